@@ -0,0 +1,367 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// formatZip is the format of zip archives
+	formatZip = "zip"
+
+	// formatTar is the format of uncompressed tar archives
+	formatTar = "tar"
+
+	// formatTarGzip is the format of gzip compressed tar archives
+	formatTarGzip = "tar.gz"
+
+	// formatTarZstd is the format of zstd compressed tar archives
+	formatTarZstd = "tar.zst"
+
+	// maxZstdWindowSize is the largest window (in bytes) a zstd compressed archive can
+	// use, which bounds the memory needed to decompress it
+	maxZstdWindowSize = 128 << 20
+)
+
+// formatExtensions maps the extensions of the supported archive formats to their format,
+// longest extensions first
+var formatExtensions = []struct {
+	extension string
+	format    string
+}{
+	{".tar.zstd", formatTarZstd},
+	{".tar.zst", formatTarZstd},
+	{".tar.gz", formatTarGzip},
+	{".tzst", formatTarZstd},
+	{".tgz", formatTarGzip},
+	{".tar", formatTar},
+	{".zip", formatZip},
+}
+
+// Limits holds the limits applied when extracting an archive, a limit of 0 means there is no limit
+type Limits struct {
+
+	// MaxSize is the largest combined size (in bytes) of the extracted files
+	MaxSize int64
+
+	// MaxEntries is the maximum number of entries (files, directories and skipped entries) an archive can hold
+	MaxEntries int
+}
+
+// Result holds the outcome of extracting an archive
+type Result struct {
+
+	// Files the paths of the extracted files, relative to the destination directory and slash separated
+	Files []string
+
+	// SkippedEntries the names of the entries that weren't extracted, i.e. symlinks
+	SkippedEntries []string
+
+	// Size is the combined size (in bytes) of the extracted files
+	Size int64
+
+	// Entries is the number of entries read from the archive, including the skipped entries
+	Entries int
+}
+
+// IsArchive returns whether the file name has the extension of a supported archive
+// format (zip, tar, tar.gz/tgz or tar.zst/tzst)
+func IsArchive(fileName string) bool {
+	format, _ := getFormat(fileName)
+	return format != ""
+}
+
+// ExtractedDirName returns the name of the directory the archive with the given file
+// name is extracted into, which is its name without the archive extension
+func ExtractedDirName(fileName string) string {
+	_, extension := getFormat(fileName)
+
+	dirName := fileName[:len(fileName)-len(extension)]
+	if dirName == "" {
+		return "archive"
+	}
+	return dirName
+}
+
+// Extract extracts the archive with the given file name and stored at the given path into the
+// destination directory, skipping links and special files and enforcing the limits
+func Extract(fileName, archivePath, destinationDir string, limits Limits) (*Result, error) {
+
+	format, _ := getFormat(fileName)
+	if format == "" {
+		return nil, fmt.Errorf("%w: %s", errors.ErrUnsupportedArchiveFormat, fileName)
+	}
+
+	err := os.Mkdir(destinationDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &extractor{
+		destinationDir: destinationDir,
+		limits:         limits,
+		result:         &Result{Files: []string{}, SkippedEntries: []string{}},
+	}
+
+	if format == formatZip {
+		err = e.extractZip(archivePath)
+	} else {
+		err = e.extractTar(archivePath, format)
+	}
+
+	if err != nil {
+		os.RemoveAll(destinationDir)
+		return nil, err
+	}
+
+	return e.result, nil
+}
+
+// getFormat returns the format of the archive with the given file name and the matched
+// extension, or empty strings if it isn't a supported archive
+func getFormat(fileName string) (string, string) {
+	lowerFileName := strings.ToLower(fileName)
+
+	for _, formatExtension := range formatExtensions {
+		if strings.HasSuffix(lowerFileName, formatExtension.extension) {
+			return formatExtension.format, fileName[len(fileName)-len(formatExtension.extension):]
+		}
+	}
+
+	return "", ""
+}
+
+// extractor extracts the entries of an archive into the destination directory within the limits
+type extractor struct {
+
+	// destinationDir is where the entries are extracted to
+	destinationDir string
+
+	// limits the limits applied to the extracted entries
+	limits Limits
+
+	// result the outcome of the extraction so far
+	result *Result
+}
+
+// extractZip extracts the entries of the zip archive at the given path
+func (e *extractor) extractZip(archivePath string) error {
+
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zipReader.Close()
+
+	for _, zipFile := range zipReader.File {
+		mode := zipFile.Mode()
+
+		switch {
+		case mode.IsDir():
+			err = e.createDir(zipFile.Name)
+
+		case mode.IsRegular():
+			err = e.checkSize(zipFile.Name, int64(zipFile.UncompressedSize64))
+			if err != nil {
+				return err
+			}
+
+			var zipFileReader io.ReadCloser
+			zipFileReader, err = zipFile.Open()
+			if err != nil {
+				return err
+			}
+			err = e.createFile(zipFile.Name, zipFileReader, mode)
+			zipFileReader.Close()
+
+		default:
+			err = e.skipEntry(zipFile.Name)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractTar extracts the entries of the (compressed) tar archive at the given path
+func (e *extractor) extractTar(archivePath, format string) error {
+
+	archiveFile, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archiveFile.Close()
+
+	var reader io.Reader = archiveFile
+
+	switch format {
+	case formatTarGzip:
+		gzipReader, err := gzip.NewReader(archiveFile)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+
+	case formatTarZstd:
+		zstdReader, err := zstd.NewReader(archiveFile, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(maxZstdWindowSize))
+		if err != nil {
+			return err
+		}
+		defer zstdReader.Close()
+		reader = zstdReader
+	}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = e.createDir(header.Name)
+
+		case tar.TypeReg:
+			err = e.checkSize(header.Name, header.Size)
+			if err == nil {
+				err = e.createFile(header.Name, tarReader, header.FileInfo().Mode())
+			}
+
+		default:
+			err = e.skipEntry(header.Name)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// createDir creates the directory entry within the destination directory
+func (e *extractor) createDir(name string) error {
+
+	// the destination directory itself, i.e. "./" in archives of a directory's contents
+	if path.Clean(strings.ReplaceAll(name, `\`, "/")) == "." {
+		return nil
+	}
+
+	entryPath, _, err := e.getEntryPath(name)
+	if err != nil {
+		return err
+	}
+
+	err = e.countEntry()
+	if err != nil {
+		return err
+	}
+
+	return os.MkdirAll(entryPath, 0755)
+}
+
+// createFile writes the content of the file entry within the destination directory, only
+// keeping whether it is executable from the mode recorded in the archive
+func (e *extractor) createFile(name string, reader io.Reader, mode os.FileMode) error {
+	var permissions os.FileMode = 0644
+
+	entryPath, relativePath, err := e.getEntryPath(name)
+	if err != nil {
+		return err
+	}
+
+	err = e.countEntry()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(entryPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	if mode&0100 != 0 {
+		permissions = 0755
+	}
+
+	// entries are never overwritten, so duplicate entries can't replace files already extracted
+	file, err := os.OpenFile(entryPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, permissions)
+	if err != nil {
+		return err
+	}
+
+	if e.limits.MaxSize > 0 {
+		// read one byte past the limit to detect larger content
+		reader = io.LimitReader(reader, e.limits.MaxSize-e.result.Size+1)
+	}
+
+	written, err := io.Copy(file, reader)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	e.result.Size += written
+	if e.limits.MaxSize > 0 && e.result.Size > e.limits.MaxSize {
+		return fmt.Errorf("%w: more than %d bytes extracted", errors.ErrArchiveTooLarge, e.limits.MaxSize)
+	}
+
+	e.result.Files = append(e.result.Files, relativePath)
+	return nil
+}
+
+// getEntryPath returns the path the entry with the given name is extracted to, along with
+// its slash separated path relative to the destination directory
+func (e *extractor) getEntryPath(name string) (string, string, error) {
+
+	// entries of zip archives created on windows may use backslashes as separators
+	relativePath := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+
+	if relativePath == "." || !filepath.IsLocal(filepath.FromSlash(relativePath)) {
+		return "", "", fmt.Errorf("%w: %s", errors.ErrUnsafeArchiveEntry, name)
+	}
+
+	return filepath.Join(e.destinationDir, filepath.FromSlash(relativePath)), relativePath, nil
+}
+
+// countEntry counts an extracted entry against the entry limit
+func (e *extractor) countEntry() error {
+	e.result.Entries++
+	if e.limits.MaxEntries > 0 && e.result.Entries > e.limits.MaxEntries {
+		return fmt.Errorf("%w: more than %d entries", errors.ErrArchiveHasTooManyEntries, e.limits.MaxEntries)
+	}
+	return nil
+}
+
+// skipEntry records an entry that isn't extracted, counting it against the entry limit
+func (e *extractor) skipEntry(name string) error {
+	e.result.SkippedEntries = append(e.result.SkippedEntries, name)
+	return e.countEntry()
+}
+
+// checkSize rejects the entry early if the size recorded in the archive already exceeds the size limit
+func (e *extractor) checkSize(name string, size int64) error {
+	if e.limits.MaxSize > 0 && (size < 0 || e.result.Size+size > e.limits.MaxSize) {
+		return fmt.Errorf("%w: %s would exceed %d bytes", errors.ErrArchiveTooLarge, name, e.limits.MaxSize)
+	}
+	return nil
+}
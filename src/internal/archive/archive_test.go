@@ -0,0 +1,375 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/archive"
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+// testEntry is an entry written to the archives used in the tests
+type testEntry struct {
+	name       string
+	content    string
+	dir        bool
+	symlink    string
+	executable bool
+}
+
+// writeZipArchive writes a zip archive holding the entries
+func writeZipArchive(t *testing.T, archivePath string, entries []testEntry) {
+	var buffer bytes.Buffer
+
+	zipWriter := zip.NewWriter(&buffer)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		content := entry.content
+
+		switch {
+		case entry.dir:
+			header.SetMode(os.ModeDir | 0755)
+		case entry.symlink != "":
+			header.SetMode(os.ModeSymlink | 0777)
+			content = entry.symlink
+		case entry.executable:
+			header.SetMode(0755)
+		default:
+			header.SetMode(0644)
+		}
+
+		entryWriter, err := zipWriter.CreateHeader(header)
+		assert.NoError(t, err)
+		_, err = entryWriter.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zipWriter.Close())
+	assert.NoError(t, os.WriteFile(archivePath, buffer.Bytes(), 0644))
+}
+
+// writeTarArchive writes a tar archive holding the entries, compressed with the given
+// compression ("gzip", "zstd" or "" for none)
+func writeTarArchive(t *testing.T, archivePath, compression string, entries []testEntry) {
+	var buffer bytes.Buffer
+
+	var writer io.WriteCloser
+	switch compression {
+	case "gzip":
+		writer = gzip.NewWriter(&buffer)
+	case "zstd":
+		zstdWriter, err := zstd.NewWriter(&buffer)
+		assert.NoError(t, err)
+		writer = zstdWriter
+	default:
+		writer = nopWriteCloser{&buffer}
+	}
+
+	tarWriter := tar.NewWriter(writer)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(entry.content))}
+
+		switch {
+		case entry.dir:
+			header = &tar.Header{Name: entry.name, Mode: 0755, Typeflag: tar.TypeDir}
+		case entry.symlink != "":
+			header = &tar.Header{Name: entry.name, Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: entry.symlink}
+		case entry.executable:
+			header.Mode = 0755
+		}
+
+		assert.NoError(t, tarWriter.WriteHeader(header))
+		if header.Typeflag == tar.TypeReg {
+			_, err := tarWriter.Write([]byte(entry.content))
+			assert.NoError(t, err)
+		}
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, os.WriteFile(archivePath, buffer.Bytes(), 0644))
+}
+
+// nopWriteCloser adds a no-op Close to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// writeArchive writes the entries to an archive in the format given by the file name
+func writeArchive(t *testing.T, archivePath, fileName string, entries []testEntry) {
+	switch {
+	case strings.HasSuffix(fileName, ".zip"):
+		writeZipArchive(t, archivePath, entries)
+	case strings.HasSuffix(fileName, ".tar.gz"), strings.HasSuffix(fileName, ".tgz"):
+		writeTarArchive(t, archivePath, "gzip", entries)
+	case strings.HasSuffix(fileName, ".tar.zst"), strings.HasSuffix(fileName, ".tzst"):
+		writeTarArchive(t, archivePath, "zstd", entries)
+	default:
+		writeTarArchive(t, archivePath, "", entries)
+	}
+}
+
+func TestExtract(t *testing.T) {
+
+	bundleEntries := []testEntry{
+		{name: "bundle/", dir: true},
+		{name: "bundle/readme.txt", content: "hello"},
+		{name: "bundle/bin/run.sh", content: "echo hi", executable: true},
+	}
+
+	tests := []struct {
+		name            string
+		fileName        string
+		entries         []testEntry
+		limits          archive.Limits
+		expectedFiles   []string
+		expectedSkipped []string
+		expectedSize    int64
+		expectedEntries int
+		expectedError   error
+	}{
+		{
+			name:            "success - zip archive",
+			fileName:        "bundle.zip",
+			entries:         bundleEntries,
+			expectedFiles:   []string{"bundle/readme.txt", "bundle/bin/run.sh"},
+			expectedSkipped: []string{},
+			expectedSize:    12,
+			expectedEntries: 3,
+		},
+		{
+			name:            "success - tar archive",
+			fileName:        "bundle.tar",
+			entries:         bundleEntries,
+			expectedFiles:   []string{"bundle/readme.txt", "bundle/bin/run.sh"},
+			expectedSkipped: []string{},
+			expectedSize:    12,
+			expectedEntries: 3,
+		},
+		{
+			name:            "success - gzip compressed tar archive",
+			fileName:        "bundle.tgz",
+			entries:         bundleEntries,
+			expectedFiles:   []string{"bundle/readme.txt", "bundle/bin/run.sh"},
+			expectedSkipped: []string{},
+			expectedSize:    12,
+			expectedEntries: 3,
+		},
+		{
+			name:            "success - zstd compressed tar archive",
+			fileName:        "bundle.tar.zst",
+			entries:         bundleEntries,
+			expectedFiles:   []string{"bundle/readme.txt", "bundle/bin/run.sh"},
+			expectedSkipped: []string{},
+			expectedSize:    12,
+			expectedEntries: 3,
+		},
+		{
+			name:     "success - zip symlinks are skipped",
+			fileName: "links.zip",
+			entries: []testEntry{
+				{name: "passwd", symlink: "/etc/passwd"},
+				{name: "notes.txt", content: "notes"},
+			},
+			expectedFiles:   []string{"notes.txt"},
+			expectedSkipped: []string{"passwd"},
+			expectedSize:    5,
+			expectedEntries: 2,
+		},
+		{
+			name:     "success - tar symlinks are skipped",
+			fileName: "links.tar.gz",
+			entries: []testEntry{
+				{name: "escape", symlink: "../../"},
+				{name: "notes.txt", content: "notes"},
+			},
+			expectedFiles:   []string{"notes.txt"},
+			expectedSkipped: []string{"escape"},
+			expectedSize:    5,
+			expectedEntries: 2,
+		},
+		{
+			name:     "success - destination directory entry isn't counted",
+			fileName: "contents.tar",
+			entries: []testEntry{
+				{name: "./", dir: true},
+				{name: "./notes.txt", content: "notes"},
+			},
+			limits:          archive.Limits{MaxEntries: 1},
+			expectedFiles:   []string{"notes.txt"},
+			expectedSkipped: []string{},
+			expectedSize:    5,
+			expectedEntries: 1,
+		},
+		{
+			name:          "failed - zip entry outside of the destination directory",
+			fileName:      "slip.zip",
+			entries:       []testEntry{{name: "../evil.sh", content: "rm -rf /"}},
+			expectedError: errors.ErrUnsafeArchiveEntry,
+		},
+		{
+			name:          "failed - zip entry outside of the destination directory with backslashes",
+			fileName:      "slip.zip",
+			entries:       []testEntry{{name: `docs\..\..\evil.sh`, content: "rm -rf /"}},
+			expectedError: errors.ErrUnsafeArchiveEntry,
+		},
+		{
+			name:          "failed - tar entry with an absolute path",
+			fileName:      "slip.tar",
+			entries:       []testEntry{{name: "/etc/cron.d/evil", content: "* * * * * root rm -rf /"}},
+			expectedError: errors.ErrUnsafeArchiveEntry,
+		},
+		{
+			name:          "failed - tar directory outside of the destination directory",
+			fileName:      "slip.tar.zst",
+			entries:       []testEntry{{name: "../outside/", dir: true}},
+			expectedError: errors.ErrUnsafeArchiveEntry,
+		},
+		{
+			name:     "failed - combined size of the files exceeds the limit",
+			fileName: "large.zip",
+			entries: []testEntry{
+				{name: "one.txt", content: strings.Repeat("a", 6)},
+				{name: "two.txt", content: strings.Repeat("b", 6)},
+			},
+			limits:        archive.Limits{MaxSize: 10},
+			expectedError: errors.ErrArchiveTooLarge,
+		},
+		{
+			name:          "failed - compressed file exceeds the limit",
+			fileName:      "bomb.tar.gz",
+			entries:       []testEntry{{name: "zeros", content: strings.Repeat("0", 1<<20)}},
+			limits:        archive.Limits{MaxSize: 1 << 10},
+			expectedError: errors.ErrArchiveTooLarge,
+		},
+		{
+			name:          "failed - too many entries",
+			fileName:      "many.tar",
+			entries:       bundleEntries,
+			limits:        archive.Limits{MaxEntries: 2},
+			expectedError: errors.ErrArchiveHasTooManyEntries,
+		},
+		{
+			name:     "failed - skipped entries count against the entry limit",
+			fileName: "links.zip",
+			entries: []testEntry{
+				{name: "one", symlink: "target"},
+				{name: "two", symlink: "target"},
+				{name: "three", symlink: "target"},
+			},
+			limits:        archive.Limits{MaxEntries: 2},
+			expectedError: errors.ErrArchiveHasTooManyEntries,
+		},
+		{
+			name:          "failed - unsupported format",
+			fileName:      "bundle.rar",
+			expectedError: errors.ErrUnsupportedArchiveFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			tempDir := t.TempDir()
+			archivePath := filepath.Join(tempDir, tt.fileName)
+			destinationDir := filepath.Join(tempDir, "extracted")
+
+			writeArchive(t, archivePath, tt.fileName, tt.entries)
+
+			result, err := archive.Extract(tt.fileName, archivePath, destinationDir, tt.limits)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.NoDirExists(t, destinationDir)
+				assert.NoFileExists(t, filepath.Join(tempDir, "evil.sh"))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedFiles, result.Files)
+			assert.Equal(t, tt.expectedSkipped, result.SkippedEntries)
+			assert.Equal(t, tt.expectedSize, result.Size)
+			assert.Equal(t, tt.expectedEntries, result.Entries)
+
+			for _, file := range result.Files {
+				assert.FileExists(t, filepath.Join(destinationDir, filepath.FromSlash(file)))
+			}
+			for _, skipped := range result.SkippedEntries {
+				_, err := os.Lstat(filepath.Join(destinationDir, skipped))
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			}
+		})
+	}
+}
+
+func TestExtract_KeepsExecutableMode(t *testing.T) {
+
+	tempDir := t.TempDir()
+	archivePath := filepath.Join(tempDir, "bundle.tar")
+	destinationDir := filepath.Join(tempDir, "extracted")
+
+	writeArchive(t, archivePath, "bundle.tar", []testEntry{
+		{name: "run.sh", content: "echo hi", executable: true},
+		{name: "readme.txt", content: "hello"},
+	})
+
+	_, err := archive.Extract("bundle.tar", archivePath, destinationDir, archive.Limits{})
+	assert.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(destinationDir, "run.sh"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm()&0755)
+
+	info, err = os.Stat(filepath.Join(destinationDir, "readme.txt"))
+	assert.NoError(t, err)
+	assert.Zero(t, info.Mode().Perm()&0111)
+}
+
+func TestExtract_DestinationMustNotExist(t *testing.T) {
+
+	tempDir := t.TempDir()
+	archivePath := filepath.Join(tempDir, "bundle.zip")
+
+	writeArchive(t, archivePath, "bundle.zip", []testEntry{{name: "readme.txt", content: "hello"}})
+
+	_, err := archive.Extract("bundle.zip", archivePath, tempDir, archive.Limits{})
+
+	assert.ErrorIs(t, err, os.ErrExist)
+}
+
+func TestIsArchive(t *testing.T) {
+
+	tests := []struct {
+		fileName        string
+		expected        bool
+		expectedDirName string
+	}{
+		{fileName: "bundle.zip", expected: true, expectedDirName: "bundle"},
+		{fileName: "bundle.tar", expected: true, expectedDirName: "bundle"},
+		{fileName: "bundle.tar.gz", expected: true, expectedDirName: "bundle"},
+		{fileName: "bundle.tgz", expected: true, expectedDirName: "bundle"},
+		{fileName: "bundle.tar.zst", expected: true, expectedDirName: "bundle"},
+		{fileName: "bundle.tar.zstd", expected: true, expectedDirName: "bundle"},
+		{fileName: "bundle.tzst", expected: true, expectedDirName: "bundle"},
+		{fileName: "Bundle.v2.ZIP", expected: true, expectedDirName: "Bundle.v2"},
+		{fileName: ".zip", expected: true, expectedDirName: "archive"},
+		{fileName: "notes.gz", expected: false, expectedDirName: "notes.gz"},
+		{fileName: "bundle.rar", expected: false, expectedDirName: "bundle.rar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, archive.IsArchive(tt.fileName))
+			assert.Equal(t, tt.expectedDirName, archive.ExtractedDirName(tt.fileName))
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// LogOutput holds the name of the output holding the path of the audit log
+	LogOutput = "audit-log"
+
+	// RedactedValue holds the value secret fields are recorded with
+	RedactedValue = "[REDACTED]"
+
+	// webhookTimeout is how long an audit event can take to be posted to the webhook
+	webhookTimeout = 10 * time.Second
+)
+
+const (
+	// EventPortalOpened is recorded when the portal's page is opened
+	EventPortalOpened = "portal.opened"
+
+	// EventAccessDenied is recorded when the portal is used with an invalid link or session
+	EventAccessDenied = "access.denied"
+
+	// EventClientLockedOut is recorded when a client is locked out after too many failed access attempts
+	EventClientLockedOut = "access.locked_out"
+
+	// EventClientRateLimited is recorded when a client goes over the portal's rate limit
+	EventClientRateLimited = "access.rate_limited"
+
+	// EventRequestForgeryDetected is recorded when a request from another origin, or without the CSRF token, is rejected
+	EventRequestForgeryDetected = "access.forgery_detected"
+
+	// EventFilesUploaded is recorded when files are uploaded to a file/multifile field
+	EventFilesUploaded = "upload.completed"
+
+	// EventUploadReset is recorded when the files uploaded to a file/multifile field are removed
+	EventUploadReset = "upload.reset"
+
+	// EventInputsSubmitted is recorded when the inputs are submitted
+	EventInputsSubmitted = "portal.submitted"
+
+	// EventPortalCancelled is recorded when the portal is cancelled
+	EventPortalCancelled = "portal.cancelled"
+)
+
+// actionPkg manages business logic around action request
+type actionPkg interface {
+	Warningf(msg string, args ...any)
+}
+
+// Event is a single recorded activity on the portal
+type Event struct {
+
+	// Time is when the activity happened
+	Time time.Time `json:"time"`
+
+	// Type is the kind of activity, i.e. EventInputsSubmitted
+	Type string `json:"event"`
+
+	// Client is the address of the client the activity came from
+	Client string `json:"client,omitempty"`
+
+	// UserAgent is the user agent of the client the activity came from
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// Actor is the recipient whose link was used, when the portal is shared with recipients
+	Actor string `json:"actor,omitempty"`
+
+	// Details describes the activity, i.e. the uploaded files or submitted values
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// NewLogRequest holds everything needed to create an audit log
+type NewLogRequest struct {
+
+	// FilePath is the path of the file events are appended to as JSON lines (optional)
+	FilePath string
+
+	// WebhookUrl is the URL each event is posted to as JSON (optional)
+	WebhookUrl string
+
+	// ActionPkg represents the githubactions package
+	ActionPkg actionPkg
+}
+
+// Log records the portal's activity as JSON lines to a file, forwarding each event to a
+// webhook when one is provided. A nil Log records nothing
+type Log struct {
+
+	// file is the file events are appended to, nil when events aren't written to a file
+	file *os.File
+
+	// fileMutex guards file while an event is written
+	fileMutex sync.Mutex
+
+	// webhookUrl is the URL each event is posted to, empty when events aren't forwarded
+	webhookUrl string
+
+	// client is the client used to post events to the webhook
+	client *http.Client
+
+	// pendingWebhooks tracks the events still being posted to the webhook
+	pendingWebhooks sync.WaitGroup
+
+	// actionPkg represents the githubactions package
+	actionPkg actionPkg
+}
+
+// NewLog returns an audit log, creating its file (and directory) if needed
+func NewLog(r *NewLogRequest) (*Log, error) {
+	auditLog := &Log{
+		webhookUrl: r.WebhookUrl,
+		client:     &http.Client{Timeout: webhookTimeout},
+		actionPkg:  r.ActionPkg,
+	}
+
+	if r.FilePath != "" {
+		err := os.MkdirAll(filepath.Dir(r.FilePath), os.ModePerm)
+		if err != nil {
+			return nil, err
+		}
+
+		auditLog.file, err = os.OpenFile(r.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return auditLog, nil
+}
+
+// Record appends the event to the audit log and posts it to the webhook in the background
+func (l *Log) Record(event Event) {
+	if l == nil {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	eventJson, err := json.Marshal(event)
+	if err != nil {
+		l.actionPkg.Warningf("Unable to encode the %s audit event: %v", event.Type, err)
+		return
+	}
+
+	if l.file != nil {
+		l.fileMutex.Lock()
+		_, err = l.file.Write(append(eventJson, '\n'))
+		l.fileMutex.Unlock()
+		if err != nil {
+			l.actionPkg.Warningf("Unable to write the %s audit event: %v", event.Type, err)
+		}
+	}
+
+	if l.webhookUrl != "" {
+		l.pendingWebhooks.Add(1)
+		go func() {
+			defer l.pendingWebhooks.Done()
+
+			err := l.postToWebhook(eventJson)
+			if err != nil {
+				l.actionPkg.Warningf("Unable to forward the %s audit event to the webhook: %v", event.Type, err)
+			}
+		}()
+	}
+}
+
+// postToWebhook posts the encoded event to the webhook
+func (l *Log) postToWebhook(eventJson []byte) error {
+	response, err := l.client.Post(l.webhookUrl, "application/json", bytes.NewReader(eventJson))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("the webhook responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+// Close waits for the events being posted to the webhook, then closes the audit log's file
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.pendingWebhooks.Wait()
+
+	if l.file == nil {
+		return nil
+	}
+
+	return l.file.Close()
+}
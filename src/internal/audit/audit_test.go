@@ -0,0 +1,133 @@
+package audit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLog_RecordToFile(t *testing.T) {
+
+	filePath := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	eventTime := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+	// events are appended to an existing log
+	assert.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	assert.NoError(t, os.WriteFile(filePath, []byte(`{"event":"portal.opened"}`+"\n"), 0600))
+
+	auditLog, err := audit.NewLog(&audit.NewLogRequest{
+		FilePath:  filePath,
+		ActionPkg: githubactions.New(githubactions.WithWriter(io.Discard)),
+	})
+	assert.NoError(t, err)
+
+	auditLog.Record(audit.Event{Type: audit.EventAccessDenied, Client: "203.0.113.7", Details: map[string]any{"reason": "invalid access token"}})
+	auditLog.Record(audit.Event{Time: eventTime, Type: audit.EventInputsSubmitted, Actor: "leon", Details: map[string]any{"values": map[string]string{"api-key": audit.RedactedValue}}})
+	assert.NoError(t, auditLog.Close())
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if !assert.Len(t, lines, 3) {
+		return
+	}
+
+	assert.Equal(t, `{"event":"portal.opened"}`, lines[0])
+
+	var deniedEvent audit.Event
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &deniedEvent))
+	assert.Equal(t, audit.EventAccessDenied, deniedEvent.Type)
+	assert.Equal(t, "203.0.113.7", deniedEvent.Client)
+	assert.WithinDuration(t, time.Now(), deniedEvent.Time, time.Minute)
+
+	assert.Equal(t, `{"time":"2026-10-18T09:30:00Z","event":"portal.submitted","actor":"leon","details":{"values":{"api-key":"[REDACTED]"}}}`, lines[2])
+
+	info, err := os.Stat(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestLog_RecordToWebhook(t *testing.T) {
+
+	var receivedMutex sync.Mutex
+	var received []audit.Event
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event audit.Event
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		// events are still delivered when the webhook is slow to respond
+		time.Sleep(20 * time.Millisecond)
+
+		receivedMutex.Lock()
+		received = append(received, event)
+		receivedMutex.Unlock()
+
+		if event.Type == audit.EventPortalCancelled {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+
+	auditLog, err := audit.NewLog(&audit.NewLogRequest{
+		WebhookUrl: server.URL,
+		ActionPkg:  githubactions.New(githubactions.WithWriter(&output)),
+	})
+	assert.NoError(t, err)
+
+	auditLog.Record(audit.Event{Type: audit.EventPortalOpened})
+	auditLog.Record(audit.Event{Type: audit.EventPortalCancelled})
+
+	// closing waits for the events to be posted
+	assert.NoError(t, auditLog.Close())
+
+	receivedMutex.Lock()
+	defer receivedMutex.Unlock()
+
+	var receivedTypes []string
+	for _, event := range received {
+		receivedTypes = append(receivedTypes, event.Type)
+	}
+	assert.ElementsMatch(t, []string{audit.EventPortalOpened, audit.EventPortalCancelled}, receivedTypes)
+
+	assert.Equal(t, "::warning::Unable to forward the portal.cancelled audit event to the webhook: the webhook responded with status 500\n", output.String())
+}
+
+func TestLog_Nil(t *testing.T) {
+
+	var auditLog *audit.Log
+
+	assert.NotPanics(t, func() {
+		auditLog.Record(audit.Event{Type: audit.EventPortalOpened})
+	})
+	assert.NoError(t, auditLog.Close())
+}
+
+func TestNewLog_InvalidFilePath(t *testing.T) {
+
+	// the log's directory can't be created inside a file
+	filePath := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(filePath, []byte{}, 0600))
+
+	_, err := audit.NewLog(&audit.NewLogRequest{FilePath: filepath.Join(filePath, "audit.jsonl")})
+
+	assert.Error(t, err)
+}
@@ -1,11 +1,19 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/exposer"
 	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
 	githubactions "github.com/sethvargo/go-githubactions"
 )
 
@@ -55,9 +63,30 @@ type Config struct {
 	//  the message(s)
 	NotifierDiscordUsernameOverride string
 
+	// NotifierGithubEnabled will be used to determine whether the GitHub notifier
+	// is enabled or not
+	NotifierGithubEnabled bool
+
+	// NotifierGithubTarget is where the GitHub notifier posts the portal's link, either as a
+	// comment on the run's pull request (or issue) or as a pending commit status
+	NotifierGithubTarget string
+
+	// CommentRepliesEnabled is whether replies to the GitHub notifier's comment can
+	// answer (or reject) the portal
+	CommentRepliesEnabled bool
+
+	// CommentReplyPrefix is the prefix of the commands in the replies, i.e. "/" for "/answer"
+	CommentReplyPrefix string
+
+	// CommentReplyPollInterval is how often the replies are checked for
+	CommentReplyPollInterval time.Duration
+
 	// GithubToken is the token that will be used to allow action to leverage the GitHub API
 	GithubToken string
 
+	// GithubApiUrl is the base URL of the GitHub API, the run's API URL is used when it isn't provided
+	GithubApiUrl string
+
 	// NgrokAuthtoken is the authtoken that will be used to make Ngrok tunnels to host the
 	// interactive inputs portals
 	NgrokAuthtoken string
@@ -68,9 +97,126 @@ type Config struct {
 	// NetworkIP is the specific network IP address to use (if not provided, will auto-detect)
 	NetworkIP string
 
+	// NetworkInterface is the name of the only interface the network IP is detected on (optional)
+	NetworkInterface string
+
+	// NetworkCidr is the range the detected network IP must fall within (optional)
+	NetworkCidr string
+
+	// NetworkIPScope is whether private or public addresses are preferred when detecting the network IP
+	NetworkIPScope string
+
+	// NetworkIPFamily is whether IPv4 or IPv6 addresses are preferred when detecting the network IP
+	NetworkIPFamily string
+
+	// NetworkHostname is the DNS name used in the portal's URL instead of the network IP (optional)
+	NetworkHostname string
+
+	// TlsEnabled is true when the portal is served over TLS when it is exposed on the
+	// network IP or localhost
+	TlsEnabled bool
+
+	// TlsCert is the PEM encoded certificate, or the path to it, the portal is served with. A
+	// self-signed certificate is generated when it isn't provided
+	TlsCert string
+
+	// TlsKey is the PEM encoded private key, or the path to it, of the certificate
+	TlsKey string
+
+	// TlsRedirectHttp is true when plain HTTP requests are redirected to HTTPS
+	TlsRedirectHttp bool
+
+	// RequireAccessToken is true when the portal can only be used from the link holding its access token
+	RequireAccessToken bool
+
+	// PortalAccessToken is the token the portal can only be used with, a random token is
+	// generated when it isn't provided
+	PortalAccessToken string
+
+	// Recipients are the GitHub usernames or emails that are each sent their own link to the portal
+	Recipients []string
+
+	// RateLimitRequests is how many requests each client can make to the portal's submit, upload
+	// and reset routes within the RateLimitWindow, 0 when requests aren't rate limited
+	RateLimitRequests int
+
+	// RateLimitWindow is the window the RateLimitRequests are counted over
+	RateLimitWindow time.Duration
+
+	// AuthLockoutAttempts is how many failed access token attempts a client can make within the
+	// AuthLockoutWindow before it is locked out for the window, 0 when clients aren't locked out
+	AuthLockoutAttempts int
+
+	// AuthLockoutWindow is the window the AuthLockoutAttempts are counted over, and how long clients are locked out for
+	AuthLockoutWindow time.Duration
+
+	// TrustForwardedFor is true when the client's address is taken from the X-Forwarded-For header
+	// whatever the portal is exposed with, i.e. when an SSH tunnel's host has a reverse proxy that sets it
+	TrustForwardedFor bool
+
+	// AuditLogPath is the path of the file the portal's activity is recorded to as JSON lines (optional)
+	AuditLogPath string
+
+	// AuditLogWebhookUrl is the URL each of the portal's audit events is posted to as JSON (optional)
+	AuditLogWebhookUrl string
+
+	// MetricsEnabled is true when the portal's metrics are exposed on /metrics
+	MetricsEnabled bool
+
 	// StartPort is the starting port number for the server (will auto-increment if occupied)
 	StartPort int
 
+	// Expose is the ordered list of methods the portal is exposed with, each is tried
+	// in turn until one succeeds
+	Expose []string
+
+	// TunnelSshDestination is the host the SSH reverse tunnel is opened to, i.e. "tunnel@example.com"
+	TunnelSshDestination string
+
+	// TunnelSshRemotePort is the port forwarded on the SSH host (0 uses the default port)
+	TunnelSshRemotePort int
+
+	// TunnelSshPublicUrl is the URL the port forwarded on the SSH host can be reached at (optional)
+	TunnelSshPublicUrl string
+
+	// TunnelCommand is the command run to tunnel to the portal, i.e. cloudflared or bore
+	TunnelCommand string
+
+	// TunnelUrlPattern is the regular expression the public URL is parsed from the output
+	// of the tunnel command with (optional)
+	TunnelUrlPattern string
+
+	// PrefillAnswersFile is the path to a JSON file holding the answers of a previous run,
+	// which are used to prefill the portal
+	PrefillAnswersFile string
+
+	// Mode is how the inputs are collected, either through the portal (default),
+	// by prompting in the terminal or from the provided answers
+	Mode string
+
+	// AnswersFile is the path to a JSON file holding the answers used instead of
+	// collecting the inputs
+	AnswersFile string
+
+	// Answers is the JSON holding the answers used instead of collecting the inputs,
+	// provided with the IAIP_ANSWERS environment variable. It takes precedence over AnswersFile
+	Answers string
+
+	// UploadScannerCommand is the command each uploaded file is scanned with, the path of
+	// the file is appended as its last argument
+	UploadScannerCommand string
+
+	// UploadScannerUrl is the URL of the HTTP endpoint each uploaded file is scanned with
+	UploadScannerUrl string
+
+	// UploadScannerAction is what happens to the uploaded files the scanner flags, either
+	// the upload is rejected (default) or the flagged files are quarantined
+	UploadScannerAction string
+
+	// CancelBehaviour is what happens when the portal is cancelled, either the step fails
+	// (default), the workflow run is cancelled or the step succeeds with the cancelled output set
+	CancelBehaviour string
+
 	Action *githubactions.Action
 }
 
@@ -84,6 +230,76 @@ const (
 	// DefaultStartPort is the default starting port number for the server
 	// Will auto-increment if occupied
 	DefaultStartPort int = 8080
+
+	// DefaultCommentReplyPrefix is the prefix of the commands in replies to the GitHub
+	// notifier's comment, i.e. "/answer"
+	DefaultCommentReplyPrefix string = "/"
+
+	// DefaultCommentReplyPollInterval is how often replies to the GitHub notifier's comment are checked for
+	DefaultCommentReplyPollInterval time.Duration = 15 * time.Second
+
+	// MinCommentReplyPollInterval is the shortest interval replies can be checked at
+	MinCommentReplyPollInterval time.Duration = 5 * time.Second
+
+	// DefaultRateLimit is how many requests each client can make to the portal's submit,
+	// upload and reset routes, and the window they are counted over
+	DefaultRateLimit string = "60/1m"
+
+	// DefaultAuthLockout is how many failed access token attempts a client can make before
+	// it is locked out, and the window they are counted over
+	DefaultAuthLockout string = "5/15m"
+
+	// ModePortal collects the inputs through the web portal
+	ModePortal string = "portal"
+
+	// ModePrompt collects the inputs by prompting in the terminal, without
+	// starting the web portal
+	ModePrompt string = "prompt"
+
+	// ModeAnswers uses the provided answers as the inputs, without starting the
+	// web portal. It is used whenever answers are provided
+	ModeAnswers string = "answers"
+
+	// ExposeMethodNgrok exposes the portal through an ngrok tunnel
+	ExposeMethodNgrok string = "ngrok"
+
+	// ExposeMethodNetworkIP exposes the portal by binding to the runner's network IP, without a tunnel
+	ExposeMethodNetworkIP string = "network-ip"
+
+	// ExposeMethodLocalhost exposes the portal on localhost, so it can only be reached from the runner
+	ExposeMethodLocalhost string = "localhost"
+
+	// ExposeMethodSsh exposes the portal through an SSH reverse tunnel to a user provided host
+	ExposeMethodSsh string = "ssh"
+
+	// ExposeMethodCommand exposes the portal by running a command that tunnels to it (i.e.
+	// cloudflared or bore), parsing the public URL from its output
+	ExposeMethodCommand string = "command"
+
+	// UploadScannerActionReject rejects the whole upload when the scanner flags a file,
+	// keeping any previously uploaded files
+	UploadScannerActionReject string = "reject"
+
+	// UploadScannerActionQuarantine moves the files the scanner flags out of the file cache
+	// into a quarantine directory, keeping the rest of the upload
+	UploadScannerActionQuarantine string = "quarantine"
+
+	// CancelBehaviourFailStep fails the step when the portal is cancelled
+	CancelBehaviourFailStep string = "fail-step"
+
+	// CancelBehaviourCancelRun cancels the workflow run through the GitHub API when the portal is cancelled
+	CancelBehaviourCancelRun string = "cancel-run"
+
+	// CancelBehaviourSucceedWithFlag lets the step succeed with the cancelled output set to true
+	// when the portal is cancelled, so later steps can decide what to do
+	CancelBehaviourSucceedWithFlag string = "succeed-with-flag"
+
+	// NotifierGithubTargetComment posts the portal's link as a comment on the run's pull request or issue,
+	// falling back to a commit status when the run has neither
+	NotifierGithubTargetComment string = "comment"
+
+	// NotifierGithubTargetStatus posts the portal's link as a pending commit status
+	NotifierGithubTargetStatus string = "status"
 )
 
 // NewFromInputs creates a new Config instance from the provided GitHub Actions inputs.
@@ -95,17 +311,203 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 
 	var err error
 
+	// handle input for fetching the mode used to collect the inputs
+	modeInput := strings.ToLower(strings.TrimSpace(action.GetInput("mode")))
+	if modeInput == "" {
+		modeInput = ModePortal
+	}
+	if modeInput != ModePortal && modeInput != ModePrompt {
+		action.Errorf("The mode '%s' is not supported, valid modes are: %s, %s", modeInput, ModePortal, ModePrompt)
+		return nil, errors.ErrInvalidModeProvided
+	}
+	if modeInput != ModePortal {
+		action.Debugf("Mode provided: %s", modeInput)
+	}
+
+	// handle input for fetching the answers used instead of collecting the inputs
+	answersInput := strings.TrimSpace(action.Getenv("IAIP_ANSWERS"))
+	answersFileInput := strings.TrimSpace(action.GetInput("answers-file"))
+	if answersFileInput != "" && !filepath.IsAbs(answersFileInput) {
+		answersFileInput = filepath.Join(action.Getenv("GITHUB_WORKSPACE"), answersFileInput)
+	}
+	if answersInput != "" || answersFileInput != "" {
+		modeInput = ModeAnswers
+		action.Debugf("Answers provided, the inputs will not be collected")
+	}
+
 	// handle input for fetching ngrok authtoken
 	ngrokAuthtokenInput := action.GetInput("ngrok-authtoken")
 	useNetworkIPInput := action.GetInput("use-network-ip") == "true"
-	networkIPInput := action.GetInput("network-ip")
-	
-	// If using ngrok (not network IP), ngrok token is required
-	if !useNetworkIPInput && ngrokAuthtokenInput == "" {
-		action.Errorf("The ngrok-authtoken was not provided, this is needed when not using network IP mode")
-		return nil, errors.ErrNgrokAuthtokenNotProvided
+	networkIPInput := strings.TrimSpace(action.GetInput("network-ip"))
+	networkInterfaceInput := strings.TrimSpace(action.GetInput("network-interface"))
+
+	// handle input for fetching the range the network IP must fall within
+	networkCidrInput := strings.TrimSpace(action.GetInput("network-cidr"))
+	if networkCidrInput != "" {
+		if _, _, err := net.ParseCIDR(networkCidrInput); err != nil {
+			action.Errorf("The network-cidr '%s' is not a valid CIDR, i.e. 10.0.0.0/8", networkCidrInput)
+			return nil, errors.ErrInvalidNetworkIPSelectionProvided
+		}
+	}
+
+	// handle input for fetching which addresses are preferred when detecting
+	// the network IP, i.e. "private-v4" (the default) or "public, v6"
+	networkIPScope := exposer.NetworkIPScopePrivate
+	networkIPFamily := exposer.NetworkIPFamilyV4
+	networkIPPreferenceInput := strings.ToLower(strings.TrimSpace(action.GetInput("network-ip-preference")))
+	for _, preference := range strings.FieldsFunc(networkIPPreferenceInput, func(r rune) bool { return r == '-' || r == ',' || r == ' ' }) {
+		switch preference {
+		case exposer.NetworkIPScopePrivate, exposer.NetworkIPScopePublic:
+			networkIPScope = preference
+		case exposer.NetworkIPFamilyV4, exposer.NetworkIPFamilyV6:
+			networkIPFamily = preference
+		default:
+			action.Errorf("The network-ip-preference '%s' is not supported, it can be made up of: %s or %s, and %s or %s", networkIPPreferenceInput, exposer.NetworkIPScopePrivate, exposer.NetworkIPScopePublic, exposer.NetworkIPFamilyV4, exposer.NetworkIPFamilyV6)
+			return nil, errors.ErrInvalidNetworkIPSelectionProvided
+		}
+	}
+
+	// handle input for fetching the DNS name used in the portal's URL
+	networkHostnameInput := strings.ToLower(strings.TrimSpace(action.GetInput("network-hostname")))
+	if networkHostnameInput != "" && !hostnameRegex.MatchString(networkHostnameInput) {
+		action.Errorf("The network-hostname '%s' is not a valid hostname, i.e. runner-01.example.com", networkHostnameInput)
+		return nil, errors.ErrInvalidNetworkIPSelectionProvided
+	}
+
+	// handle input for fetching the methods the portal is exposed with, which defaults to
+	// the network IP when it is used and ngrok otherwise
+	exposeInput := parseListInput(action.GetInput("expose"))
+	if len(exposeInput) == 0 && useNetworkIPInput {
+		exposeInput = []string{ExposeMethodNetworkIP}
+	}
+	if len(exposeInput) == 0 {
+		exposeInput = []string{ExposeMethodNgrok}
+	}
+
+	tunnelSshDestinationInput := strings.TrimSpace(action.GetInput("tunnel-ssh-destination"))
+	tunnelSshPublicUrlInput := strings.TrimSpace(action.GetInput("tunnel-ssh-public-url"))
+	tunnelCommandInput := strings.TrimSpace(action.GetInput("tunnel-command"))
+	tunnelUrlPatternInput := strings.TrimSpace(action.GetInput("tunnel-url-pattern"))
+	if tunnelUrlPatternInput != "" {
+		if _, err := regexp.Compile(tunnelUrlPatternInput); err != nil {
+			action.Errorf("The tunnel-url-pattern is not a valid regular expression: %v", err)
+			return nil, errors.ErrInvalidTunnelProviderProvided
+		}
+	}
+
+	var tunnelSshRemotePort int
+	tunnelSshRemotePortInput := strings.TrimSpace(action.GetInput("tunnel-ssh-remote-port"))
+	if tunnelSshRemotePortInput != "" {
+		tunnelSshRemotePort, err = strconv.Atoi(tunnelSshRemotePortInput)
+		if err != nil || tunnelSshRemotePort < 1 || tunnelSshRemotePort > 65535 {
+			action.Errorf("The tunnel-ssh-remote-port must be a port between 1 and 65535, got: %s", tunnelSshRemotePortInput)
+			return nil, errors.ErrInvalidTunnelProviderProvided
+		}
+	}
+
+	for _, exposeMethod := range exposeInput {
+		switch exposeMethod {
+		case ExposeMethodNgrok:
+			// If using ngrok, ngrok token is required. The portal is only
+			// hosted in portal mode, so it isn't needed otherwise
+			if modeInput == ModePortal && ngrokAuthtokenInput == "" {
+				action.Errorf("The ngrok-authtoken was not provided, this is needed when exposing the portal with %s", ExposeMethodNgrok)
+				return nil, errors.ErrNgrokAuthtokenNotProvided
+			}
+
+		case ExposeMethodNetworkIP, ExposeMethodLocalhost:
+
+		case ExposeMethodSsh:
+			if modeInput == ModePortal && tunnelSshDestinationInput == "" {
+				action.Errorf("The tunnel-ssh-destination was not provided, this is needed when exposing the portal with %s", ExposeMethodSsh)
+				return nil, errors.ErrInvalidTunnelProviderProvided
+			}
+
+		case ExposeMethodCommand:
+			if modeInput == ModePortal && tunnelCommandInput == "" {
+				action.Errorf("The tunnel-command was not provided, this is needed when exposing the portal with %s", ExposeMethodCommand)
+				return nil, errors.ErrInvalidTunnelProviderProvided
+			}
+
+		default:
+			action.Errorf("The expose method '%s' is not supported, valid expose methods are: %s, %s, %s, %s, %s", exposeMethod, ExposeMethodNgrok, ExposeMethodNetworkIP, ExposeMethodLocalhost, ExposeMethodSsh, ExposeMethodCommand)
+			return nil, errors.ErrInvalidExposeMethodProvided
+		}
+	}
+
+	// handle input for fetching how the portal is served over TLS, which is enabled
+	// when a certificate is provided
+	tlsCertInput := resolvePemInput(action, action.GetInput("tls-cert"))
+	tlsKeyInput := resolvePemInput(action, action.GetInput("tls-key"))
+	if (tlsCertInput == "") != (tlsKeyInput == "") {
+		action.Errorf("Both the tls-cert and tls-key need to be provided to serve the portal with your own certificate")
+		return nil, errors.ErrInvalidTlsConfigProvided
+	}
+	tlsEnabledInput := action.GetInput("tls") == "true" || tlsCertInput != ""
+	tlsRedirectHttpInput := tlsEnabledInput && action.GetInput("tls-redirect-http") != "false"
+	if tlsEnabledInput && tlsCertInput == "" {
+		action.Debugf("TLS enabled without a certificate, a self-signed certificate will be generated")
+	}
+
+	// handle input for fetching whether the portal can only be used with its access token,
+	// which is required unless turned off
+	requireAccessTokenInput := action.GetInput("require-access-token") != "false"
+	portalAccessTokenInput := strings.TrimSpace(action.GetInput("portal-access-token"))
+	if !requireAccessTokenInput {
+		action.Warningf("The portal's access token isn't required, anyone who can reach the portal can use it")
+	}
+
+	// handle input for fetching who is sent their own link to the portal
+	var recipientsInput []string
+	for _, recipient := range parseListInput(action.GetInput("recipients")) {
+		recipient = strings.TrimPrefix(recipient, "@")
+		if !githubUsernameRegex.MatchString(recipient) && !emailRegex.MatchString(recipient) {
+			action.Errorf("The recipient '%s' must be a GitHub username or email", recipient)
+			return nil, errors.ErrInvalidRecipientsProvided
+		}
+		if !toolbox.StringInSlice(recipient, recipientsInput) {
+			recipientsInput = append(recipientsInput, recipient)
+		}
+	}
+	if len(recipientsInput) > 0 && !requireAccessTokenInput {
+		action.Errorf("The recipients are sent their own links, which require the portal's access token")
+		return nil, errors.ErrInvalidRecipientsProvided
+	}
+
+	// handle inputs for fetching how clients are rate limited and locked out, either
+	// of which can be turned off with "0"
+	rateLimitRequests, rateLimitWindow, err := parseRateInput(action.GetInput("rate-limit"), DefaultRateLimit)
+	if err != nil {
+		action.Errorf("The rate-limit must be in the <requests>/<window> format, i.e. %s: %v", DefaultRateLimit, err)
+		return nil, errors.ErrInvalidRateLimitProvided
+	}
+
+	authLockoutAttempts, authLockoutWindow, err := parseRateInput(action.GetInput("auth-lockout"), DefaultAuthLockout)
+	if err != nil {
+		action.Errorf("The auth-lockout must be in the <attempts>/<window> format, i.e. %s: %v", DefaultAuthLockout, err)
+		return nil, errors.ErrInvalidRateLimitProvided
 	}
 
+	trustForwardedForInput := action.GetInput("trust-forwarded-for") == "true"
+
+	// handle inputs for fetching where the portal's activity is recorded to
+	auditLogPathInput := strings.TrimSpace(action.GetInput("audit-log"))
+	if auditLogPathInput != "" && !filepath.IsAbs(auditLogPathInput) {
+		auditLogPathInput = filepath.Join(action.Getenv("GITHUB_WORKSPACE"), auditLogPathInput)
+	}
+
+	auditLogWebhookUrlInput := strings.TrimSpace(action.GetInput("audit-log-webhook"))
+	if auditLogWebhookUrlInput != "" {
+		parsedAuditLogWebhookUrl, err := url.Parse(auditLogWebhookUrlInput)
+		if err != nil || (parsedAuditLogWebhookUrl.Scheme != "http" && parsedAuditLogWebhookUrl.Scheme != "https") || parsedAuditLogWebhookUrl.Host == "" {
+			action.Errorf("The audit-log-webhook must be an HTTP(S) URL")
+			return nil, errors.ErrInvalidAuditLogProvided
+		}
+	}
+
+	// handle input for fetching whether the portal's metrics are exposed
+	metricsEnabledInput := action.GetInput("metrics") == "true"
+
 	// handle input for fetching start port
 	var startPort int = DefaultStartPort
 	startPortInput := action.GetInput("start-port")
@@ -128,6 +530,16 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 		return nil, errors.ErrGithubTokenNotProvided
 	}
 
+	// handle input for fetching the base URL of the GitHub API, i.e. for GitHub Enterprise Server
+	githubApiUrlInput := strings.TrimSuffix(strings.TrimSpace(action.GetInput("github-api-url")), "/")
+	if githubApiUrlInput != "" {
+		parsedGithubApiUrl, err := url.Parse(githubApiUrlInput)
+		if err != nil || (parsedGithubApiUrl.Scheme != "http" && parsedGithubApiUrl.Scheme != "https") || parsedGithubApiUrl.Host == "" {
+			action.Errorf("The github-api-url must be an HTTP(S) URL")
+			return nil, errors.ErrInvalidGithubApiUrlProvided
+		}
+	}
+
 	// handle input for fetching timeout
 	var timeout int
 	timeoutInput := action.GetInput("timeout")
@@ -143,6 +555,45 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 		}
 	}
 
+	// handle input for fetching the answers file used to prefill the portal
+	prefillAnswersFileInput := strings.TrimSpace(action.GetInput("prefill-answers-file"))
+	if prefillAnswersFileInput != "" {
+		if !filepath.IsAbs(prefillAnswersFileInput) {
+			prefillAnswersFileInput = filepath.Join(action.Getenv("GITHUB_WORKSPACE"), prefillAnswersFileInput)
+		}
+		action.Debugf("Prefill answers file provided: %s", prefillAnswersFileInput)
+	}
+
+	// handle input for fetching the scanner uploaded files are scanned with
+	uploadScannerCommandInput := strings.TrimSpace(action.GetInput("upload-scanner-command"))
+	uploadScannerUrlInput := strings.TrimSpace(action.GetInput("upload-scanner-url"))
+	if uploadScannerCommandInput != "" && uploadScannerUrlInput != "" {
+		action.Errorf("Only one of 'upload-scanner-command' and 'upload-scanner-url' can be provided")
+		return nil, errors.ErrInvalidUploadScannerProvided
+	}
+
+	uploadScannerActionInput := strings.ToLower(strings.TrimSpace(action.GetInput("upload-scanner-action")))
+	if uploadScannerActionInput == "" {
+		uploadScannerActionInput = UploadScannerActionReject
+	}
+	if uploadScannerActionInput != UploadScannerActionReject && uploadScannerActionInput != UploadScannerActionQuarantine {
+		action.Errorf("The upload-scanner-action '%s' is not supported, valid actions are: %s, %s", uploadScannerActionInput, UploadScannerActionReject, UploadScannerActionQuarantine)
+		return nil, errors.ErrInvalidUploadScannerProvided
+	}
+	if uploadScannerCommandInput != "" || uploadScannerUrlInput != "" {
+		action.Debugf("Upload scanner provided, flagged files will be %sed", strings.TrimSuffix(uploadScannerActionInput, "e"))
+	}
+
+	// handle input for fetching what happens when the portal is cancelled
+	cancelBehaviourInput := strings.ToLower(strings.TrimSpace(action.GetInput("cancel-behaviour")))
+	if cancelBehaviourInput == "" {
+		cancelBehaviourInput = CancelBehaviourFailStep
+	}
+	if cancelBehaviourInput != CancelBehaviourFailStep && cancelBehaviourInput != CancelBehaviourCancelRun && cancelBehaviourInput != CancelBehaviourSucceedWithFlag {
+		action.Errorf("The cancel-behaviour '%s' is not supported, valid behaviours are: %s, %s, %s", cancelBehaviourInput, CancelBehaviourFailStep, CancelBehaviourCancelRun, CancelBehaviourSucceedWithFlag)
+		return nil, errors.ErrInvalidCancelBehaviourProvided
+	}
+
 	// handle input for fetching form title if provided
 	titleInput := action.GetInput("title")
 	if titleInput != "" {
@@ -196,11 +647,74 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 		notifierDiscordThreadId = strings.TrimSpace(action.GetInput("notifier-discord-thread-id"))
 	}
 
+	// handle input for fetching github notifier
+	var notifierGithubTarget string
+
+	notifierGithubEnabledInput := action.GetInput("notifier-github-enabled") == "true"
+	if notifierGithubEnabledInput {
+
+		notifierGithubTarget = strings.ToLower(strings.TrimSpace(action.GetInput("notifier-github-target")))
+		if notifierGithubTarget == "" {
+			notifierGithubTarget = NotifierGithubTargetComment
+		}
+		if notifierGithubTarget != NotifierGithubTargetComment && notifierGithubTarget != NotifierGithubTargetStatus {
+			action.Errorf("The notifier-github-target '%s' is not supported, valid targets are: %s, %s", notifierGithubTarget, NotifierGithubTargetComment, NotifierGithubTargetStatus)
+			return nil, errors.ErrInvalidGithubNotifierTargetProvided
+		}
+	}
+
+	// handle inputs for fetching how replies to the github notifier's comment are answered with
+	var commentReplyPrefix string
+	var commentReplyPollInterval time.Duration
+
+	commentRepliesEnabledInput := action.GetInput("comment-replies") == "true"
+	if commentRepliesEnabledInput {
+
+		if !notifierGithubEnabledInput || notifierGithubTarget != NotifierGithubTargetComment {
+			action.Errorf("The comment-replies can only be used when the GitHub notifier is enabled with the '%s' target", NotifierGithubTargetComment)
+			return nil, errors.ErrInvalidCommentRepliesProvided
+		}
+
+		commentReplyPrefix = strings.TrimSpace(action.GetInput("comment-reply-prefix"))
+		if commentReplyPrefix == "" {
+			commentReplyPrefix = DefaultCommentReplyPrefix
+		}
+		if strings.ContainsAny(commentReplyPrefix, " \t") {
+			action.Errorf("The comment-reply-prefix '%s' can't contain whitespace", commentReplyPrefix)
+			return nil, errors.ErrInvalidCommentRepliesProvided
+		}
+
+		// replies are attributed to GitHub usernames, so recipients listed by email can't reply
+		for _, recipient := range recipientsInput {
+			if emailRegex.MatchString(recipient) {
+				action.Warningf("The recipient '%s' is an email, so they can't answer by replying to the GitHub notifier's comment", recipient)
+			}
+		}
+
+		commentReplyPollInterval = DefaultCommentReplyPollInterval
+		if commentReplyPollIntervalInput := strings.TrimSpace(action.GetInput("comment-reply-poll-interval")); commentReplyPollIntervalInput != "" {
+			commentReplyPollInterval, err = time.ParseDuration(commentReplyPollIntervalInput)
+			if err != nil || commentReplyPollInterval < MinCommentReplyPollInterval {
+				action.Errorf("The comment-reply-poll-interval must be a duration of at least %s, i.e. %s, got: %s", MinCommentReplyPollInterval, DefaultCommentReplyPollInterval, commentReplyPollIntervalInput)
+				return nil, errors.ErrInvalidCommentRepliesProvided
+			}
+		}
+	}
+
 	// handle masking of sensitive data
 	action.AddMask(notifierSlackToken)
 	action.AddMask(notifierDiscordWebhook)
 	action.AddMask(githubTokenInput)
 	action.AddMask(ngrokAuthtokenInput)
+	if portalAccessTokenInput != "" {
+		action.AddMask(portalAccessTokenInput)
+	}
+	if uploadScannerUrlInput != "" {
+		action.AddMask(uploadScannerUrlInput)
+	}
+	if auditLogWebhookUrlInput != "" {
+		action.AddMask(auditLogWebhookUrlInput)
+	}
 
 	c := Config{
 		Title:   titleInput,
@@ -209,11 +723,57 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 
 		NgrokAuthtoken: ngrokAuthtokenInput,
 		GithubToken:    githubTokenInput,
+		GithubApiUrl:   githubApiUrlInput,
 
 		UseNetworkIP: useNetworkIPInput,
 		NetworkIP:    networkIPInput,
 		StartPort:    startPort,
 
+		NetworkInterface: networkInterfaceInput,
+		NetworkCidr:      networkCidrInput,
+		NetworkIPScope:   networkIPScope,
+		NetworkIPFamily:  networkIPFamily,
+		NetworkHostname:  networkHostnameInput,
+
+		TlsEnabled:      tlsEnabledInput,
+		TlsCert:         tlsCertInput,
+		TlsKey:          tlsKeyInput,
+		TlsRedirectHttp: tlsRedirectHttpInput,
+
+		RequireAccessToken: requireAccessTokenInput,
+		PortalAccessToken:  portalAccessTokenInput,
+		Recipients:         recipientsInput,
+
+		RateLimitRequests:   rateLimitRequests,
+		RateLimitWindow:     rateLimitWindow,
+		AuthLockoutAttempts: authLockoutAttempts,
+		AuthLockoutWindow:   authLockoutWindow,
+		TrustForwardedFor:   trustForwardedForInput,
+
+		AuditLogPath:       auditLogPathInput,
+		AuditLogWebhookUrl: auditLogWebhookUrlInput,
+
+		MetricsEnabled: metricsEnabledInput,
+
+		Expose:               exposeInput,
+		TunnelSshDestination: tunnelSshDestinationInput,
+		TunnelSshRemotePort:  tunnelSshRemotePort,
+		TunnelSshPublicUrl:   tunnelSshPublicUrlInput,
+		TunnelCommand:        tunnelCommandInput,
+		TunnelUrlPattern:     tunnelUrlPatternInput,
+
+		PrefillAnswersFile: prefillAnswersFileInput,
+
+		Mode:        modeInput,
+		AnswersFile: answersFileInput,
+		Answers:     answersInput,
+
+		UploadScannerCommand: uploadScannerCommandInput,
+		UploadScannerUrl:     uploadScannerUrlInput,
+		UploadScannerAction:  uploadScannerActionInput,
+
+		CancelBehaviour: cancelBehaviourInput,
+
 		NotifierSlackEnabled:  notifierSlackEnabledInput,
 		NotifierSlackToken:    notifierSlackToken,
 		NotifierSlackChannel:  notifierSlackChannel,
@@ -225,7 +785,84 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 		NotifierDiscordUsernameOverride: notifierDiscordUsernameOverride,
 		NotifierDiscordThreadId:         notifierDiscordThreadId,
 
+		NotifierGithubEnabled: notifierGithubEnabledInput,
+		NotifierGithubTarget:  notifierGithubTarget,
+
+		CommentRepliesEnabled:    commentRepliesEnabledInput,
+		CommentReplyPrefix:       commentReplyPrefix,
+		CommentReplyPollInterval: commentReplyPollInterval,
+
 		Action: action,
 	}
 	return &c, nil
 }
+
+// resolvePemInput returns the given PEM content as is, or the path to a file holding it
+// resolved against the workspace
+func resolvePemInput(action *githubactions.Action, pemOrPath string) string {
+	pemOrPath = strings.TrimSpace(pemOrPath)
+	if pemOrPath == "" || strings.HasPrefix(pemOrPath, "-----BEGIN") || filepath.IsAbs(pemOrPath) {
+		return pemOrPath
+	}
+	return filepath.Join(action.Getenv("GITHUB_WORKSPACE"), pemOrPath)
+}
+
+// parseRateInput parses a count and the window it applies to from the input (i.e. "60/1m"),
+// falling back to the default when the input isn't provided. "0" turns the rate off.
+func parseRateInput(input, defaultInput string) (int, time.Duration, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		input = defaultInput
+	}
+
+	if input == "0" {
+		return 0, 0, nil
+	}
+
+	countInput, windowInput, found := strings.Cut(input, "/")
+	if !found {
+		return 0, 0, fmt.Errorf("missing the window")
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(countInput))
+	if err != nil || count < 1 {
+		return 0, 0, fmt.Errorf("the count must be a positive number, got: %s", countInput)
+	}
+
+	window, err := time.ParseDuration(strings.TrimSpace(windowInput))
+	if err != nil || window <= 0 {
+		return 0, 0, fmt.Errorf("the window must be a positive duration, got: %s", windowInput)
+	}
+
+	return count, window, nil
+}
+
+// githubUsernameRegex matches a GitHub username, i.e. octo-cat
+var githubUsernameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,37}[a-z0-9])?$`)
+
+// emailRegex matches a plain email address, i.e. octo.cat@example.com
+var emailRegex = regexp.MustCompile(`^[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}$`)
+
+// hostnameRegex matches a DNS hostname made up of one or more labels, i.e. runner-01.example.com
+var hostnameRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+
+// parseListInput parses a lowercased, deduplicated list from the comma or newline separated input,
+// optionally wrapped in square brackets (i.e. "[ngrok, network-ip, localhost]")
+func parseListInput(input string) []string {
+	var items []string
+	var seen map[string]bool = make(map[string]bool)
+
+	input = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(input), "["), "]")
+
+	for _, item := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == '\n' }) {
+		item = strings.ToLower(strings.Trim(strings.TrimSpace(item), `"'`))
+		if item == "" || seen[item] {
+			continue
+		}
+
+		seen[item] = true
+		items = append(items, item)
+	}
+
+	return items
+}
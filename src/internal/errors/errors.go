@@ -41,6 +41,17 @@ var (
 	// returns a status code other than 200 or 401
 	ErrUnexpectedDiscordVerificationStatusCode = errors.New("UnexpectedDiscordVerificationStatusCode")
 
+	// ErrInvalidGithubNotifierTargetProvided is returned when the GitHub notifier's target isn't supported
+	ErrInvalidGithubNotifierTargetProvided = errors.New("InvalidGithubNotifierTargetProvided")
+
+	// ErrGithubNotifierVerificationFailed is returned when the GitHub notifier can't access the repository
+	// or find where to post to
+	ErrGithubNotifierVerificationFailed = errors.New("GithubNotifierVerificationFailed")
+
+	// ErrInvalidCommentRepliesProvided is returned when comment replies are enabled without the GitHub
+	// notifier commenting, or their prefix or poll interval are invalid
+	ErrInvalidCommentRepliesProvided = errors.New("InvalidCommentRepliesProvided")
+
 	// ErrFailedToSendMessageWithNotifier is returned when the notifier is unable to send a message
 	ErrFailedToSendMessageWithNotifier = errors.New("FailedToSendMessageWithNotifier")
 
@@ -52,4 +63,103 @@ var (
 
 	// ErrDuplicateFieldLabelDetected is returned when the same field label is detected in the input data
 	ErrDuplicateFieldLabelDetected = errors.New("DuplicateFieldLabelDetected")
+
+	// ErrNoGroupSubFieldsProvided is returned when a group (table) field is defined without any sub-fields
+	ErrNoGroupSubFieldsProvided = errors.New("NoGroupSubFieldsProvided")
+
+	// ErrInvalidGroupSubFieldTypeProvided is returned when the type of a group (table) field's sub-field
+	// is not one of the supported sub-field types
+	ErrInvalidGroupSubFieldTypeProvided = errors.New("InvalidGroupSubFieldTypeProvided")
+
+	// ErrInvalidGroupRowLimitsProvided is returned when the minRows/maxRows of a group (table) field
+	// are negative or contradict each other
+	ErrInvalidGroupRowLimitsProvided = errors.New("InvalidGroupRowLimitsProvided")
+
+	// ErrInvalidSchemaProvided is returned when the JSON Schema of a json/yaml field cannot be loaded or compiled
+	ErrInvalidSchemaProvided = errors.New("InvalidSchemaProvided")
+
+	// ErrInvalidStructuredOutputFormatProvided is returned when the output format of a json/yaml field
+	// is not one of the supported formats
+	ErrInvalidStructuredOutputFormatProvided = errors.New("InvalidStructuredOutputFormatProvided")
+
+	// ErrInvalidFieldValueProvided is returned when a value submitted for a field does not satisfy
+	// the field's definition
+	ErrInvalidFieldValueProvided = errors.New("InvalidFieldValueProvided")
+
+	// ErrInvalidModeProvided is returned when the mode provided is not one of the supported modes
+	ErrInvalidModeProvided = errors.New("InvalidModeProvided")
+
+	// ErrPromptInputClosed is returned when the terminal input is closed before all
+	// fields have been answered in prompt mode
+	ErrPromptInputClosed = errors.New("PromptInputClosed")
+
+	// ErrInvalidAnswersProvided is returned when the provided answers can't be parsed or
+	// don't satisfy the field definitions
+	ErrInvalidAnswersProvided = errors.New("InvalidAnswersProvided")
+
+	// ErrInvalidUploadLimitsProvided is returned when the maxFileSize, maxTotalSize or maxFiles of a
+	// file/multifile field are malformed or negative
+	ErrInvalidUploadLimitsProvided = errors.New("InvalidUploadLimitsProvided")
+
+	// ErrUnsupportedArchiveFormat is returned when a file can't be extracted as it isn't a zip, tar,
+	// tar.gz or tar.zst archive
+	ErrUnsupportedArchiveFormat = errors.New("UnsupportedArchiveFormat")
+
+	// ErrUnsafeArchiveEntry is returned when an archive holds an entry that would be extracted
+	// outside of the destination directory, i.e. "../../etc/passwd"
+	ErrUnsafeArchiveEntry = errors.New("UnsafeArchiveEntry")
+
+	// ErrArchiveTooLarge is returned when the files extracted from an archive exceed the size limit
+	ErrArchiveTooLarge = errors.New("ArchiveTooLarge")
+
+	// ErrArchiveHasTooManyEntries is returned when an archive holds more entries than the entry limit
+	ErrArchiveHasTooManyEntries = errors.New("ArchiveHasTooManyEntries")
+
+	// ErrInvalidUploadScannerProvided is returned when the upload scanner is misconfigured, i.e. both
+	// a command and URL are provided or the command can't be found
+	ErrInvalidUploadScannerProvided = errors.New("InvalidUploadScannerProvided")
+
+	// ErrUploadScanFailed is returned when an uploaded file couldn't be scanned, i.e. the scanner
+	// timed out or errored
+	ErrUploadScanFailed = errors.New("UploadScanFailed")
+
+	// ErrFileFlaggedByScanner is returned when the upload scanner flags a file provided in prompt or
+	// answers mode, which is always rejected as it can't be quarantined
+	ErrFileFlaggedByScanner = errors.New("FileFlaggedByScanner")
+
+	// ErrInvalidTunnelProviderProvided is returned when the inputs needed to tunnel to the portal
+	// over SSH or with a command are missing or malformed
+	ErrInvalidTunnelProviderProvided = errors.New("InvalidTunnelProviderProvided")
+
+	// ErrInvalidExposeMethodProvided is returned when one of the methods the portal is exposed with isn't supported
+	ErrInvalidExposeMethodProvided = errors.New("InvalidExposeMethodProvided")
+
+	// ErrInvalidNetworkIPSelectionProvided is returned when the network-cidr, network-ip-preference
+	// or network-hostname inputs are malformed
+	ErrInvalidNetworkIPSelectionProvided = errors.New("InvalidNetworkIPSelectionProvided")
+
+	// ErrInvalidTlsConfigProvided is returned when only one of the TLS certificate and key is
+	// provided, or they can't be loaded
+	ErrInvalidTlsConfigProvided = errors.New("InvalidTlsConfigProvided")
+
+	// ErrInvalidRecipientsProvided is returned when a recipient isn't a GitHub username or email, or
+	// recipients are provided without requiring the portal's access token
+	ErrInvalidRecipientsProvided = errors.New("InvalidRecipientsProvided")
+
+	// ErrInvalidRateLimitProvided is returned when the rate-limit or auth-lockout inputs aren't
+	// in the <count>/<window> format, i.e. 60/1m
+	ErrInvalidRateLimitProvided = errors.New("InvalidRateLimitProvided")
+
+	// ErrInvalidAuditLogProvided is returned when the audit-log-webhook isn't an HTTP(S) URL, or
+	// the audit log can't be opened
+	ErrInvalidAuditLogProvided = errors.New("InvalidAuditLogProvided")
+
+	// ErrInvalidCancelBehaviourProvided is returned when the cancel-behaviour isn't supported
+	ErrInvalidCancelBehaviourProvided = errors.New("InvalidCancelBehaviourProvided")
+
+	// ErrInvalidGithubApiUrlProvided is returned when the github-api-url isn't an HTTP(S) URL
+	ErrInvalidGithubApiUrlProvided = errors.New("InvalidGithubApiUrlProvided")
+
+	// ErrUnableToExposePortal is returned when none of the methods the portal is exposed with succeed
+	ErrUnableToExposePortal = errors.New("UnableToExposePortal")
 )
@@ -0,0 +1,132 @@
+package exposer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+const (
+	// DefaultStartPort is the first port tried when no start port is provided
+	DefaultStartPort = 8080
+
+	// maxPortAttempts is how many ports, counting up from the start port, are tried
+	maxPortAttempts = 100
+)
+
+// NewBindExposerRequest is the request object for creating a new
+// instance of a Bind Exposer.
+type NewBindExposerRequest struct {
+
+	// Host is the host the portal's URL is made up of, i.e. the runner's network IP.
+	// Defaults to localhost
+	Host string
+
+	// StartPort is the first port tried, the following ports are tried when it is occupied
+	StartPort int
+
+	// Tls is how the portal is served over TLS, it is served over plain HTTP when not provided
+	Tls *TlsOptions
+}
+
+// NewBindExposer returns a new instance of a Bind Exposer
+func NewBindExposer(r *NewBindExposerRequest) Exposer {
+
+	var host string = "localhost"
+	var startPort int = DefaultStartPort
+
+	if r.Host != "" {
+		host = r.Host
+	}
+
+	if r.StartPort != 0 {
+		startPort = r.StartPort
+	}
+
+	return &BindExposer{
+		host:      host,
+		startPort: startPort,
+		tls:       r.Tls,
+	}
+}
+
+// BindExposer is a struct that implements the Exposer interface by serving the
+// portal directly on one of the runner's ports, without a tunnel
+type BindExposer struct {
+
+	// host is the host the portal's URL is made up of
+	host string
+
+	// startPort is the first port tried
+	startPort int
+
+	// tls is how the portal is served over TLS, nil when it is served over plain HTTP
+	tls *TlsOptions
+
+	// listener is the listener the portal is served on
+	listener net.Listener
+}
+
+func (e *BindExposer) Expose(ctx context.Context) (net.Listener, string, error) {
+
+	listener, err := listenOnAvailablePort("", e.startPort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	scheme := "http"
+	if e.tls != nil {
+		listener = newTlsListener(listener, e.tls.Certificate, e.tls.RedirectHttp)
+		scheme = "https"
+	}
+
+	e.listener = listener
+	return listener, fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(e.host, fmt.Sprint(listenerPort(listener)))), nil
+}
+
+func (e *BindExposer) Close() error {
+	if e.listener == nil {
+		return nil
+	}
+	return ignoreClosedListenerErr(e.listener.Close())
+}
+
+func (e *BindExposer) Name() string {
+	return "bind"
+}
+
+func (e *BindExposer) SetsForwardedFor() bool {
+	return false
+}
+
+// listenOnAvailablePort listens on the first available port of the host (all interfaces when
+// empty), counting up from the start port
+func listenOnAvailablePort(host string, startPort int) (net.Listener, error) {
+
+	for port := startPort; port < startPort+maxPortAttempts && port <= 65535; port++ {
+		listener, err := net.Listen("tcp", net.JoinHostPort(host, fmt.Sprint(port)))
+		if err == nil {
+			return listener, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no available port found starting from %d (checked ports %d-%d)", startPort, startPort, startPort+maxPortAttempts-1)
+}
+
+// listenerPort returns the port the listener is listening on
+func listenerPort(listener net.Listener) int {
+	if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok {
+		return tcpAddr.Port
+	}
+	return 0
+}
+
+// ignoreClosedListenerErr ignores the error returned when the listener has already been
+// closed, i.e. by the server shutting down
+func ignoreClosedListenerErr(err error) error {
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
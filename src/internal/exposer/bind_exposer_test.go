@@ -0,0 +1,81 @@
+package exposer_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/exposer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindExposer_Expose(t *testing.T) {
+
+	// occupy a port, so that the exposer has to move on to the next one
+	occupiedListener, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	defer occupiedListener.Close()
+	occupiedPort := occupiedListener.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		name              string
+		host              string
+		expectedUrlPrefix string
+	}{
+		{
+			name:              "success - defaults to localhost",
+			expectedUrlPrefix: "http://localhost:",
+		},
+		{
+			name:              "success - network IP host",
+			host:              "10.1.2.3",
+			expectedUrlPrefix: "http://10.1.2.3:",
+		},
+		{
+			name:              "success - IPv6 host",
+			host:              "fd00::5",
+			expectedUrlPrefix: "http://[fd00::5]:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			bindExposer := exposer.NewBindExposer(&exposer.NewBindExposerRequest{
+				Host:      tt.host,
+				StartPort: occupiedPort,
+			})
+			defer bindExposer.Close()
+
+			listener, portalUrl, err := bindExposer.Expose(context.Background())
+			assert.NoError(t, err)
+
+			port := listener.Addr().(*net.TCPAddr).Port
+			assert.Greater(t, port, occupiedPort)
+			assert.Equal(t, fmt.Sprintf("%s%d", tt.expectedUrlPrefix, port), portalUrl)
+
+			assert.Equal(t, "bind", bindExposer.Name())
+			assert.False(t, bindExposer.SetsForwardedFor())
+		})
+	}
+}
+
+func TestBindExposer_Close(t *testing.T) {
+
+	bindExposer := exposer.NewBindExposer(&exposer.NewBindExposerRequest{StartPort: 18380})
+
+	// closing before exposing is a no-op
+	assert.NoError(t, bindExposer.Close())
+
+	listener, _, err := bindExposer.Expose(context.Background())
+	assert.NoError(t, err)
+
+	// the portal's server closes the listener when it shuts down, before the exposer is closed
+	server := &http.Server{}
+	go server.Serve(listener)
+	assert.NoError(t, server.Close())
+
+	assert.NoError(t, bindExposer.Close())
+}
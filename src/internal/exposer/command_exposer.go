@@ -0,0 +1,147 @@
+package exposer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/sethvargo/go-githubactions"
+)
+
+const (
+	// PortPlaceholder is replaced in the command with the local port the portal is served on
+	PortPlaceholder = "{port}"
+
+	// DefaultUrlPattern matches the first http(s) URL in the command's output
+	DefaultUrlPattern = `https?://[^\s|"'<>]+`
+)
+
+// NewCommandExposerRequest is the request object for creating a new
+// instance of a Command Exposer.
+type NewCommandExposerRequest struct {
+
+	// Command is the command run to expose the portal (i.e. "cloudflared tunnel --url http://localhost:{port}"),
+	// {port} is replaced with the local port the portal is served on. It isn't run through a shell.
+	Command string
+
+	// UrlPattern is the regular expression the public URL is parsed from the command's output with,
+	// the first capture group is used when it has one. Defaults to DefaultUrlPattern
+	UrlPattern string
+
+	// StartPort is the first local port tried, the following ports are tried when it is occupied
+	StartPort int
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+}
+
+// NewCommandExposer returns a new instance of a Command Exposer, or an error
+// if the command or URL pattern is invalid
+func NewCommandExposer(r *NewCommandExposerRequest) (Exposer, error) {
+
+	var urlPattern string = DefaultUrlPattern
+	var startPort int = DefaultStartPort
+
+	commandParts := strings.Fields(r.Command)
+	if len(commandParts) == 0 {
+		return nil, fmt.Errorf("%w: no tunnel command provided", errors.ErrInvalidTunnelProviderProvided)
+	}
+
+	if r.UrlPattern != "" {
+		urlPattern = r.UrlPattern
+	}
+
+	compiledUrlPattern, err := regexp.Compile(urlPattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid tunnel URL pattern: %v", errors.ErrInvalidTunnelProviderProvided, err)
+	}
+
+	if r.StartPort != 0 {
+		startPort = r.StartPort
+	}
+
+	return &CommandExposer{
+		name:       commandParts[0],
+		args:       commandParts[1:],
+		urlPattern: compiledUrlPattern,
+		startPort:  startPort,
+		action:     r.ActionPkg,
+	}, nil
+}
+
+// CommandExposer is a struct that implements the Exposer interface by running a command that
+// tunnels to the portal (i.e. cloudflared or bore), parsing the public URL from its output
+type CommandExposer struct {
+
+	// name is the name (or path) of the command
+	name string
+
+	// args the arguments passed to the command
+	args []string
+
+	// urlPattern is the regular expression the public URL is parsed from the command's output with
+	urlPattern *regexp.Regexp
+
+	// startPort is the first local port tried
+	startPort int
+
+	// action represents the githubactions package
+	action *githubactions.Action
+
+	// listener is the local listener the portal is served on
+	listener net.Listener
+
+	// tunnel is the running command
+	tunnel *tunnelCommand
+}
+
+func (e *CommandExposer) Expose(ctx context.Context) (net.Listener, string, error) {
+
+	// only the tunnel needs to reach the portal, so it isn't served on the runner's network
+	listener, err := listenOnAvailablePort("127.0.0.1", e.startPort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	args := make([]string, len(e.args))
+	for i, arg := range e.args {
+		args[i] = strings.ReplaceAll(arg, PortPlaceholder, fmt.Sprint(listenerPort(listener)))
+	}
+
+	tunnel, publicUrl, err := startTunnelCommand(ctx, e.action, e.name, args, e.urlPattern)
+	if err != nil {
+		listener.Close()
+		return nil, "", err
+	}
+
+	// tools such as bore print the host and port without a scheme
+	if !strings.Contains(publicUrl, "://") {
+		publicUrl = "http://" + publicUrl
+	}
+
+	e.listener = listener
+	e.tunnel = tunnel
+	return listener, publicUrl, nil
+}
+
+func (e *CommandExposer) Close() error {
+	if e.tunnel != nil {
+		e.tunnel.stop()
+	}
+	if e.listener == nil {
+		return nil
+	}
+	return ignoreClosedListenerErr(e.listener.Close())
+}
+
+func (e *CommandExposer) Name() string {
+	return filepath.Base(e.name)
+}
+
+func (e *CommandExposer) SetsForwardedFor() bool {
+	return false
+}
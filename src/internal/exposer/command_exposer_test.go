@@ -0,0 +1,141 @@
+package exposer_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/exposer"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeStubCommand writes a shell script with the given body to the directory, returning its path
+func writeStubCommand(t *testing.T, dir, name, body string) string {
+	scriptPath := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(scriptPath, []byte("#!/bin/sh\n"+body), 0755))
+	return scriptPath
+}
+
+func TestCommandExposer_Expose(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		script        string
+		args          string
+		urlPattern    string
+		expectedUrl   string
+		expectedError bool
+	}{
+		{
+			name:        "success - first URL in the output is used",
+			script:      "echo 'starting tunnel' >&2\necho '| https://quiet-fox.trycloudflare.com |' >&2\nexec sleep 30\n",
+			expectedUrl: "https://quiet-fox.trycloudflare.com",
+		},
+		{
+			name:        "success - port placeholder is replaced",
+			script:      "echo \"http://tunnel.example.com/$1\"\nexec sleep 30\n",
+			args:        "--url=http://localhost:{port}",
+			expectedUrl: "http://tunnel.example.com/--url=http://localhost:%d",
+		},
+		{
+			name:        "success - capture group of the URL pattern without a scheme",
+			script:      "echo 'listening at bore.pub:41234'\nexec sleep 30\n",
+			urlPattern:  `listening at (\S+)`,
+			expectedUrl: "http://bore.pub:41234",
+		},
+		{
+			name:          "failed - command exits before printing a URL",
+			script:        "echo 'unable to connect' >&2\nexit 1\n",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			command := writeStubCommand(t, t.TempDir(), "tunnel", tt.script)
+
+			commandExposer, err := exposer.NewCommandExposer(&exposer.NewCommandExposerRequest{
+				Command:    command + " " + tt.args,
+				UrlPattern: tt.urlPattern,
+				StartPort:  18480,
+				ActionPkg:  githubactions.New(githubactions.WithWriter(io.Discard)),
+			})
+			assert.NoError(t, err)
+			defer commandExposer.Close()
+
+			listener, portalUrl, err := commandExposer.Expose(context.Background())
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			// only the tunnel needs to reach the portal
+			tcpAddr := listener.Addr().(*net.TCPAddr)
+			assert.True(t, tcpAddr.IP.IsLoopback())
+
+			expectedUrl := tt.expectedUrl
+			if tt.args != "" {
+				expectedUrl = fmt.Sprintf(tt.expectedUrl, tcpAddr.Port)
+			}
+			assert.Equal(t, expectedUrl, portalUrl)
+
+			assert.Equal(t, "tunnel", commandExposer.Name())
+			assert.False(t, commandExposer.SetsForwardedFor())
+
+			assert.NoError(t, commandExposer.Close())
+		})
+	}
+}
+
+func TestNewCommandExposer(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		command       string
+		urlPattern    string
+		expectedError error
+	}{
+		{
+			name:    "success - default URL pattern",
+			command: "cloudflared tunnel --url http://localhost:{port}",
+		},
+		{
+			name:          "failed - no command",
+			command:       "   ",
+			expectedError: errors.ErrInvalidTunnelProviderProvided,
+		},
+		{
+			name:          "failed - invalid URL pattern",
+			command:       "bore local {port} --to bore.pub",
+			urlPattern:    `listening at (\S+`,
+			expectedError: errors.ErrInvalidTunnelProviderProvided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			_, err := exposer.NewCommandExposer(&exposer.NewCommandExposerRequest{
+				Command:    tt.command,
+				UrlPattern: tt.urlPattern,
+			})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
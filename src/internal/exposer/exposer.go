@@ -0,0 +1,24 @@
+package exposer
+
+import (
+	"context"
+	"net"
+)
+
+// Exposer exposes the portal to whoever provides the inputs
+type Exposer interface {
+
+	// Expose starts exposing the portal, returning the listener the portal is served
+	// on and the URL it can be reached at
+	Expose(ctx context.Context) (net.Listener, string, error)
+
+	// Close stops exposing the portal
+	Close() error
+
+	// Name returns the name of the exposer
+	Name() string
+
+	// SetsForwardedFor returns true when the tunnel provider adds the client's address to the
+	// X-Forwarded-For header itself
+	SetsForwardedFor() bool
+}
@@ -0,0 +1,213 @@
+package exposer
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	// NetworkIPScopePrivate prefers private addresses, i.e. 10.0.0.0/8 or fc00::/7
+	NetworkIPScopePrivate = "private"
+
+	// NetworkIPScopePublic prefers publicly routable addresses
+	NetworkIPScopePublic = "public"
+
+	// NetworkIPFamilyV4 prefers IPv4 addresses
+	NetworkIPFamilyV4 = "v4"
+
+	// NetworkIPFamilyV6 prefers IPv6 addresses
+	NetworkIPFamilyV6 = "v6"
+)
+
+// virtualInterfacePrefixes are the name prefixes of interfaces created for containers and
+// virtual machines, whose addresses usually can't be reached from outside the runner
+var virtualInterfacePrefixes = []string{"docker", "br-", "veth", "virbr", "cni", "flannel", "cali", "lxc", "lxd", "podman"}
+
+// DetectNetworkIPRequest holds the filters and preferences used to pick the network IP
+type DetectNetworkIPRequest struct {
+
+	// Interface is the name of the only interface considered, i.e. "eth0" (optional)
+	Interface string
+
+	// Cidr is the range the network IP must fall within, i.e. "10.0.0.0/8" (optional)
+	Cidr string
+
+	// Scope is whether private or public addresses are preferred. Defaults to NetworkIPScopePrivate
+	Scope string
+
+	// Family is whether IPv4 or IPv6 addresses are preferred. Defaults to NetworkIPFamilyV4
+	Family string
+
+	// Interfaces are the interfaces the network IP is picked from (optional). Defaults to the
+	// runner's interfaces
+	Interfaces []NetworkInterface
+}
+
+// NetworkInterface is a network interface along with its addresses
+type NetworkInterface struct {
+
+	// Name is the name of the interface, i.e. "eth0"
+	Name string
+
+	// Flags are the interface's flags, i.e. whether it is up or a loopback interface
+	Flags net.Flags
+
+	// Addrs are the interface's addresses
+	Addrs []net.Addr
+}
+
+// networkIPCandidate is an address of one of the runner's interfaces
+type networkIPCandidate struct {
+
+	// ip is the address
+	ip net.IP
+
+	// interfaceName is the name of the interface the address belongs to
+	interfaceName string
+}
+
+// DetectNetworkIP returns the runner's preferred network IP by enumerating its interfaces
+func DetectNetworkIP(r *DetectNetworkIPRequest) (string, error) {
+
+	var scope string = NetworkIPScopePrivate
+	var family string = NetworkIPFamilyV4
+	var cidr *net.IPNet
+	var candidates []networkIPCandidate
+
+	if r.Scope != "" {
+		scope = r.Scope
+	}
+
+	if r.Family != "" {
+		family = r.Family
+	}
+
+	if r.Cidr != "" {
+		_, parsedCidr, err := net.ParseCIDR(r.Cidr)
+		if err != nil {
+			return "", fmt.Errorf("invalid CIDR '%s': %v", r.Cidr, err)
+		}
+		cidr = parsedCidr
+	}
+
+	interfaces := r.Interfaces
+	if interfaces == nil {
+		listedInterfaces, err := listNetworkInterfaces()
+		if err != nil {
+			return "", fmt.Errorf("unable to list the network interfaces: %v", err)
+		}
+		interfaces = listedInterfaces
+	}
+
+	for _, networkInterface := range interfaces {
+		if r.Interface != "" && networkInterface.Name != r.Interface {
+			continue
+		}
+
+		if networkInterface.Flags&net.FlagUp == 0 || networkInterface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		for _, addr := range networkInterface.Addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+
+			ip := ipNet.IP
+			if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsMulticast() || ip.IsUnspecified() {
+				continue
+			}
+
+			if cidr != nil && !cidr.Contains(ip) {
+				continue
+			}
+
+			candidates = append(candidates, networkIPCandidate{ip: ip, interfaceName: networkInterface.Name})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no usable network IP found%s", describeNetworkIPFilters(r))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return rankNetworkIPCandidate(candidates[i], scope, family) > rankNetworkIPCandidate(candidates[j], scope, family)
+	})
+
+	return candidates[0].ip.String(), nil
+}
+
+// listNetworkInterfaces returns the runner's network interfaces along with their addresses,
+// interfaces whose addresses can't be read are returned without any
+func listNetworkInterfaces() ([]NetworkInterface, error) {
+	var networkInterfaces []NetworkInterface = []NetworkInterface{}
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, networkInterface := range interfaces {
+		addrs, _ := networkInterface.Addrs()
+		networkInterfaces = append(networkInterfaces, NetworkInterface{
+			Name:  networkInterface.Name,
+			Flags: networkInterface.Flags,
+			Addrs: addrs,
+		})
+	}
+
+	return networkInterfaces, nil
+}
+
+// rankNetworkIPCandidate scores the candidate, the higher the score the more it is preferred
+func rankNetworkIPCandidate(candidate networkIPCandidate, scope, family string) int {
+	var rank int
+
+	if !isVirtualInterface(candidate.interfaceName) {
+		rank += 4
+	}
+
+	isV4 := candidate.ip.To4() != nil
+	if isV4 == (family == NetworkIPFamilyV4) {
+		rank += 2
+	}
+
+	if candidate.ip.IsPrivate() == (scope == NetworkIPScopePrivate) {
+		rank++
+	}
+
+	return rank
+}
+
+// isVirtualInterface returns true when the interface looks like it was created for containers
+// or virtual machines
+func isVirtualInterface(interfaceName string) bool {
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(interfaceName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeNetworkIPFilters returns the filters applied when detecting the network IP, for use in errors
+func describeNetworkIPFilters(r *DetectNetworkIPRequest) string {
+	var filters []string
+
+	if r.Interface != "" {
+		filters = append(filters, fmt.Sprintf("on interface %s", r.Interface))
+	}
+
+	if r.Cidr != "" {
+		filters = append(filters, fmt.Sprintf("within %s", r.Cidr))
+	}
+
+	if len(filters) == 0 {
+		return ""
+	}
+
+	return " " + strings.Join(filters, " ")
+}
@@ -0,0 +1,145 @@
+package exposer_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/exposer"
+	"github.com/stretchr/testify/assert"
+)
+
+// newNetworkInterface returns an interface that is up with the given CIDR addresses
+func newNetworkInterface(t *testing.T, name string, cidrs ...string) exposer.NetworkInterface {
+	networkInterface := exposer.NetworkInterface{Name: name, Flags: net.FlagUp}
+
+	for _, cidr := range cidrs {
+		ip, ipNet, err := net.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		ipNet.IP = ip
+		networkInterface.Addrs = append(networkInterface.Addrs, ipNet)
+	}
+
+	return networkInterface
+}
+
+func TestDetectNetworkIP(t *testing.T) {
+
+	loopback := newNetworkInterface(t, "lo", "127.0.0.1/8", "::1/128")
+	loopback.Flags |= net.FlagLoopback
+
+	downInterface := newNetworkInterface(t, "eth2", "10.9.9.9/24")
+	downInterface.Flags = 0
+
+	tests := []struct {
+		name          string
+		request       exposer.DetectNetworkIPRequest
+		interfaces    []exposer.NetworkInterface
+		expectedIP    string
+		expectedError string
+	}{
+		{
+			name:       "success - loopback interfaces and addresses are ignored",
+			interfaces: []exposer.NetworkInterface{loopback, newNetworkInterface(t, "eth0", "127.0.0.2/8", "10.0.0.5/24")},
+			expectedIP: "10.0.0.5",
+		},
+		{
+			name:       "success - link-local addresses are ignored",
+			interfaces: []exposer.NetworkInterface{newNetworkInterface(t, "eth0", "169.254.10.1/16", "fe80::1/64", "203.0.113.7/24")},
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:       "success - interfaces that are down are ignored",
+			interfaces: []exposer.NetworkInterface{downInterface, newNetworkInterface(t, "eth0", "192.168.1.20/24")},
+			expectedIP: "192.168.1.20",
+		},
+		{
+			name:       "success - private addresses are preferred by default",
+			interfaces: []exposer.NetworkInterface{newNetworkInterface(t, "eth0", "203.0.113.7/24", "10.0.0.5/24")},
+			expectedIP: "10.0.0.5",
+		},
+		{
+			name:       "success - public addresses are preferred with the public scope",
+			request:    exposer.DetectNetworkIPRequest{Scope: exposer.NetworkIPScopePublic},
+			interfaces: []exposer.NetworkInterface{newNetworkInterface(t, "eth0", "10.0.0.5/24", "203.0.113.7/24")},
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:       "success - IPv4 addresses are preferred by default",
+			interfaces: []exposer.NetworkInterface{newNetworkInterface(t, "eth0", "fd00::5/64", "203.0.113.7/24")},
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:       "success - IPv6 addresses are preferred with the v6 family",
+			request:    exposer.DetectNetworkIPRequest{Family: exposer.NetworkIPFamilyV6},
+			interfaces: []exposer.NetworkInterface{newNetworkInterface(t, "eth0", "10.0.0.5/24", "2001:db8::5/64", "fd00::5/64")},
+			expectedIP: "fd00::5",
+		},
+		{
+			name:       "success - family is preferred over scope",
+			request:    exposer.DetectNetworkIPRequest{Family: exposer.NetworkIPFamilyV6},
+			interfaces: []exposer.NetworkInterface{newNetworkInterface(t, "eth0", "10.0.0.5/24", "2001:db8::5/64")},
+			expectedIP: "2001:db8::5",
+		},
+		{
+			name: "success - physical interfaces are preferred over container bridges",
+			interfaces: []exposer.NetworkInterface{
+				newNetworkInterface(t, "docker0", "172.17.0.1/16"),
+				newNetworkInterface(t, "eth0", "2001:db8::5/64"),
+			},
+			expectedIP: "2001:db8::5",
+		},
+		{
+			name:    "success - only the selected interface is considered",
+			request: exposer.DetectNetworkIPRequest{Interface: "br-1234"},
+			interfaces: []exposer.NetworkInterface{
+				newNetworkInterface(t, "eth0", "10.0.0.5/24"),
+				newNetworkInterface(t, "br-1234", "172.18.0.1/16"),
+			},
+			expectedIP: "172.18.0.1",
+		},
+		{
+			name:    "success - only addresses within the CIDR are considered",
+			request: exposer.DetectNetworkIPRequest{Cidr: "192.168.0.0/16"},
+			interfaces: []exposer.NetworkInterface{
+				newNetworkInterface(t, "eth0", "10.0.0.5/24"),
+				newNetworkInterface(t, "tailscale0", "192.168.50.3/24"),
+			},
+			expectedIP: "192.168.50.3",
+		},
+		{
+			name:          "failed - no usable address",
+			interfaces:    []exposer.NetworkInterface{loopback, newNetworkInterface(t, "eth0", "fe80::1/64")},
+			expectedError: "no usable network IP found",
+		},
+		{
+			name:          "failed - no address matches the filters",
+			request:       exposer.DetectNetworkIPRequest{Interface: "eth0", Cidr: "192.168.0.0/16"},
+			interfaces:    []exposer.NetworkInterface{newNetworkInterface(t, "eth0", "10.0.0.5/24")},
+			expectedError: "no usable network IP found on interface eth0 within 192.168.0.0/16",
+		},
+		{
+			name:          "failed - invalid CIDR",
+			request:       exposer.DetectNetworkIPRequest{Cidr: "10.0.0.0/33"},
+			interfaces:    []exposer.NetworkInterface{newNetworkInterface(t, "eth0", "10.0.0.5/24")},
+			expectedError: "invalid CIDR '10.0.0.0/33'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			request := tt.request
+			request.Interfaces = tt.interfaces
+
+			ip, err := exposer.DetectNetworkIP(&request)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedIP, ip)
+		})
+	}
+}
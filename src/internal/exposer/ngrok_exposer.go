@@ -0,0 +1,87 @@
+package exposer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.ngrok.com/ngrok"
+	nconfig "golang.ngrok.com/ngrok/config"
+)
+
+// ngrokConnectTimeout is how long connecting to the ngrok service is given before giving up
+const ngrokConnectTimeout = 30 * time.Second
+
+// NewNgrokExposerRequest is the request object for creating a new
+// instance of a Ngrok Exposer.
+type NewNgrokExposerRequest struct {
+
+	// Authtoken is the authtoken used to create the ngrok tunnel
+	Authtoken string
+}
+
+// NewNgrokExposer returns a new instance of a Ngrok Exposer
+func NewNgrokExposer(r *NewNgrokExposerRequest) Exposer {
+	return &NgrokExposer{
+		authtoken: r.Authtoken,
+	}
+}
+
+// NgrokExposer is a struct that implements the Exposer interface by
+// serving the portal through an ngrok tunnel
+type NgrokExposer struct {
+
+	// authtoken is the authtoken used to create the ngrok tunnel
+	authtoken string
+
+	// tunnel is the ngrok tunnel the portal is served on
+	tunnel ngrok.Tunnel
+
+	// cancelSession stops the ngrok session
+	cancelSession context.CancelFunc
+}
+
+func (e *NgrokExposer) Expose(ctx context.Context) (net.Listener, string, error) {
+
+	// the session lives for as long as its context, so it can only be
+	// cancelled if connecting takes too long rather than given a deadline
+	sessionCtx, cancelSession := context.WithCancel(ctx)
+	connectTimer := time.AfterFunc(ngrokConnectTimeout, cancelSession)
+
+	tunnel, err := ngrok.Listen(sessionCtx,
+		nconfig.HTTPEndpoint(),
+		ngrok.WithAuthtoken(e.authtoken),
+	)
+	if !connectTimer.Stop() {
+		if err == nil {
+			tunnel.Close()
+		}
+		cancelSession()
+		return nil, "", fmt.Errorf("unable to connect to ngrok within %s: %v", ngrokConnectTimeout, err)
+	}
+	if err != nil {
+		cancelSession()
+		return nil, "", err
+	}
+
+	e.tunnel = tunnel
+	e.cancelSession = cancelSession
+	return tunnel, tunnel.URL(), nil
+}
+
+func (e *NgrokExposer) Close() error {
+	if e.tunnel == nil {
+		return nil
+	}
+	defer e.cancelSession()
+	return e.tunnel.Close()
+}
+
+func (e *NgrokExposer) Name() string {
+	return "ngrok"
+}
+
+func (e *NgrokExposer) SetsForwardedFor() bool {
+	return true
+}
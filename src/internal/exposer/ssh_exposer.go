@@ -0,0 +1,152 @@
+package exposer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sethvargo/go-githubactions"
+)
+
+// DefaultSshRemotePort is the port forwarded on the remote host when no remote port is provided
+const DefaultSshRemotePort = 8080
+
+// sshReadyPattern matches the (verbose) output ssh prints once the remote port is forwarded
+var sshReadyPattern = regexp.MustCompile(`remote forward success`)
+
+// NewSshExposerRequest is the request object for creating a new
+// instance of a SSH Exposer.
+type NewSshExposerRequest struct {
+
+	// Destination is the host the reverse tunnel is opened to, i.e. "tunnel@example.com". It is
+	// passed to ssh as is, so hosts configured in ~/.ssh/config can be used.
+	Destination string
+
+	// RemotePort is the port forwarded on the remote host. Defaults to DefaultSshRemotePort
+	RemotePort int
+
+	// PublicUrl is the URL the forwarded port can be reached at, i.e. when a reverse
+	// proxy is in front of it. Defaults to http://<destination host>:<remote port>
+	PublicUrl string
+
+	// StartPort is the first local port tried, the following ports are tried when it is occupied
+	StartPort int
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+}
+
+// NewSshExposer returns a new instance of a SSH Exposer
+func NewSshExposer(r *NewSshExposerRequest) Exposer {
+
+	var remotePort int = DefaultSshRemotePort
+	var startPort int = DefaultStartPort
+
+	if r.RemotePort != 0 {
+		remotePort = r.RemotePort
+	}
+
+	publicUrl := r.PublicUrl
+	if publicUrl == "" {
+		publicUrl = fmt.Sprintf("http://%s", net.JoinHostPort(getSshDestinationHost(r.Destination), fmt.Sprint(remotePort)))
+	}
+
+	if r.StartPort != 0 {
+		startPort = r.StartPort
+	}
+
+	return &SshExposer{
+		destination: r.Destination,
+		remotePort:  remotePort,
+		publicUrl:   publicUrl,
+		startPort:   startPort,
+		action:      r.ActionPkg,
+	}
+}
+
+// SshExposer is a struct that implements the Exposer interface by opening an SSH reverse
+// tunnel from a port on a user provided host to the portal
+type SshExposer struct {
+
+	// destination is the host the reverse tunnel is opened to
+	destination string
+
+	// remotePort is the port forwarded on the remote host
+	remotePort int
+
+	// publicUrl is the URL the forwarded port can be reached at
+	publicUrl string
+
+	// startPort is the first local port tried
+	startPort int
+
+	// action represents the githubactions package
+	action *githubactions.Action
+
+	// listener is the local listener the portal is served on
+	listener net.Listener
+
+	// tunnel is the running ssh command
+	tunnel *tunnelCommand
+}
+
+func (e *SshExposer) Expose(ctx context.Context) (net.Listener, string, error) {
+
+	// only the tunnel needs to reach the portal, so it isn't served on the runner's network
+	listener, err := listenOnAvailablePort("127.0.0.1", e.startPort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	args := []string{
+		// verbose output reports when the remote port has been forwarded
+		"-v", "-N", "-T",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=30",
+		"-o", "BatchMode=yes",
+		"-R", fmt.Sprintf("%d:127.0.0.1:%d", e.remotePort, listenerPort(listener)),
+		e.destination,
+	}
+
+	tunnel, _, err := startTunnelCommand(ctx, e.action, "ssh", args, sshReadyPattern)
+	if err != nil {
+		listener.Close()
+		return nil, "", err
+	}
+
+	e.listener = listener
+	e.tunnel = tunnel
+	return listener, e.publicUrl, nil
+}
+
+func (e *SshExposer) Close() error {
+	if e.tunnel != nil {
+		e.tunnel.stop()
+	}
+	if e.listener == nil {
+		return nil
+	}
+	return ignoreClosedListenerErr(e.listener.Close())
+}
+
+func (e *SshExposer) Name() string {
+	return "ssh"
+}
+
+func (e *SshExposer) SetsForwardedFor() bool {
+	return false
+}
+
+// getSshDestinationHost returns the host of the ssh destination, which is either
+// [user@]host or ssh://[user@]host[:port]
+func getSshDestinationHost(destination string) string {
+
+	if destinationUrl, err := url.Parse(destination); err == nil && destinationUrl.Scheme == "ssh" {
+		return destinationUrl.Hostname()
+	}
+
+	return destination[strings.LastIndex(destination, "@")+1:]
+}
@@ -0,0 +1,86 @@
+package exposer_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/exposer"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSshScript records the arguments it's run with and reports the remote port as
+// forwarded, the same way ssh does in verbose mode
+const stubSshScript = `echo "$@" > "$(dirname "$0")/args"
+echo "debug1: remote forward success for: listen 8080, connect 127.0.0.1:8080" >&2
+exec sleep 30
+`
+
+func TestSshExposer_Expose(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		destination string
+		remotePort  int
+		publicUrl   string
+		expectedUrl string
+	}{
+		{
+			name:        "success - public URL defaults to the destination host",
+			destination: "tunnel@example.com",
+			expectedUrl: "http://example.com:8080",
+		},
+		{
+			name:        "success - ssh URL destination with a remote port",
+			destination: "ssh://tunnel@example.com:2222",
+			remotePort:  9000,
+			expectedUrl: "http://example.com:9000",
+		},
+		{
+			name:        "success - provided public URL",
+			destination: "bastion",
+			publicUrl:   "https://portal.example.com",
+			expectedUrl: "https://portal.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			binDir := t.TempDir()
+			writeStubCommand(t, binDir, "ssh", stubSshScript)
+			t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			sshExposer := exposer.NewSshExposer(&exposer.NewSshExposerRequest{
+				Destination: tt.destination,
+				RemotePort:  tt.remotePort,
+				PublicUrl:   tt.publicUrl,
+				StartPort:   18580,
+				ActionPkg:   githubactions.New(githubactions.WithWriter(io.Discard)),
+			})
+			defer sshExposer.Close()
+
+			listener, portalUrl, err := sshExposer.Expose(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedUrl, portalUrl)
+
+			remotePort := tt.remotePort
+			if remotePort == 0 {
+				remotePort = exposer.DefaultSshRemotePort
+			}
+
+			args, err := os.ReadFile(filepath.Join(binDir, "args"))
+			assert.NoError(t, err)
+			assert.Contains(t, string(args), fmt.Sprintf("-R %d:127.0.0.1:%d %s", remotePort, listener.Addr().(*net.TCPAddr).Port, tt.destination))
+			assert.Contains(t, string(args), "ExitOnForwardFailure=yes")
+
+			assert.Equal(t, "ssh", sshExposer.Name())
+			assert.False(t, sshExposer.SetsForwardedFor())
+		})
+	}
+}
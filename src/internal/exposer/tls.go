@@ -0,0 +1,280 @@
+package exposer
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// selfSignedCertificateValidity is how long generated self-signed certificates are valid for
+	selfSignedCertificateValidity = 7 * 24 * time.Hour
+
+	// tlsSniffTimeout is how long a new connection is given to send its first byte, which
+	// decides whether it is a TLS handshake or a plain HTTP request to redirect
+	tlsSniffTimeout = 10 * time.Second
+
+	// tlsHandshakeRecordType is the first byte of a TLS handshake
+	tlsHandshakeRecordType = 0x16
+)
+
+// TlsOptions holds how the portal is served over TLS
+type TlsOptions struct {
+
+	// Certificate is the certificate the portal is served with, either provided or self-signed
+	Certificate *tls.Certificate
+
+	// RedirectHttp is true when plain HTTP requests to the portal's port are redirected to HTTPS
+	RedirectHttp bool
+}
+
+// LoadCertificate loads a PEM encoded certificate and private key, each of
+// which can be provided as its content or the path to a file holding it
+func LoadCertificate(cert, key string) (*tls.Certificate, error) {
+
+	certPem, err := readPem(cert)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the TLS certificate: %v", err)
+	}
+
+	keyPem, err := readPem(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the TLS key: %v", err)
+	}
+
+	certificate, err := tls.X509KeyPair(certPem, keyPem)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS certificate or key: %v", err)
+	}
+
+	return &certificate, nil
+}
+
+// readPem returns the given PEM content, or the content of the file at the given path
+func readPem(pemOrPath string) ([]byte, error) {
+	if trimmed := strings.TrimSpace(pemOrPath); strings.HasPrefix(trimmed, "-----BEGIN") {
+		return []byte(trimmed), nil
+	}
+	return os.ReadFile(pemOrPath)
+}
+
+// NewSelfSignedCertificate generates a self-signed certificate for the given hosts, which
+// can be IP addresses or DNS names
+func NewSelfSignedCertificate(hosts []string) (*tls.Certificate, error) {
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{"Interactive Inputs"}, CommonName: hosts[0]},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(selfSignedCertificateValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{certDer},
+		PrivateKey:  privateKey,
+	}, nil
+}
+
+// CertificateFingerprint returns the SHA-256 fingerprint of the certificate, formatted
+// the way browsers display it (i.e. "AB:CD:...")
+func CertificateFingerprint(certificate *tls.Certificate) string {
+	if len(certificate.Certificate) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256(certificate.Certificate[0])
+	hexPairs := make([]string, len(sum))
+	for i, b := range sum {
+		hexPairs[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(hexPairs, ":")
+}
+
+// newTlsListener wraps the listener to serve its connections over TLS, optionally redirecting
+// plain HTTP requests to HTTPS
+func newTlsListener(listener net.Listener, certificate *tls.Certificate, redirectHttp bool) net.Listener {
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{*certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if !redirectHttp {
+		return tls.NewListener(listener, tlsConfig)
+	}
+
+	sniffingListener := &tlsSniffingListener{
+		Listener:  listener,
+		tlsConfig: tlsConfig,
+		tlsConns:  make(chan net.Conn),
+		httpConns: make(chan net.Conn),
+		closed:    make(chan struct{}),
+	}
+
+	go sniffingListener.acceptConns()
+	go http.Serve(&chanListener{listener: sniffingListener, conns: sniffingListener.httpConns}, http.HandlerFunc(redirectToHttps))
+
+	return sniffingListener
+}
+
+// redirectToHttps redirects the request to the same URL over HTTPS
+func redirectToHttps(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, fmt.Sprintf("https://%s%s", r.Host, r.URL.RequestURI()), http.StatusMovedPermanently)
+}
+
+// tlsSniffingListener is a listener that serves TLS connections, passing plain
+// HTTP connections to be redirected on to httpConns
+type tlsSniffingListener struct {
+	net.Listener
+
+	// tlsConfig is the configuration TLS connections are served with
+	tlsConfig *tls.Config
+
+	// tlsConns are the accepted TLS connections
+	tlsConns chan net.Conn
+
+	// httpConns are the accepted plain HTTP connections
+	httpConns chan net.Conn
+
+	// closed is closed once the listener is closed
+	closed chan struct{}
+
+	// closeOnce makes sure closed is only closed once
+	closeOnce sync.Once
+}
+
+// acceptConns accepts connections until the listener is closed, sniffing each in the background
+func (l *tlsSniffingListener) acceptConns() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			l.Close()
+			return
+		}
+
+		go l.sniffConn(conn)
+	}
+}
+
+// sniffConn passes the connection on depending on whether it starts with a TLS handshake
+func (l *tlsSniffingListener) sniffConn(conn net.Conn) {
+
+	conn.SetReadDeadline(time.Now().Add(tlsSniffTimeout))
+	reader := bufio.NewReader(conn)
+	firstByte, err := reader.Peek(1)
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	var sniffedConn net.Conn = &peekedConn{Conn: conn, reader: reader}
+	var conns chan net.Conn = l.httpConns
+	if firstByte[0] == tlsHandshakeRecordType {
+		sniffedConn = tls.Server(sniffedConn, l.tlsConfig)
+		conns = l.tlsConns
+	}
+
+	select {
+	case conns <- sniffedConn:
+	case <-l.closed:
+		conn.Close()
+	}
+}
+
+func (l *tlsSniffingListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.tlsConns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *tlsSniffingListener) Close() error {
+	var err error = net.ErrClosed
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		err = l.Listener.Close()
+	})
+	return err
+}
+
+// chanListener is a listener that accepts the connections sent on conns
+type chanListener struct {
+
+	// listener is the listener the connections were accepted by
+	listener *tlsSniffingListener
+
+	// conns are the connections to accept
+	conns chan net.Conn
+}
+
+func (l *chanListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.listener.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *chanListener) Close() error {
+	return nil
+}
+
+func (l *chanListener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// peekedConn is a connection whose first bytes have been peeked at
+type peekedConn struct {
+	net.Conn
+
+	// reader holds the peeked bytes, followed by the rest of the connection
+	reader *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -0,0 +1,218 @@
+package exposer_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/exposer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCertificatePem returns a self-signed certificate for localhost and its key, PEM encoded
+func newTestCertificatePem(t *testing.T) (string, string) {
+	t.Helper()
+
+	certificate, err := exposer.NewSelfSignedCertificate([]string{"localhost"})
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(certificate.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Certificate[0]})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	return string(certPem), string(keyPem)
+}
+
+func TestLoadCertificate(t *testing.T) {
+
+	certPem, keyPem := newTestCertificatePem(t)
+	otherCertPem, _ := newTestCertificatePem(t)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certPath, []byte(certPem), 0600))
+	require.NoError(t, os.WriteFile(keyPath, []byte(keyPem), 0600))
+
+	tests := []struct {
+		name          string
+		cert          string
+		key           string
+		expectedError string
+	}{
+		{
+			name: "success - PEM content",
+			cert: certPem,
+			key:  keyPem,
+		},
+		{
+			name: "success - PEM content with leading whitespace",
+			cert: "\n  " + certPem,
+			key:  keyPem,
+		},
+		{
+			name: "success - PEM file paths",
+			cert: certPath,
+			key:  keyPath,
+		},
+		{
+			name: "success - PEM content and file path",
+			cert: certPem,
+			key:  keyPath,
+		},
+		{
+			name:          "failure - certificate file missing",
+			cert:          filepath.Join(dir, "missing.pem"),
+			key:           keyPem,
+			expectedError: "unable to read the TLS certificate",
+		},
+		{
+			name:          "failure - key file missing",
+			cert:          certPem,
+			key:           filepath.Join(dir, "missing.pem"),
+			expectedError: "unable to read the TLS key",
+		},
+		{
+			name:          "failure - key doesn't match the certificate",
+			cert:          otherCertPem,
+			key:           keyPem,
+			expectedError: "invalid TLS certificate or key",
+		},
+		{
+			name:          "failure - not PEM encoded",
+			cert:          keyPath,
+			key:           certPath,
+			expectedError: "invalid TLS certificate or key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			certificate, err := exposer.LoadCertificate(tt.cert, tt.key)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				assert.Nil(t, certificate)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, certificate.Certificate)
+		})
+	}
+}
+
+func TestNewSelfSignedCertificate(t *testing.T) {
+
+	certificate, err := exposer.NewSelfSignedCertificate([]string{"10.1.2.3", "portal.example.com"})
+	require.NoError(t, err)
+
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.1.2.3", parsed.Subject.CommonName)
+	assert.Equal(t, []string{"portal.example.com"}, parsed.DNSNames)
+	if assert.Len(t, parsed.IPAddresses, 1) {
+		assert.Equal(t, "10.1.2.3", parsed.IPAddresses[0].String())
+	}
+	assert.NoError(t, parsed.VerifyHostname("portal.example.com"))
+	assert.NoError(t, parsed.VerifyHostname("10.1.2.3"))
+}
+
+func TestCertificateFingerprint(t *testing.T) {
+
+	certificate, err := exposer.NewSelfSignedCertificate([]string{"localhost"})
+	require.NoError(t, err)
+
+	fingerprint := exposer.CertificateFingerprint(certificate)
+	assert.Regexp(t, regexp.MustCompile(`^([0-9A-F]{2}:){31}[0-9A-F]{2}$`), fingerprint)
+	assert.Equal(t, fingerprint, exposer.CertificateFingerprint(certificate))
+
+	assert.Empty(t, exposer.CertificateFingerprint(&tls.Certificate{}))
+}
+
+func TestBindExposer_ExposeTls(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		redirectHttp bool
+	}{
+		{
+			name: "success - TLS only",
+		},
+		{
+			name:         "success - plain HTTP redirected",
+			redirectHttp: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			certificate, err := exposer.NewSelfSignedCertificate([]string{"localhost"})
+			require.NoError(t, err)
+
+			bindExposer := exposer.NewBindExposer(&exposer.NewBindExposerRequest{
+				StartPort: 18480,
+				Tls:       &exposer.TlsOptions{Certificate: certificate, RedirectHttp: tt.redirectHttp},
+			})
+			defer bindExposer.Close()
+
+			listener, portalUrl, err := bindExposer.Expose(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("https://localhost:%d", listener.Addr().(*net.TCPAddr).Port), portalUrl)
+
+			server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "portal")
+			})}
+			go server.Serve(listener)
+			defer server.Close()
+
+			// the portal is served over HTTPS with the certificate
+			parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+			require.NoError(t, err)
+			rootCAs := x509.NewCertPool()
+			rootCAs.AddCert(parsed)
+
+			httpsClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}}
+			response, err := httpsClient.Get(portalUrl + "/path?query=1")
+			require.NoError(t, err)
+			body, _ := io.ReadAll(response.Body)
+			response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, "portal", string(body))
+
+			// plain HTTP requests are redirected to HTTPS when enabled, and fail otherwise
+			httpUrl := "http" + portalUrl[len("https"):]
+			httpClient := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			}}
+			response, err = httpClient.Get(httpUrl + "/path?query=1")
+			if !tt.redirectHttp {
+				if err == nil {
+					response.Body.Close()
+					assert.NotEqual(t, http.StatusOK, response.StatusCode)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			response.Body.Close()
+			assert.Equal(t, http.StatusMovedPermanently, response.StatusCode)
+			assert.Equal(t, portalUrl+"/path?query=1", response.Header.Get("Location"))
+		})
+	}
+}
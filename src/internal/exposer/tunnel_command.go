@@ -0,0 +1,114 @@
+package exposer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/sethvargo/go-githubactions"
+)
+
+// tunnelCommandReadyTimeout is how long a tunnel command can take to report it is ready
+const tunnelCommandReadyTimeout = time.Minute
+
+// tunnelCommand is a running command that tunnels to the portal, i.e. cloudflared or ssh
+type tunnelCommand struct {
+
+	// cmd is the running command
+	cmd *exec.Cmd
+
+	// done is closed once the command has exited
+	done chan struct{}
+
+	// err is the error the command exited with, it is set before done is closed
+	err error
+}
+
+// startTunnelCommand runs the command and waits until a line of its output matches the ready
+// pattern, returning the running command along with the first capture group of the match
+func startTunnelCommand(ctx context.Context, action *githubactions.Action, name string, args []string, readyPattern *regexp.Regexp) (*tunnelCommand, string, error) {
+
+	outputReader, outputWriter := io.Pipe()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = outputWriter
+	cmd.Stderr = outputWriter
+
+	err := cmd.Start()
+	if err != nil {
+		return nil, "", err
+	}
+
+	tunnel := &tunnelCommand{cmd: cmd, done: make(chan struct{})}
+	ready := make(chan string, 1)
+
+	go func() {
+		var isReady bool
+
+		lineScanner := bufio.NewScanner(outputReader)
+		for lineScanner.Scan() {
+			line := lineScanner.Text()
+			if action != nil {
+				action.Debugf("[%s] %s", filepath.Base(name), line)
+			}
+
+			if isReady {
+				continue
+			}
+
+			match := readyPattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+
+			isReady = true
+			if len(match) > 1 {
+				ready <- match[1]
+			} else {
+				ready <- match[0]
+			}
+		}
+
+		// keep draining the output (i.e. after an overly long line), so the command never blocks writing it
+		io.Copy(io.Discard, outputReader)
+	}()
+
+	go func() {
+		tunnel.err = cmd.Wait()
+		outputWriter.Close()
+		close(tunnel.done)
+	}()
+
+	select {
+	case match := <-ready:
+		return tunnel, match, nil
+
+	case <-tunnel.done:
+		return nil, "", fmt.Errorf("%s exited before it was ready: %v", filepath.Base(name), tunnel.err)
+
+	case <-time.After(tunnelCommandReadyTimeout):
+		tunnel.stop()
+		return nil, "", fmt.Errorf("%s wasn't ready within %s", filepath.Base(name), tunnelCommandReadyTimeout)
+
+	case <-ctx.Done():
+		tunnel.stop()
+		return nil, "", ctx.Err()
+	}
+}
+
+// stop stops the command, waiting for it to exit
+func (t *tunnelCommand) stop() {
+	select {
+	case <-t.done:
+		return
+	default:
+	}
+
+	t.cmd.Process.Kill()
+	<-t.done
+}
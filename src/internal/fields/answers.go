@@ -0,0 +1,193 @@
+package fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+)
+
+// LoadAnswersFromFile reads the raw JSON answers keyed by field label from the file,
+// i.e. the answers saved from a previous run
+func LoadAnswersFromFile(filePath string) (map[string]json.RawMessage, error) {
+
+	answersBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAnswers(answersBytes)
+}
+
+// ParseAnswers parses answers held in a JSON object keyed by field labels
+func ParseAnswers(answersBytes []byte) (map[string]json.RawMessage, error) {
+	var answers map[string]json.RawMessage
+
+	err := json.Unmarshal(answersBytes, &answers)
+	if err != nil {
+		return nil, err
+	}
+
+	return answers, nil
+}
+
+// ResolveAnswers validates the provided answers against the fields, returning the values to output
+// keyed by field label and the reason each rejected answer was rejected
+func (f *Fields) ResolveAnswers(answers map[string]json.RawMessage) (url.Values, map[string]string) {
+	var values url.Values = make(url.Values)
+	var answerErrors map[string]string = make(map[string]string)
+
+	for label := range answers {
+		if !f.hasField(label) {
+			answerErrors[label] = "no field is defined with this label"
+		}
+	}
+
+	for i := range f.Fields {
+		field := &f.Fields[i]
+
+		providedValues, err := field.answerOrDefault(answers)
+		if err != nil {
+			answerErrors[field.Label] = err.Error()
+			continue
+		}
+
+		normalisedValues, err := field.NormaliseValues(providedValues)
+		if fieldValidationError, ok := err.(*FieldValidationError); ok {
+			answerErrors[field.Label] = fieldValidationError.Message
+			continue
+		}
+		if err != nil {
+			answerErrors[field.Label] = err.Error()
+			continue
+		}
+
+		values[field.Label] = normalisedValues
+	}
+
+	return values, answerErrors
+}
+
+// answerOrDefault returns the decoded answer provided for the field, or its default
+// value when the field is read-only or no answer was provided
+func (f *Field) answerOrDefault(answers map[string]json.RawMessage) ([]string, error) {
+
+	answer, ok := answers[f.Label]
+	if ok && !f.Properties.ReadOnly {
+		return f.DecodeAnswer(answer)
+	}
+
+	if f.Properties.DefaultValue == "" {
+		return []string{}, nil
+	}
+
+	if f.Properties.Type != "multiselect" {
+		return []string{f.Properties.DefaultValue}, nil
+	}
+
+	var defaultChoices []string
+	for _, defaultChoice := range strings.Split(f.Properties.DefaultValue, ",") {
+		defaultChoices = append(defaultChoices, strings.TrimSpace(defaultChoice))
+	}
+	return defaultChoices, nil
+}
+
+// hasField returns whether a field is defined with the given label
+func (f *Fields) hasField(label string) bool {
+	return f.fieldWithLabel(label) != nil
+}
+
+// fieldWithLabel returns the field defined with the given label, or nil when there isn't one
+func (f *Fields) fieldWithLabel(label string) *Field {
+	for i := range f.Fields {
+		if f.Fields[i].Label == label {
+			return &f.Fields[i]
+		}
+	}
+	return nil
+}
+
+// DecodeAnswer converts the raw JSON answer provided for the field into its submitted value(s)
+func (f *Field) DecodeAnswer(raw json.RawMessage) ([]string, error) {
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, fmt.Errorf("answer for '%s' is not valid JSON: %v", f.Label, err)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return []string{}, nil
+
+	case string:
+		return []string{v}, nil
+
+	case bool:
+		return []string{strconv.FormatBool(v)}, nil
+
+	case json.Number:
+		return []string{v.String()}, nil
+
+	case []interface{}:
+		if f.IsGroup() || f.IsStructured() {
+			break
+		}
+		if f.Properties.Type != "multiselect" && f.Properties.Type != "multifile" {
+			return nil, fmt.Errorf("answer for '%s' must be a single value", f.Label)
+		}
+
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			itemString, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("answer for '%s' must be a list of strings", f.Label)
+			}
+			values = append(values, itemString)
+		}
+		return values, nil
+	}
+
+	if !f.IsGroup() && !f.IsStructured() {
+		return nil, fmt.Errorf("answer for '%s' must be a single value", f.Label)
+	}
+
+	var compacted bytes.Buffer
+	err = json.Compact(&compacted, raw)
+	if err != nil {
+		return nil, fmt.Errorf("answer for '%s' is not valid JSON: %v", f.Label, err)
+	}
+
+	return []string{compacted.String()}, nil
+}
+
+// IsDefaultChoice returns whether the given choice is selected by the field's default value
+// or, for multiselect fields, the choices it was prefilled with
+func (f *Field) IsDefaultChoice(choice string) bool {
+	if f.Properties.Type == "multiselect" && f.Properties.PrefilledChoices != nil {
+		return toolbox.StringInSlice(choice, f.Properties.PrefilledChoices)
+	}
+
+	if f.Properties.DefaultValue == "" {
+		return false
+	}
+
+	if f.Properties.Type != "multiselect" {
+		return f.Properties.DefaultValue == choice
+	}
+
+	for _, defaultChoice := range strings.Split(f.Properties.DefaultValue, ",") {
+		if strings.TrimSpace(defaultChoice) == choice {
+			return true
+		}
+	}
+	return false
+}
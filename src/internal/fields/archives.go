@@ -0,0 +1,78 @@
+package fields
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/boasihq/interactive-inputs/internal/archive"
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+// ArchiveExtractor extracts the archives provided for a file/multifile field, enforcing the
+// field's extraction limits across all of its archives
+type ArchiveExtractor struct {
+
+	// enabled is true when the field extracts archives
+	enabled bool
+
+	// limits the limits left for the field's archives
+	limits archive.Limits
+}
+
+// NewArchiveExtractor returns the archive extractor of the file/multifile field
+func (f *Field) NewArchiveExtractor() (*ArchiveExtractor, error) {
+
+	limits, err := f.Properties.GetExtractionLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArchiveExtractor{
+		enabled: f.Properties.ExtractArchives,
+		limits:  limits,
+	}, nil
+}
+
+// Extracts returns whether the file with the given name is extracted, which is when
+// it is an archive and the field extracts archives
+func (e *ArchiveExtractor) Extracts(fileName string) bool {
+	return e.enabled && archive.IsArchive(fileName)
+}
+
+// Extract extracts the file, with the given name and stored at the given path, into the destination
+// directory if it is extracted. It returns nil when the file isn't extracted.
+func (e *ArchiveExtractor) Extract(fileName, filePath, destinationDir string) (*archive.Result, error) {
+
+	if !e.Extracts(fileName) {
+		return nil, nil
+	}
+
+	// the previous archives may have used up the limits
+	if e.limits.MaxSize <= 0 {
+		return nil, fmt.Errorf("%w: no extraction size left for %s", errors.ErrArchiveTooLarge, fileName)
+	}
+	if e.limits.MaxEntries <= 0 {
+		return nil, fmt.Errorf("%w: no extraction entries left for %s", errors.ErrArchiveHasTooManyEntries, fileName)
+	}
+
+	result, err := archive.Extract(fileName, filePath, destinationDir, e.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	e.limits.MaxSize -= result.Size
+	e.limits.MaxEntries -= result.Entries
+
+	return result, nil
+}
+
+// RecordExtraction records on the manifest entry the directory the file was extracted into and the
+// extracted files, with their paths made relative to the cache directory the directory is in
+func (e *FileManifestEntry) RecordExtraction(dirName string, result *archive.Result) {
+	e.ExtractedTo = dirName
+	e.ExtractedFiles = []string{}
+
+	for _, file := range result.Files {
+		e.ExtractedFiles = append(e.ExtractedFiles, path.Join(dirName, file))
+	}
+}
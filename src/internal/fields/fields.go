@@ -23,6 +23,20 @@ var (
 		"multiselect",
 		"file",
 		"multifile",
+		"group",
+		"table",
+		"json",
+		"yaml",
+	}
+
+	// ValidGroupSubFieldTypes is a list of field types that can be used as the sub-fields
+	// of a group (table) field.
+	ValidGroupSubFieldTypes = []string{
+		"text",
+		"textarea",
+		"number",
+		"boolean",
+		"select",
 	}
 )
 
@@ -48,6 +62,17 @@ type Field struct {
 // Required indicates whether the field must be filled out.
 // MaxLength is the maximum length of the field's value.
 // DisableAutoCopySelection is whether the field should stop automatically coping the selected option to the clipboard (valid fields: select, multiselect).
+// SubFields is the list of fields that make up a single row of a group (table) field.
+// MinRows and MaxRows are the minimum and maximum number of rows a group (table) field accepts (0 means no limit).
+// Schema is an inline JSON Schema the value of a json/yaml field is validated against.
+// SchemaFilePath is the path to a file containing the JSON Schema (relative paths are resolved against the workspace).
+// OutputFormat is the format the value of a json/yaml field is output in (json or yaml), defaulting to the field's type.
+// MaxFileSize and MaxTotalSize are the largest size of a single file and of all files uploaded to a file/multifile field, i.e. "100MB" (empty means no limit).
+// MaxFiles is the maximum number of files that can be uploaded to a multifile field (0 means no limit).
+// ExtractArchives extracts zip, tar, tar.gz and tar.zst files provided for a file/multifile field into its cache directory.
+// MaxExtractedSize and MaxExtractedFiles limit the combined size and number of entries (skipped ones included) read from a field's archives (defaults: 1GB and 10000).
+// Secret is whether the field's value is redacted from the audit log and masked in the logs.
+// PrefilledChoices are the choices a multiselect field was prefilled with, which are preselected instead of those in DefaultValue (set by the portal, not configurable).
 type FieldProperties struct {
 	Display                  string   `yaml:"display"`
 	Type                     string   `yaml:"type"`
@@ -63,6 +88,20 @@ type FieldProperties struct {
 	ReadOnly                 bool     `yaml:"readOnly"`
 	DisableAutoCopySelection bool     `yaml:"disableAutoCopySelection"`
 	AcceptedFileTypes        []string `yaml:"acceptedFileTypes"`
+	SubFields                []Field  `yaml:"fields"`
+	MinRows                  int      `yaml:"minRows"`
+	MaxRows                  int      `yaml:"maxRows"`
+	Schema                   string   `yaml:"schema"`
+	SchemaFilePath           string   `yaml:"schemaFilePath"`
+	OutputFormat             string   `yaml:"outputFormat"`
+	MaxFileSize              string   `yaml:"maxFileSize"`
+	MaxTotalSize             string   `yaml:"maxTotalSize"`
+	MaxFiles                 int      `yaml:"maxFiles"`
+	ExtractArchives          bool     `yaml:"extractArchives"`
+	MaxExtractedSize         string   `yaml:"maxExtractedSize"`
+	MaxExtractedFiles        int      `yaml:"maxExtractedFiles"`
+	Secret                   bool     `yaml:"secret"`
+	PrefilledChoices         []string `yaml:"-"`
 }
 
 // MarshalStringIntoValidFieldsStruct takes a YAML-formatted string representation of a Fields
@@ -103,10 +142,8 @@ func MarshalStringIntoValidFieldsStruct(fieldsString string, action *githubactio
 		}
 
 		// make sure label is camel case
-		labelKebabCase, err := toolbox.StringConvertToKebabCase(
-			toolbox.StringRemoveSpecialCharactersWith(field.Label, ""),
-		)
-		if err != nil || labelKebabCase == "" {
+		labelKebabCase, err := normaliseFieldLabel(field.Label)
+		if err != nil {
 			action.Errorf("Invalid label provided - '%s' is not kebab case compatible", field.Label)
 			return nil, errors.ErrInvalidLabelProvided
 		}
@@ -123,11 +160,110 @@ func MarshalStringIntoValidFieldsStruct(fieldsString string, action *githubactio
 
 		// add the field label to the detected field labels
 		detectedFieldLabels = append(detectedFieldLabels, field.Label)
+
+		// make sure group (table) fields have a valid row definition
+		if fields.Fields[i].IsGroup() {
+			err = validateGroupFieldDefinition(&fields.Fields[i], action)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// make sure file and multifile fields have valid upload limits
+		if fields.Fields[i].IsFile() {
+			err = validateFileFieldDefinition(&fields.Fields[i])
+			if err != nil {
+				action.Errorf("Invalid upload limits provided for field '%s': %v", fields.Fields[i].Label, err)
+				return nil, errors.ErrInvalidUploadLimitsProvided
+			}
+		}
+
+		// make sure json/yaml fields have a valid schema and output format
+		if fields.Fields[i].IsStructured() {
+			fields.Fields[i].Properties.OutputFormat = toolbox.StringStandardisedToLower(field.Properties.OutputFormat)
+
+			err = validateStructuredFieldDefinition(&fields.Fields[i])
+			if err != nil {
+				action.Errorf("Invalid schema or output format provided for field '%s': %v", fields.Fields[i].Label, err)
+				return nil, err
+			}
+		}
 	}
 
 	return &fields, nil
 }
 
+// IsGroup returns whether the field is a group (table) field, which holds a
+// variable number of rows made up of its sub-fields.
+func (f *Field) IsGroup() bool {
+	return f.Properties.Type == "group" || f.Properties.Type == "table"
+}
+
+// normaliseFieldLabel strips special characters from the label and converts it to kebab case.
+// It returns an error if the label is not kebab case compatible.
+func normaliseFieldLabel(label string) (string, error) {
+	labelKebabCase, err := toolbox.StringConvertToKebabCase(
+		toolbox.StringRemoveSpecialCharactersWith(label, ""),
+	)
+	if err != nil || labelKebabCase == "" {
+		return "", errors.ErrInvalidLabelProvided
+	}
+
+	return labelKebabCase, nil
+}
+
+// validateGroupFieldDefinition makes sure the sub-fields and row limits of the given group (table)
+// field are valid, normalising the sub-field labels and types along the way.
+func validateGroupFieldDefinition(field *Field, action *githubactions.Action) error {
+	var detectedSubFieldLabels []string = make([]string, 0)
+
+	if len(field.Properties.SubFields) == 0 {
+		action.Errorf("No sub-fields provided for group field '%s'", field.Label)
+		return errors.ErrNoGroupSubFieldsProvided
+	}
+
+	if field.Properties.MinRows < 0 || field.Properties.MaxRows < 0 ||
+		(field.Properties.MaxRows > 0 && field.Properties.MinRows > field.Properties.MaxRows) {
+		action.Errorf(
+			"Invalid row limits provided for group field '%s' (minRows: %d, maxRows: %d)",
+			field.Label,
+			field.Properties.MinRows,
+			field.Properties.MaxRows,
+		)
+		return errors.ErrInvalidGroupRowLimitsProvided
+	}
+
+	for i, subField := range field.Properties.SubFields {
+		subFieldType := toolbox.StringStandardisedToLower(subField.Properties.Type)
+		if !toolbox.StringInSlice(subFieldType, ValidGroupSubFieldTypes) {
+			action.Errorf(
+				"Invalid sub-field type '%s' provided for sub-field '%s' of group field '%s'. Valid sub-field types are: %s",
+				subField.Properties.Type,
+				subField.Label,
+				field.Label,
+				strings.Join(ValidGroupSubFieldTypes, ", "),
+			)
+			return errors.ErrInvalidGroupSubFieldTypeProvided
+		}
+		field.Properties.SubFields[i].Properties.Type = subFieldType
+
+		subFieldLabel, err := normaliseFieldLabel(subField.Label)
+		if err != nil {
+			action.Errorf("Invalid label provided for sub-field of group field '%s' - '%s' is not kebab case compatible", field.Label, subField.Label)
+			return errors.ErrInvalidLabelProvided
+		}
+		field.Properties.SubFields[i].Label = subFieldLabel
+
+		if toolbox.StringInSlice(subFieldLabel, detectedSubFieldLabels) {
+			action.Errorf("Duplicate sub-field label detected in group field '%s': '%s'", field.Label, subFieldLabel)
+			return errors.ErrDuplicateFieldLabelDetected
+		}
+		detectedSubFieldLabels = append(detectedSubFieldLabels, subFieldLabel)
+	}
+
+	return nil
+}
+
 // LoadChoicesFromFile reads choices from a text file where each line represents one choice.
 // It returns a slice of strings containing the choices, with empty lines and whitespace-only lines filtered out.
 func LoadChoicesFromFile(filePath string) ([]string, error) {
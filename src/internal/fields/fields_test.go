@@ -1,9 +1,17 @@
 package fields_test
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/boasihq/interactive-inputs/internal/errors"
 	"github.com/boasihq/interactive-inputs/internal/fields"
 	"github.com/sethvargo/go-githubactions"
 	"github.com/stretchr/testify/assert"
@@ -80,6 +88,94 @@ func TestMarshalStringIntoValidFieldsStruct(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:          "success - group field with sub-fields parsed",
+			fieldsString:  "fields:\n  - label: services\n    properties:\n      type: TABLE\n      minRows: 1\n      maxRows: 3\n      fields:\n        - label: Service Name\n          properties:\n            type: text\n            required: true\n        - label: replicas\n          properties:\n            type: Number\n",
+			expectedError: false,
+			expectedField: &fields.Fields{
+				Fields: []fields.Field{
+					{
+						Label: "services",
+						Properties: fields.FieldProperties{
+							Type:    "table",
+							MinRows: 1,
+							MaxRows: 3,
+							SubFields: []fields.Field{
+								{
+									Label: "service-name",
+									Properties: fields.FieldProperties{
+										Type:     "text",
+										Required: true,
+									},
+								},
+								{
+									Label: "replicas",
+									Properties: fields.FieldProperties{
+										Type: "number",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: "",
+		},
+		{
+			name:           "failed - group field without sub-fields",
+			fieldsString:   "fields:\n  - label: services\n    properties:\n      type: group\n",
+			expectedError:  true,
+			expectedOutput: "::error::No sub-fields provided for group field 'services'\n",
+		},
+		{
+			name:           "failed - group field with unsupported sub-field type",
+			fieldsString:   "fields:\n  - label: services\n    properties:\n      type: group\n      fields:\n        - label: artifact\n          properties:\n            type: file\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid sub-field type 'file' provided for sub-field 'artifact' of group field 'services'. Valid sub-field types are: text, textarea, number, boolean, select\n",
+		},
+		{
+			name:           "failed - group field with contradicting row limits",
+			fieldsString:   "fields:\n  - label: services\n    properties:\n      type: group\n      minRows: 3\n      maxRows: 2\n      fields:\n        - label: name\n          properties:\n            type: text\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid row limits provided for group field 'services' (minRows: 3, maxRows: 2)\n",
+		},
+		{
+			name:          "success - multifile field with upload limits",
+			fieldsString:  "fields:\n  - label: docs\n    properties:\n      type: multifile\n      maxFileSize: 100MB\n      maxTotalSize: 1.5 GiB\n      maxFiles: 5\n",
+			expectedError: false,
+			expectedField: &fields.Fields{
+				Fields: []fields.Field{
+					{
+						Label: "docs",
+						Properties: fields.FieldProperties{
+							Type:         "multifile",
+							MaxFileSize:  "100MB",
+							MaxTotalSize: "1.5 GiB",
+							MaxFiles:     5,
+						},
+					},
+				},
+			},
+			expectedOutput: "",
+		},
+		{
+			name:           "failed - file field with malformed size limit",
+			fieldsString:   "fields:\n  - label: docs\n    properties:\n      type: file\n      maxFileSize: lots\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid upload limits provided for field 'docs': maxFileSize: invalid size 'lots', expected a number optionally followed by B, KB, MB, GB or TB\n",
+		},
+		{
+			name:           "failed - file field with malformed extraction size limit",
+			fieldsString:   "fields:\n  - label: bundle\n    properties:\n      type: file\n      extractArchives: true\n      maxExtractedSize: huge\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid upload limits provided for field 'bundle': maxExtractedSize: invalid size 'huge', expected a number optionally followed by B, KB, MB, GB or TB\n",
+		},
+		{
+			name:           "failed - file field with negative extraction entry limit",
+			fieldsString:   "fields:\n  - label: bundle\n    properties:\n      type: file\n      extractArchives: true\n      maxExtractedFiles: -1\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid upload limits provided for field 'bundle': maxExtractedFiles must not be negative, got -1\n",
+		},
 		{
 			name:          "Empty string",
 			fieldsString:  "",
@@ -129,3 +225,938 @@ func TestMarshalStringIntoValidFieldsStruct(t *testing.T) {
 		})
 	}
 }
+
+func TestField_NormaliseGroupValue(t *testing.T) {
+
+	groupField := fields.Field{
+		Label: "services",
+		Properties: fields.FieldProperties{
+			Type:    "group",
+			MinRows: 1,
+			MaxRows: 2,
+			SubFields: []fields.Field{
+				{Label: "name", Properties: fields.FieldProperties{Type: "text", Required: true, MaxLength: 10}},
+				{Label: "replicas", Properties: fields.FieldProperties{Type: "number", NumberMin: 1, NumberMax: 5}},
+				{Label: "canary", Properties: fields.FieldProperties{Type: "boolean"}},
+				{Label: "tier", Properties: fields.FieldProperties{Type: "select", Choices: []string{"web", "worker"}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		raw           string
+		expectedValue string
+		expectedError string
+	}{
+		{
+			name:          "success - rows normalised with typed values",
+			raw:           `[{"name":" api ","replicas":"3","canary":true,"tier":"web"},{"name":"jobs","replicas":2}]`,
+			expectedValue: `[{"canary":true,"name":"api","replicas":3,"tier":"web"},{"canary":null,"name":"jobs","replicas":2,"tier":null}]`,
+		},
+		{
+			name:          "success - empty cells are null whatever their type",
+			raw:           `[{"name":"api","replicas":"","canary":null,"tier":" "}]`,
+			expectedValue: `[{"canary":null,"name":"api","replicas":null,"tier":null}]`,
+		},
+		{
+			name:          "failed - fewer rows than minimum",
+			raw:           `[]`,
+			expectedError: "services: at least 1 row(s) are required, got 0",
+		},
+		{
+			name:          "failed - more rows than maximum",
+			raw:           `[{"name":"a"},{"name":"b"},{"name":"c"}]`,
+			expectedError: "services: at most 2 row(s) are allowed, got 3",
+		},
+		{
+			name:          "failed - malformed rows",
+			raw:           `{"name":"a"}`,
+			expectedError: "services: rows must be a JSON array of objects",
+		},
+		{
+			name:          "failed - unknown column",
+			raw:           `[{"name":"a","region":"eu"}]`,
+			expectedError: "services: row 1: unknown column 'region'",
+		},
+		{
+			name:          "failed - required sub-field missing",
+			raw:           `[{"name":"a"},{"replicas":"1"}]`,
+			expectedError: "services: row 2: name is required",
+		},
+		{
+			name:          "failed - number out of range",
+			raw:           `[{"name":"a","replicas":"9"}]`,
+			expectedError: "services: row 1: replicas must be at most 5",
+		},
+		{
+			name:          "failed - choice not allowed",
+			raw:           `[{"name":"a","tier":"db"}]`,
+			expectedError: "services: row 1: tier must be one of: web, worker",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			value, err := groupField.NormaliseGroupValue(tt.raw)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				assert.ErrorIs(t, err, errors.ErrInvalidFieldValueProvided)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedValue, value)
+		})
+	}
+}
+
+func TestField_NormaliseStructuredValue(t *testing.T) {
+
+	schema := `{"type":"object","required":["flag"],"properties":{"flag":{"type":"string"},"percentage":{"type":"integer","maximum":100}}}`
+
+	tests := []struct {
+		name          string
+		field         fields.Field
+		raw           string
+		expectedValue string
+		expectedError string
+		expectedLine  int
+	}{
+		{
+			name:          "success - json minified",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "json", Schema: schema}},
+			raw:           "{\n  \"flag\": \"dark-mode\",\n  \"percentage\": 25\n}",
+			expectedValue: `{"flag":"dark-mode","percentage":25}`,
+		},
+		{
+			name:          "success - yaml output as json",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "yaml", OutputFormat: "json"}},
+			raw:           "flag: dark-mode\nregions: [eu, us]\nratio: 0.50\n",
+			expectedValue: `{"flag":"dark-mode","ratio":0.5,"regions":["eu","us"]}`,
+		},
+		{
+			name:          "success - yaml normalised",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "yaml"}},
+			raw:           "flag:    dark-mode\nregions:\n    - eu\n",
+			expectedValue: "flag: dark-mode\nregions:\n    - eu",
+		},
+		{
+			name:          "success - yaml numbers kept unquoted",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "yaml", Schema: schema}},
+			raw:           "flag: dark-mode\npercentage: 25\nratio: 0.50\nversion: \"1.2\"\n",
+			expectedValue: "flag: dark-mode\npercentage: 25\nratio: 0.5\nversion: \"1.2\"",
+		},
+		{
+			name:          "success - json output as yaml",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "json", OutputFormat: "yaml"}},
+			raw:           `{"replicas": 3, "ratio": 1.5e2, "tags": [1, "2"]}`,
+			expectedValue: "ratio: 150\nreplicas: 3\ntags:\n    - 1\n    - \"2\"",
+		},
+		{
+			name:  "success - optional and empty",
+			field: fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "json"}},
+			raw:   "  ",
+		},
+		{
+			name:          "failed - required and empty",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "json", Required: true}},
+			raw:           "",
+			expectedError: "override: is required",
+		},
+		{
+			name:          "failed - json syntax error",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "json"}},
+			raw:           "{\n  \"flag\": \"dark-mode\",\n}",
+			expectedError: "override: line 3: invalid character '}' looking for beginning of object key string",
+			expectedLine:  3,
+		},
+		{
+			name:          "failed - json with trailing data",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "json"}},
+			raw:           "{}\n{}",
+			expectedError: "override: line 2: unexpected data after the end of the JSON document",
+			expectedLine:  2,
+		},
+		{
+			name:          "failed - yaml syntax error",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "yaml"}},
+			raw:           "flag: a\n regions: [",
+			expectedError: "override: line 2: mapping values are not allowed in this context",
+			expectedLine:  2,
+		},
+		{
+			name:          "failed - json schema violation",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "json", Schema: schema}},
+			raw:           "{\n  \"flag\": \"dark-mode\",\n  \"percentage\": 250\n}",
+			expectedError: "override: line 3: must be <= 100 but found 250 (at /percentage)",
+			expectedLine:  3,
+		},
+		{
+			name:          "failed - yaml schema violation",
+			field:         fields.Field{Label: "override", Properties: fields.FieldProperties{Type: "yaml", Schema: schema}},
+			raw:           "percentage: 5\n",
+			expectedError: "override: line 1: missing properties: 'flag' (at /)",
+			expectedLine:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			value, err := tt.field.NormaliseStructuredValue(tt.raw)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+
+				fieldValidationError, ok := err.(*fields.FieldValidationError)
+				assert.True(t, ok)
+				if ok {
+					assert.Equal(t, tt.expectedLine, fieldValidationError.Line)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedValue, value)
+		})
+	}
+}
+
+func TestField_DecodeAnswer(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		field          fields.Field
+		raw            string
+		expectedValues []string
+		expectedError  bool
+	}{
+		{
+			name:           "success - string answer",
+			field:          fields.Field{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+			raw:            `"Leon"`,
+			expectedValues: []string{"Leon"},
+		},
+		{
+			name:           "success - number and boolean answers",
+			field:          fields.Field{Label: "age", Properties: fields.FieldProperties{Type: "number"}},
+			raw:            `21.50`,
+			expectedValues: []string{"21.50"},
+		},
+		{
+			name:           "success - multiselect answer",
+			field:          fields.Field{Label: "colour", Properties: fields.FieldProperties{Type: "multiselect"}},
+			raw:            `["Red", "Blue"]`,
+			expectedValues: []string{"Red", "Blue"},
+		},
+		{
+			name:           "success - group answer kept as JSON",
+			field:          fields.Field{Label: "services", Properties: fields.FieldProperties{Type: "group"}},
+			raw:            `[ {"name": "api"} ]`,
+			expectedValues: []string{`[{"name":"api"}]`},
+		},
+		{
+			name:          "failed - list answer for single value field",
+			field:         fields.Field{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+			raw:           `["a", "b"]`,
+			expectedError: true,
+		},
+		{
+			name:          "failed - object answer for multiselect field",
+			field:         fields.Field{Label: "colour", Properties: fields.FieldProperties{Type: "multiselect"}},
+			raw:           `{"Red": true}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			values, err := tt.field.DecodeAnswer([]byte(tt.raw))
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedValues, values)
+		})
+	}
+}
+
+func TestField_IsDefaultChoice(t *testing.T) {
+
+	tests := []struct {
+		name             string
+		field            fields.Field
+		expectedSelected []string
+	}{
+		{
+			name:             "select default value",
+			field:            fields.Field{Label: "env", Properties: fields.FieldProperties{Type: "select", DefaultValue: "staging"}},
+			expectedSelected: []string{"staging"},
+		},
+		{
+			name:             "multiselect comma separated default value",
+			field:            fields.Field{Label: "env", Properties: fields.FieldProperties{Type: "multiselect", DefaultValue: "dev, staging"}},
+			expectedSelected: []string{"dev", "staging"},
+		},
+		{
+			name:             "multiselect prefilled choices replace the default value and are kept whole",
+			field:            fields.Field{Label: "env", Properties: fields.FieldProperties{Type: "multiselect", DefaultValue: "dev", PrefilledChoices: []string{"eu, west"}}},
+			expectedSelected: []string{"eu, west"},
+		},
+		{
+			name:  "multiselect prefilled with no choices",
+			field: fields.Field{Label: "env", Properties: fields.FieldProperties{Type: "multiselect", DefaultValue: "dev", PrefilledChoices: []string{}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var selected []string
+			for _, choice := range []string{"dev", "staging", "eu", "west", "eu, west"} {
+				if tt.field.IsDefaultChoice(choice) {
+					selected = append(selected, choice)
+				}
+			}
+
+			assert.Equal(t, tt.expectedSelected, selected)
+		})
+	}
+}
+
+func TestField_NormaliseValues(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		field           fields.Field
+		values          []string
+		expectedValues  []string
+		expectedMessage string
+	}{
+		{
+			name:           "success - text value trimmed",
+			field:          fields.Field{Label: "name", Properties: fields.FieldProperties{Type: "text", MaxLength: 5}},
+			values:         []string{" Leon "},
+			expectedValues: []string{"Leon"},
+		},
+		{
+			name:            "failed - text value too long",
+			field:           fields.Field{Label: "name", Properties: fields.FieldProperties{Type: "text", MaxLength: 3}},
+			values:          []string{"Leon"},
+			expectedMessage: "must be at most 3 characters long",
+		},
+		{
+			name:            "failed - required value missing",
+			field:           fields.Field{Label: "name", Properties: fields.FieldProperties{Type: "text", Required: true}},
+			values:          []string{},
+			expectedMessage: "is required",
+		},
+		{
+			name:            "failed - multiple values for single value field",
+			field:           fields.Field{Label: "env", Properties: fields.FieldProperties{Type: "select", Choices: []string{"dev", "prod"}}},
+			values:          []string{"dev", "prod"},
+			expectedMessage: "must be a single value",
+		},
+		{
+			name:           "success - multiselect values",
+			field:          fields.Field{Label: "colour", Properties: fields.FieldProperties{Type: "multiselect", Choices: []string{"Red", "Blue"}}},
+			values:         []string{"Red", "", "Blue"},
+			expectedValues: []string{"Red", "Blue"},
+		},
+		{
+			name:            "failed - multiselect value not a choice",
+			field:           fields.Field{Label: "colour", Properties: fields.FieldProperties{Type: "multiselect", Choices: []string{"Red", "Blue"}}},
+			values:          []string{"Green"},
+			expectedMessage: "'Green' is not one of: Red, Blue",
+		},
+		{
+			name:            "failed - multiple files for file field",
+			field:           fields.Field{Label: "doc", Properties: fields.FieldProperties{Type: "file"}},
+			values:          []string{"a.txt", "b.txt"},
+			expectedMessage: "only one file can be provided",
+		},
+		{
+			name:           "success - json value normalised",
+			field:          fields.Field{Label: "cfg", Properties: fields.FieldProperties{Type: "json"}},
+			values:         []string{"{ \"a\": 1 }"},
+			expectedValues: []string{`{"a":1}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			values, err := tt.field.NormaliseValues(tt.values)
+
+			if tt.expectedMessage != "" {
+				assert.ErrorIs(t, err, errors.ErrInvalidFieldValueProvided)
+				assert.Equal(t, tt.expectedMessage, err.(*fields.FieldValidationError).Message)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedValues, values)
+		})
+	}
+}
+
+func TestFields_ResolveAnswers(t *testing.T) {
+
+	testFields := fields.Fields{
+		Fields: []fields.Field{
+			{Label: "name", Properties: fields.FieldProperties{Type: "text", Required: true}},
+			{Label: "env", Properties: fields.FieldProperties{Type: "select", Choices: []string{"dev", "prod"}, DefaultValue: "dev"}},
+			{Label: "colour", Properties: fields.FieldProperties{Type: "multiselect", Choices: []string{"Red", "Blue"}, DefaultValue: "Red, Blue"}},
+			{Label: "fixed", Properties: fields.FieldProperties{Type: "text", ReadOnly: true, DefaultValue: "locked"}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		answers        string
+		expectedValues url.Values
+		expectedErrors map[string]string
+	}{
+		{
+			name:    "success - answers and defaults resolved",
+			answers: `{"name": "Leon", "fixed": "changed"}`,
+			expectedValues: url.Values{
+				"name":   []string{"Leon"},
+				"env":    []string{"dev"},
+				"colour": []string{"Red", "Blue"},
+				"fixed":  []string{"locked"},
+			},
+			expectedErrors: map[string]string{},
+		},
+		{
+			name:    "failed - invalid, missing and unknown answers",
+			answers: `{"env": "staging", "nmae": "Leon"}`,
+			expectedValues: url.Values{
+				"colour": []string{"Red", "Blue"},
+				"fixed":  []string{"locked"},
+			},
+			expectedErrors: map[string]string{
+				"name": "is required",
+				"env":  "must be one of: dev, prod",
+				"nmae": "no field is defined with this label",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			answers, err := fields.ParseAnswers([]byte(tt.answers))
+			assert.NoError(t, err)
+
+			values, answerErrors := testFields.ResolveAnswers(answers)
+
+			assert.Equal(t, tt.expectedValues, values)
+			assert.Equal(t, tt.expectedErrors, answerErrors)
+		})
+	}
+}
+
+func TestFields_ParseReplyCommand(t *testing.T) {
+
+	replyFields := &fields.Fields{Fields: []fields.Field{
+		{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+		{Label: "count", Properties: fields.FieldProperties{Type: "number"}},
+		{Label: "colour", Properties: fields.FieldProperties{Type: "multiselect", Choices: []string{"Red", "Blue"}}},
+		{Label: "config", Properties: fields.FieldProperties{Type: "json"}},
+	}}
+
+	tests := []struct {
+		name            string
+		reply           string
+		prefix          string
+		expectedCommand *fields.ReplyCommand
+		expectedErr     bool
+	}{
+		{
+			name:   "success - answers parsed as text and lists",
+			reply:  "Here you go\n/answer name = Leon Silcott\n/answer count=3\n/ANSWER colour=Red\n/answer colour=Blue\n> /reject",
+			prefix: "/",
+			expectedCommand: &fields.ReplyCommand{
+				Action: fields.ReplyActionSubmit,
+				Answers: map[string]json.RawMessage{
+					"name":   json.RawMessage(`"Leon Silcott"`),
+					"count":  json.RawMessage(`"3"`),
+					"colour": json.RawMessage(`["Red","Blue"]`),
+				},
+			},
+		},
+		{
+			name:   "success - JSON only used for group and json/yaml fields",
+			reply:  "/answer name=123\n/answer count=null\n/answer config={\"replicas\": 2}\n/answer unknown=true",
+			prefix: "/",
+			expectedCommand: &fields.ReplyCommand{
+				Action: fields.ReplyActionSubmit,
+				Answers: map[string]json.RawMessage{
+					"name":    json.RawMessage(`"123"`),
+					"count":   json.RawMessage(`"null"`),
+					"config":  json.RawMessage(`{"replicas": 2}`),
+					"unknown": json.RawMessage(`"true"`),
+				},
+			},
+		},
+		{
+			name:   "success - invalid JSON for a json field used as text",
+			reply:  "/answer config={replicas: 2}",
+			prefix: "/",
+			expectedCommand: &fields.ReplyCommand{
+				Action: fields.ReplyActionSubmit,
+				Answers: map[string]json.RawMessage{
+					"config": json.RawMessage(`"{replicas: 2}"`),
+				},
+			},
+		},
+		{
+			name:   "success - approve with a custom prefix",
+			reply:  "!approve",
+			prefix: "!",
+			expectedCommand: &fields.ReplyCommand{
+				Action:  fields.ReplyActionSubmit,
+				Answers: map[string]json.RawMessage{},
+			},
+		},
+		{
+			name:            "success - reject",
+			reply:           "  /reject not this time",
+			prefix:          "/",
+			expectedCommand: &fields.ReplyCommand{Action: fields.ReplyActionReject},
+		},
+		{
+			name:            "success - reply without commands ignored",
+			reply:           "Looks good to me, /answer is how to reply",
+			prefix:          "/",
+			expectedCommand: nil,
+		},
+		{
+			name:        "failed - answer without a value",
+			reply:       "/answer name",
+			prefix:      "/",
+			expectedErr: true,
+		},
+		{
+			name:        "failed - reject along with answers",
+			reply:       "/answer name=Leon\n/reject",
+			prefix:      "/",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			command, err := replyFields.ParseReplyCommand(tt.reply, tt.prefix)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCommand, command)
+		})
+	}
+}
+
+func TestFieldProperties_GetMaxUploadSize(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		properties   fields.FieldProperties
+		expectedSize int64
+	}{
+		{
+			name:         "total size takes precedence",
+			properties:   fields.FieldProperties{Type: "multifile", MaxFileSize: "1KB", MaxTotalSize: "2KB", MaxFiles: 5},
+			expectedSize: 2048,
+		},
+		{
+			name:         "file size multiplied by max files",
+			properties:   fields.FieldProperties{Type: "multifile", MaxFileSize: "1MB", MaxFiles: 3},
+			expectedSize: 3 << 20,
+		},
+		{
+			name:         "file field only accepts a single file",
+			properties:   fields.FieldProperties{Type: "file", MaxFileSize: "512"},
+			expectedSize: 512,
+		},
+		{
+			name:         "no limit without max files",
+			properties:   fields.FieldProperties{Type: "multifile", MaxFileSize: "1MB"},
+			expectedSize: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			size, err := tt.properties.GetMaxUploadSize()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSize, size)
+		})
+	}
+}
+
+func TestFieldProperties_IsAcceptedFileType(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		acceptedFileTypes  []string
+		fileName           string
+		sniffedContentType string
+		expectedAccepted   bool
+	}{
+		{
+			name:               "all files accepted without accepted file types",
+			fileName:           "notes.bin",
+			sniffedContentType: "application/octet-stream",
+			expectedAccepted:   true,
+		},
+		{
+			name:               "extension matched case-insensitively",
+			acceptedFileTypes:  []string{".csv"},
+			fileName:           "REPORT.CSV",
+			sniffedContentType: "text/plain; charset=utf-8",
+			expectedAccepted:   true,
+		},
+		{
+			name:               "extension not accepted",
+			acceptedFileTypes:  []string{".csv", ".txt"},
+			fileName:           "script.sh",
+			sniffedContentType: "text/plain; charset=utf-8",
+			expectedAccepted:   false,
+		},
+		{
+			name:               "wildcard matched against the sniffed content type",
+			acceptedFileTypes:  []string{"image/*"},
+			fileName:           "photo",
+			sniffedContentType: "image/png",
+			expectedAccepted:   true,
+		},
+		{
+			name:               "renamed file rejected when sniffing doesn't recognise it",
+			acceptedFileTypes:  []string{"image/png"},
+			fileName:           "payload.png",
+			sniffedContentType: "application/octet-stream",
+			expectedAccepted:   false,
+		},
+		{
+			name:               "sniffed content type takes precedence over the extension",
+			acceptedFileTypes:  []string{"application/pdf"},
+			fileName:           "document.pdf",
+			sniffedContentType: "image/png",
+			expectedAccepted:   false,
+		},
+		{
+			name:               "extension used when the sniffed content type is generic",
+			acceptedFileTypes:  []string{"application/json"},
+			fileName:           "config.json",
+			sniffedContentType: "text/plain; charset=utf-8",
+			expectedAccepted:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			properties := fields.FieldProperties{Type: "file", AcceptedFileTypes: tt.acceptedFileTypes}
+
+			assert.Equal(t, tt.expectedAccepted, properties.IsAcceptedFileType(tt.fileName, tt.sniffedContentType))
+		})
+	}
+}
+
+func TestNewFileManifestEntry(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		originalName  string
+		storedName    string
+		content       []byte
+		expectedEntry fields.FileManifestEntry
+	}{
+		{
+			name:         "text file",
+			originalName: "../notes/hello.txt",
+			storedName:   "hello.txt",
+			content:      []byte("hello"),
+			expectedEntry: fields.FileManifestEntry{
+				OriginalName: "../notes/hello.txt",
+				StoredName:   "hello.txt",
+				Size:         5,
+				ContentType:  "text/plain",
+				SHA256:       "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			},
+		},
+		{
+			name:         "content type sniffed regardless of the extension",
+			originalName: "image.bin",
+			storedName:   "image.bin",
+			content:      []byte("\x89PNG\r\n\x1a\n0000000000000"),
+			expectedEntry: fields.FileManifestEntry{
+				OriginalName: "image.bin",
+				StoredName:   "image.bin",
+				Size:         21,
+				ContentType:  "image/png",
+				SHA256:       "5bc51f834320771ec7821d3ec92a16033af1b9fe9adee0f064ef4470cc3013fc",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			filePath := filepath.Join(t.TempDir(), tt.storedName)
+			assert.NoError(t, os.WriteFile(filePath, tt.content, 0644))
+
+			entry, err := fields.NewFileManifestEntry(tt.originalName, filePath)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEntry, entry)
+		})
+	}
+}
+
+func TestFileManifest_WriteToDir(t *testing.T) {
+
+	dir := t.TempDir()
+	manifest := fields.FileManifest{
+		Field: "documents",
+		Files: []fields.FileManifestEntry{
+			{OriginalName: "a.txt", StoredName: "a.txt", Size: 1, ContentType: "text/plain", SHA256: "aaa"},
+			{OriginalName: "A.txt", StoredName: "A (1).txt", Size: 2, ContentType: "text/plain", SHA256: "bbb"},
+		},
+	}
+
+	manifestPath, err := manifest.WriteToDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, fields.FileManifestName), manifestPath)
+
+	loadedManifest, err := fields.LoadFileManifestFromDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, &manifest, loadedManifest)
+	assert.Equal(t, "aaa  a.txt\nbbb  A (1).txt", loadedManifest.Checksums())
+}
+
+// writeZipArchive writes a zip archive with the given entries (name to content) to the path
+func writeZipArchive(t *testing.T, archivePath string, entries map[string]string) {
+	var buffer bytes.Buffer
+
+	zipWriter := zip.NewWriter(&buffer)
+	for name, content := range entries {
+		entryWriter, err := zipWriter.Create(name)
+		assert.NoError(t, err)
+		_, err = entryWriter.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zipWriter.Close())
+	assert.NoError(t, os.WriteFile(archivePath, buffer.Bytes(), 0644))
+}
+
+// writeTarGzipArchive writes a gzip compressed tar archive with the given headers, regular
+// files get their content from the content map
+func writeTarGzipArchive(t *testing.T, archivePath string, headers []*tar.Header, content map[string]string) {
+	var buffer bytes.Buffer
+
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, header := range headers {
+		header.Size = int64(len(content[header.Name]))
+		assert.NoError(t, tarWriter.WriteHeader(header))
+		_, err := tarWriter.Write([]byte(content[header.Name]))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	assert.NoError(t, os.WriteFile(archivePath, buffer.Bytes(), 0644))
+}
+
+func TestArchiveExtractor_Extract(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		properties      fields.FieldProperties
+		fileName        string
+		writeArchive    func(t *testing.T, archivePath string)
+		expectedFiles   []string
+		expectedSkipped []string
+		expectedError   error
+	}{
+		{
+			name:       "success - zip archive extracted",
+			properties: fields.FieldProperties{Type: "file", ExtractArchives: true},
+			fileName:   "bundle.zip",
+			writeArchive: func(t *testing.T, archivePath string) {
+				writeZipArchive(t, archivePath, map[string]string{"docs/readme.txt": "hello"})
+			},
+			expectedFiles:   []string{"docs/readme.txt"},
+			expectedSkipped: []string{},
+		},
+		{
+			name:       "success - symlinks skipped",
+			properties: fields.FieldProperties{Type: "file", ExtractArchives: true},
+			fileName:   "bundle.tar.gz",
+			writeArchive: func(t *testing.T, archivePath string) {
+				writeTarGzipArchive(t, archivePath, []*tar.Header{
+					{Name: "./", Typeflag: tar.TypeDir, Mode: 0755},
+					{Name: "passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+					{Name: "notes.txt", Typeflag: tar.TypeReg, Mode: 0644},
+				}, map[string]string{"notes.txt": "notes"})
+			},
+			expectedFiles:   []string{"notes.txt"},
+			expectedSkipped: []string{"passwd"},
+		},
+		{
+			name:       "success - archives not extracted unless enabled",
+			properties: fields.FieldProperties{Type: "file"},
+			fileName:   "bundle.zip",
+			writeArchive: func(t *testing.T, archivePath string) {
+				writeZipArchive(t, archivePath, map[string]string{"readme.txt": "hello"})
+			},
+		},
+		{
+			name:       "failed - entry outside of the destination directory (zip-slip)",
+			properties: fields.FieldProperties{Type: "file", ExtractArchives: true},
+			fileName:   "bundle.zip",
+			writeArchive: func(t *testing.T, archivePath string) {
+				writeZipArchive(t, archivePath, map[string]string{"../../evil.sh": "rm -rf /"})
+			},
+			expectedError: errors.ErrUnsafeArchiveEntry,
+		},
+		{
+			name:       "failed - extracted size limit exceeded",
+			properties: fields.FieldProperties{Type: "file", ExtractArchives: true, MaxExtractedSize: "8B"},
+			fileName:   "bundle.zip",
+			writeArchive: func(t *testing.T, archivePath string) {
+				writeZipArchive(t, archivePath, map[string]string{"large.txt": "more than eight bytes"})
+			},
+			expectedError: errors.ErrArchiveTooLarge,
+		},
+		{
+			name:       "failed - extracted entry limit exceeded",
+			properties: fields.FieldProperties{Type: "file", ExtractArchives: true, MaxExtractedFiles: 1},
+			fileName:   "bundle.tar.gz",
+			writeArchive: func(t *testing.T, archivePath string) {
+				writeTarGzipArchive(t, archivePath, []*tar.Header{
+					{Name: "a.txt", Typeflag: tar.TypeReg, Mode: 0644},
+					{Name: "b.txt", Typeflag: tar.TypeReg, Mode: 0644},
+				}, map[string]string{"a.txt": "a", "b.txt": "b"})
+			},
+			expectedError: errors.ErrArchiveHasTooManyEntries,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			dir := t.TempDir()
+			archivePath := filepath.Join(dir, tt.fileName)
+			destinationDir := filepath.Join(dir, "extracted")
+			tt.writeArchive(t, archivePath)
+
+			field := fields.Field{Label: "bundle", Properties: tt.properties}
+			archiveExtractor, err := field.NewArchiveExtractor()
+			assert.NoError(t, err)
+
+			result, err := archiveExtractor.Extract(tt.fileName, archivePath, destinationDir)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.NoDirExists(t, destinationDir)
+				return
+			}
+
+			assert.NoError(t, err)
+			if tt.expectedFiles == nil {
+				assert.Nil(t, result)
+				assert.NoDirExists(t, destinationDir)
+				return
+			}
+
+			assert.Equal(t, tt.expectedFiles, result.Files)
+			assert.Equal(t, tt.expectedSkipped, result.SkippedEntries)
+			for _, file := range tt.expectedFiles {
+				assert.FileExists(t, filepath.Join(destinationDir, filepath.FromSlash(file)))
+			}
+		})
+	}
+}
+
+func TestField_SecretValues(t *testing.T) {
+
+	deployKeysField := fields.Field{Label: "deploy-keys", Properties: fields.FieldProperties{Type: "group", SubFields: []fields.Field{
+		{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+		{Label: "key", Properties: fields.FieldProperties{Type: "text", Secret: true}},
+		{Label: "pin", Properties: fields.FieldProperties{Type: "number", Secret: true}},
+		{Label: "enabled", Properties: fields.FieldProperties{Type: "boolean", Secret: true}},
+	}}}
+
+	tests := []struct {
+		name                 string
+		field                fields.Field
+		values               []string
+		expectedSecretValues []string
+	}{
+		{
+			name:                 "field that isn't secret",
+			field:                fields.Field{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+			values:               []string{"Leon"},
+			expectedSecretValues: []string{},
+		},
+		{
+			name:                 "secret field",
+			field:                fields.Field{Label: "api-key", Properties: fields.FieldProperties{Type: "text", Secret: true}},
+			values:               []string{"s3cr3t"},
+			expectedSecretValues: []string{"s3cr3t"},
+		},
+		{
+			name:                 "empty secret field",
+			field:                fields.Field{Label: "api-key", Properties: fields.FieldProperties{Type: "text", Secret: true}},
+			values:               []string{" "},
+			expectedSecretValues: []string{},
+		},
+		{
+			name:                 "multi-line secret field is also returned line by line",
+			field:                fields.Field{Label: "private-key", Properties: fields.FieldProperties{Type: "textarea", Secret: true}},
+			values:               []string{"-----BEGIN KEY-----\nabc123\n-----END KEY-----"},
+			expectedSecretValues: []string{"-----BEGIN KEY-----\nabc123\n-----END KEY-----", "-----BEGIN KEY-----", "abc123", "-----END KEY-----"},
+		},
+		{
+			name:                 "multiselect secret field",
+			field:                fields.Field{Label: "regions", Properties: fields.FieldProperties{Type: "multiselect", Secret: true}},
+			values:               []string{"eu-west-1", "us-east-1"},
+			expectedSecretValues: []string{"eu-west-1", "us-east-1"},
+		},
+		{
+			name:                 "group field with secret sub-fields",
+			field:                deployKeysField,
+			values:               []string{`[{"name":"api","key":"ssh-ed25519 AAAA","pin":1234,"enabled":true},{"name":"jobs","key":null,"pin":null,"enabled":false}]`},
+			expectedSecretValues: []string{"ssh-ed25519 AAAA", "1234"},
+		},
+		{
+			name: "secret group field",
+			field: fields.Field{Label: "services", Properties: fields.FieldProperties{Type: "group", Secret: true, SubFields: []fields.Field{
+				{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+			}}},
+			values:               []string{`[{"name":"api"}]`},
+			expectedSecretValues: []string{`[{"name":"api"}]`, "api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedSecretValues, tt.field.SecretValues(tt.values))
+		})
+	}
+}
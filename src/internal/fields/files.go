@@ -0,0 +1,213 @@
+package fields
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/archive"
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+)
+
+const (
+
+	// defaultMaxExtractedSize is the largest combined size (in bytes) of the files extracted from
+	// the archives of a file/multifile field when maxExtractedSize isn't provided
+	defaultMaxExtractedSize int64 = 1 << 30
+
+	// defaultMaxExtractedFiles is the maximum number of entries extracted from the archives
+	// of a file/multifile field when maxExtractedFiles isn't provided
+	defaultMaxExtractedFiles = 10000
+)
+
+var (
+
+	// genericContentTypes are sniffed content types that don't tell what kind of file was
+	// uploaded, so the file's extension is used to determine its content type instead
+	genericContentTypes = []string{
+		"application/octet-stream",
+		"text/plain",
+		"text/xml",
+		"application/zip",
+	}
+
+	// sniffedContentTypeAliases are the content types that are reported by extension
+	// for the content types that are detected when sniffing
+	sniffedContentTypeAliases = map[string][]string{
+		"application/ogg": {"audio/ogg", "video/ogg"},
+		"audio/wave":      {"audio/wav", "audio/x-wav", "audio/vnd.wave"},
+		"video/avi":       {"video/x-msvideo"},
+	}
+)
+
+// IsFile returns whether the field is a file or multifile field, whose output points
+// to the cache directory holding the uploaded file(s).
+func (f *Field) IsFile() bool {
+	return f.Properties.Type == "file" || f.Properties.Type == "multifile"
+}
+
+// GetMaxFileSize returns the largest size (in bytes) of a single file that can be uploaded
+// to a file/multifile field. It returns 0 when there is no limit.
+func (fp *FieldProperties) GetMaxFileSize() (int64, error) {
+	return toolbox.ParseByteSize(fp.MaxFileSize)
+}
+
+// GetMaxTotalSize returns the largest combined size (in bytes) of the files that can be
+// uploaded to a file/multifile field. It returns 0 when there is no limit.
+func (fp *FieldProperties) GetMaxTotalSize() (int64, error) {
+	return toolbox.ParseByteSize(fp.MaxTotalSize)
+}
+
+// GetMaxFiles returns the maximum number of files that can be uploaded to a file/multifile
+// field, file fields only ever accept a single file. It returns 0 when there is no limit.
+func (fp *FieldProperties) GetMaxFiles() int {
+	if fp.Type == "file" {
+		return 1
+	}
+	return fp.MaxFiles
+}
+
+// GetMaxUploadSize returns the largest combined size (in bytes) of the files that can be
+// uploaded to a file/multifile field, or 0 when there is no limit
+func (fp *FieldProperties) GetMaxUploadSize() (int64, error) {
+
+	maxTotalSize, err := fp.GetMaxTotalSize()
+	if err != nil || maxTotalSize > 0 {
+		return maxTotalSize, err
+	}
+
+	maxFileSize, err := fp.GetMaxFileSize()
+	if err != nil {
+		return 0, err
+	}
+
+	return maxFileSize * int64(fp.GetMaxFiles()), nil
+}
+
+// GetExtractionLimits returns the limits applied when extracting the archives provided for a
+// file/multifile field, falling back to the default limits when they aren't provided
+func (fp *FieldProperties) GetExtractionLimits() (archive.Limits, error) {
+	var limits archive.Limits = archive.Limits{
+		MaxSize:    defaultMaxExtractedSize,
+		MaxEntries: defaultMaxExtractedFiles,
+	}
+
+	maxExtractedSize, err := toolbox.ParseByteSize(fp.MaxExtractedSize)
+	if err != nil {
+		return limits, err
+	}
+
+	if maxExtractedSize > 0 {
+		limits.MaxSize = maxExtractedSize
+	}
+	if fp.MaxExtractedFiles > 0 {
+		limits.MaxEntries = fp.MaxExtractedFiles
+	}
+
+	return limits, nil
+}
+
+// validateFileFieldDefinition makes sure the upload and extraction limits of the given file/multifile field are valid
+func validateFileFieldDefinition(field *Field) error {
+
+	if field.Properties.MaxFiles < 0 {
+		return fmt.Errorf("maxFiles must not be negative, got %d", field.Properties.MaxFiles)
+	}
+
+	if field.Properties.MaxExtractedFiles < 0 {
+		return fmt.Errorf("maxExtractedFiles must not be negative, got %d", field.Properties.MaxExtractedFiles)
+	}
+
+	_, err := field.Properties.GetExtractionLimits()
+	if err != nil {
+		return fmt.Errorf("maxExtractedSize: %v", err)
+	}
+
+	_, err = field.Properties.GetMaxFileSize()
+	if err != nil {
+		return fmt.Errorf("maxFileSize: %v", err)
+	}
+
+	_, err = field.Properties.GetMaxTotalSize()
+	if err != nil {
+		return fmt.Errorf("maxTotalSize: %v", err)
+	}
+
+	return nil
+}
+
+// IsAcceptedFileType returns whether a file with the given name and sniffed content type
+// satisfies the field's accepted file types
+func (fp *FieldProperties) IsAcceptedFileType(fileName, sniffedContentType string) bool {
+
+	if len(fp.AcceptedFileTypes) == 0 {
+		return true
+	}
+
+	extension := strings.ToLower(filepath.Ext(fileName))
+	contentTypes := fileContentTypes(extension, sniffedContentType)
+
+	for _, acceptedFileType := range fp.AcceptedFileTypes {
+		acceptedFileType = strings.ToLower(strings.TrimSpace(acceptedFileType))
+
+		switch {
+		case strings.HasPrefix(acceptedFileType, "."):
+			if extension == acceptedFileType {
+				return true
+			}
+
+		case strings.HasSuffix(acceptedFileType, "/*"):
+			for _, contentType := range contentTypes {
+				if strings.HasPrefix(contentType, strings.TrimSuffix(acceptedFileType, "*")) {
+					return true
+				}
+			}
+
+		default:
+			if toolbox.StringInSlice(acceptedFileType, contentTypes) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// fileContentTypes returns the content type(s) a file is treated as, falling back to
+// its extension when the sniffed content type is generic
+func fileContentTypes(extension, sniffedContentType string) []string {
+
+	sniffedMediaType := mediaType(sniffedContentType)
+	if sniffedMediaType != "" && !toolbox.StringInSlice(sniffedMediaType, genericContentTypes) {
+		return append([]string{sniffedMediaType}, sniffedContentTypeAliases[sniffedMediaType]...)
+	}
+
+	extensionMediaType := mediaType(mime.TypeByExtension(extension))
+	if extensionMediaType == "" || isSniffableContentType(extensionMediaType) {
+		return []string{sniffedMediaType}
+	}
+
+	return []string{extensionMediaType}
+}
+
+// isSniffableContentType returns whether files of the given content type are recognised when
+// sniffed, in which case a generic sniffed content type means the file isn't of that type
+func isSniffableContentType(contentType string) bool {
+	switch {
+	case contentType == "image/svg+xml":
+		return false
+	case strings.HasPrefix(contentType, "image/"),
+		strings.HasPrefix(contentType, "audio/"),
+		strings.HasPrefix(contentType, "video/"),
+		contentType == "application/pdf":
+		return true
+	}
+	return false
+}
+
+// mediaType returns the lower case media type of the content type, without any parameters
+func mediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
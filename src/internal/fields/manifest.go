@@ -0,0 +1,163 @@
+package fields
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileManifestName is the name of the manifest written to the cache directory of a
+// file/multifile field, it can't be used as the name of an uploaded file
+const FileManifestName = "manifest.json"
+
+// FileManifest describes the files provided for a file/multifile field
+type FileManifest struct {
+
+	// Field is the label of the field the files were provided for
+	Field string `json:"field"`
+
+	// Files the files provided for the field
+	Files []FileManifestEntry `json:"files"`
+
+	// Quarantined the files the upload scanner flagged, which were moved out of the cache directory
+	Quarantined []FileManifestEntry `json:"quarantined,omitempty"`
+}
+
+// FileManifestEntry describes a file provided for a file/multifile field
+type FileManifestEntry struct {
+
+	// OriginalName is the name the file was provided with
+	OriginalName string `json:"original_name"`
+
+	// StoredName is the name of the file in the field's cache directory
+	StoredName string `json:"stored_name"`
+
+	// Size is the size of the file in bytes
+	Size int64 `json:"size"`
+
+	// ContentType is the content type of the file
+	ContentType string `json:"content_type"`
+
+	// SHA256 is the hex encoded SHA-256 checksum of the file
+	SHA256 string `json:"sha256"`
+
+	// ExtractedTo is the directory the archive was extracted into, relative to the cache directory
+	ExtractedTo string `json:"extracted_to,omitempty"`
+
+	// ExtractedFiles the paths of the files extracted from the archive, relative to the cache directory
+	ExtractedFiles []string `json:"extracted_files,omitempty"`
+
+	// Scan is the outcome of scanning the file, if an upload scanner is configured
+	Scan *FileScanResult `json:"scan,omitempty"`
+
+	// QuarantinedTo is the path the file was moved to, if it was quarantined
+	QuarantinedTo string `json:"quarantined_to,omitempty"`
+}
+
+// FileScanResult describes the outcome of scanning a file with the upload scanner
+type FileScanResult struct {
+
+	// Scanner is the name of the scanner the file was scanned with
+	Scanner string `json:"scanner"`
+
+	// Flagged is true when the scanner flagged the file
+	Flagged bool `json:"flagged"`
+
+	// Detail is the scanner's explanation of the outcome, i.e. the name of the detected malware
+	Detail string `json:"detail,omitempty"`
+}
+
+// NewFileManifestEntry returns the manifest entry for the file stored at the given path, reading
+// the file to determine its size, content type and checksum
+func NewFileManifestEntry(originalName, filePath string) (FileManifestEntry, error) {
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return FileManifestEntry{}, err
+	}
+	defer file.Close()
+
+	sniffedContent := make([]byte, 512)
+	n, err := io.ReadFull(file, sniffedContent)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return FileManifestEntry{}, err
+	}
+
+	hash := sha256.New()
+	hash.Write(sniffedContent[:n])
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return FileManifestEntry{}, err
+	}
+
+	return FileManifestEntry{
+		OriginalName: originalName,
+		StoredName:   filepath.Base(filePath),
+		Size:         int64(n) + size,
+		ContentType:  FileContentType(filePath, http.DetectContentType(sniffedContent[:n])),
+		SHA256:       hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// FileContentType returns the content type a file with the given name and sniffed content
+// type (i.e. from http.DetectContentType) is treated as when checking its accepted file types
+func FileContentType(fileName, sniffedContentType string) string {
+	contentTypes := fileContentTypes(strings.ToLower(filepath.Ext(fileName)), sniffedContentType)
+	if len(contentTypes) == 0 || contentTypes[0] == "" {
+		return "application/octet-stream"
+	}
+	return contentTypes[0]
+}
+
+// WriteToDir writes the manifest to the given (cache) directory, returning the path of the manifest
+func (m *FileManifest) WriteToDir(dir string) (string, error) {
+
+	manifestBytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	manifestPath := filepath.Join(dir, FileManifestName)
+	return manifestPath, os.WriteFile(manifestPath, manifestBytes, 0644)
+}
+
+// Checksums returns the checksums of the files in the format used by sha256sum
+func (m *FileManifest) Checksums() string {
+	var checksums []string
+	for _, file := range m.Files {
+		checksums = append(checksums, fmt.Sprintf("%s  %s", file.SHA256, file.StoredName))
+	}
+	return strings.Join(checksums, "\n")
+}
+
+// ExtractedFiles returns the paths of the files extracted from the archives, relative to the cache directory
+func (m *FileManifest) ExtractedFiles() []string {
+	var extractedFiles []string = []string{}
+	for _, file := range m.Files {
+		extractedFiles = append(extractedFiles, file.ExtractedFiles...)
+	}
+	return extractedFiles
+}
+
+// LoadFileManifestFromDir loads the manifest written to the given (cache) directory
+func LoadFileManifestFromDir(dir string) (*FileManifest, error) {
+	var manifest FileManifest
+
+	manifestBytes, err := os.ReadFile(filepath.Join(dir, FileManifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(manifestBytes, &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
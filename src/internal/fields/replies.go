@@ -0,0 +1,101 @@
+package fields
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// ReplyActionSubmit is the action of replies that answer the fields (i.e. "/answer name=value")
+	// or approve the portal with its default values (i.e. "/approve")
+	ReplyActionSubmit = "submit"
+
+	// ReplyActionReject is the action of replies that reject the portal (i.e. "/reject")
+	ReplyActionReject = "reject"
+)
+
+// ReplyCommand holds what a reply to the portal's comment asks for
+type ReplyCommand struct {
+
+	// Action is what the reply asks for, either ReplyActionSubmit or ReplyActionReject
+	Action string
+
+	// Answers holds the raw JSON answer provided for each field label, which can be
+	// resolved with ResolveAnswers
+	Answers map[string]json.RawMessage
+}
+
+// ParseReplyCommand parses the answer, approve and reject commands of a reply to the portal's
+// comment, returning nil when the reply has no commands
+func (f *Fields) ParseReplyCommand(reply, prefix string) (*ReplyCommand, error) {
+	var submitted, rejected bool
+	var answerValues map[string][]string = make(map[string][]string)
+	var answerLabels []string
+
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		command, argument, _ := strings.Cut(strings.TrimPrefix(line, prefix), " ")
+		argument = strings.TrimSpace(argument)
+
+		switch strings.ToLower(command) {
+		case "answer":
+			label, value, found := strings.Cut(argument, "=")
+			label = strings.TrimSpace(label)
+			if !found || label == "" {
+				return nil, fmt.Errorf("%sanswer must be followed by <label>=<value>, got: %s", prefix, argument)
+			}
+
+			if _, ok := answerValues[label]; !ok {
+				answerLabels = append(answerLabels, label)
+			}
+			answerValues[label] = append(answerValues[label], strings.TrimSpace(value))
+			submitted = true
+		case "approve":
+			submitted = true
+		case "reject":
+			rejected = true
+		}
+	}
+
+	if rejected && submitted {
+		return nil, fmt.Errorf("%sreject can't be used along with %sanswer or %sapprove", prefix, prefix, prefix)
+	}
+
+	if rejected {
+		return &ReplyCommand{Action: ReplyActionReject}, nil
+	}
+
+	if !submitted {
+		return nil, nil
+	}
+
+	var answers map[string]json.RawMessage = make(map[string]json.RawMessage, len(answerLabels))
+	for _, label := range answerLabels {
+		answer, err := replyAnswerToJSON(f.fieldWithLabel(label), answerValues[label])
+		if err != nil {
+			return nil, err
+		}
+		answers[label] = answer
+	}
+
+	return &ReplyCommand{Action: ReplyActionSubmit, Answers: answers}, nil
+}
+
+// replyAnswerToJSON returns the values answered for the field as JSON. A single value is used
+// as-is for group and json/yaml fields when it is valid JSON, otherwise the value(s) are used as text
+func replyAnswerToJSON(field *Field, values []string) (json.RawMessage, error) {
+	if len(values) == 1 {
+		if field != nil && (field.IsGroup() || field.IsStructured()) && json.Valid([]byte(values[0])) {
+			return json.RawMessage(values[0]), nil
+		}
+
+		return json.Marshal(values[0])
+	}
+
+	return json.Marshal(values)
+}
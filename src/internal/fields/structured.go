@@ -0,0 +1,387 @@
+package fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	// StructuredOutputFormatJSON outputs the value of a json/yaml field as minified JSON
+	StructuredOutputFormatJSON = "json"
+
+	// StructuredOutputFormatYAML outputs the value of a json/yaml field as consistently indented YAML
+	StructuredOutputFormatYAML = "yaml"
+)
+
+// yamlErrorLineRegex matches the prefix and line number reported in YAML parsing errors
+var yamlErrorLineRegex = regexp.MustCompile(`^yaml: (?:line (\d+): )?`)
+
+// IsStructured returns whether the field is a json or yaml field, which holds a
+// config blob that is syntax checked (and optionally validated against a JSON Schema).
+func (f *Field) IsStructured() bool {
+	return f.Properties.Type == "json" || f.Properties.Type == "yaml"
+}
+
+// GetStructuredOutputFormat returns the format the value of a json/yaml field will be output in.
+// If no output format is provided, the field's type is used.
+func (fp *FieldProperties) GetStructuredOutputFormat() string {
+	if fp.OutputFormat != "" {
+		return fp.OutputFormat
+	}
+	return fp.Type
+}
+
+// GetSchema returns the JSON Schema for a field, either from the Schema field or by loading from SchemaFilePath.
+// If both are provided, Schema takes precedence. Relative file paths are resolved against the GitHub workspace.
+func (fp *FieldProperties) GetSchema() (string, error) {
+	// If the schema is directly provided, use it
+	if fp.Schema != "" {
+		return fp.Schema, nil
+	}
+
+	// If schemaFilePath is provided, load from file
+	if fp.SchemaFilePath != "" {
+		schemaFilePath := fp.SchemaFilePath
+		if !filepath.IsAbs(schemaFilePath) && os.Getenv("GITHUB_WORKSPACE") != "" {
+			schemaFilePath = filepath.Join(os.Getenv("GITHUB_WORKSPACE"), schemaFilePath)
+		}
+
+		schemaBytes, err := os.ReadFile(schemaFilePath)
+		if err != nil {
+			return "", err
+		}
+		return string(schemaBytes), nil
+	}
+
+	// No schema provided
+	return "", nil
+}
+
+// compileSchema compiles the JSON Schema of the field, returning nil if no schema is provided
+func (fp *FieldProperties) compileSchema() (*jsonschema.Schema, error) {
+	schema, err := fp.GetSchema()
+	if err != nil || schema == "" {
+		return nil, err
+	}
+
+	return jsonschema.CompileString("schema.json", schema)
+}
+
+// NormaliseStructuredValue validates the value submitted for a json/yaml field against its syntax and
+// schema, returning it in the field's output format
+func (f *Field) NormaliseStructuredValue(raw string) (string, error) {
+	var document interface{}
+	var err error
+
+	if strings.TrimSpace(raw) == "" {
+		if f.Properties.Required {
+			return "", newFieldValidationError(f.Label, "is required")
+		}
+		return "", nil
+	}
+
+	switch f.Properties.Type {
+	case "json":
+		document, err = decodeJSONDocument(raw)
+	case "yaml":
+		document, err = decodeYAMLDocument(raw)
+	}
+	if err != nil {
+		if fieldValidationError, ok := err.(*FieldValidationError); ok {
+			fieldValidationError.Label = f.Label
+		}
+		return "", err
+	}
+
+	schema, err := f.Properties.compileSchema()
+	if err != nil {
+		return "", newFieldValidationError(f.Label, "schema could not be loaded: %v", err)
+	}
+
+	if schema != nil {
+		err = schema.Validate(document)
+		if schemaValidationError, ok := err.(*jsonschema.ValidationError); ok {
+			return "", schemaValidationErrorToFieldValidationError(f.Label, raw, schemaValidationError)
+		}
+		if err != nil {
+			return "", newFieldValidationError(f.Label, "%v", err)
+		}
+	}
+
+	switch f.Properties.GetStructuredOutputFormat() {
+	case StructuredOutputFormatYAML:
+		normalisedBytes, err := yamlv3.Marshal(toYAMLCompatible(document))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(normalisedBytes), "\n"), nil
+
+	default:
+		normalisedBytes, err := json.Marshal(document)
+		if err != nil {
+			return "", err
+		}
+		return string(normalisedBytes), nil
+	}
+}
+
+// validateStructuredFieldDefinition makes sure the output format and schema of the json/yaml field are valid
+func validateStructuredFieldDefinition(field *Field) error {
+
+	if field.Properties.OutputFormat != "" &&
+		field.Properties.OutputFormat != StructuredOutputFormatJSON &&
+		field.Properties.OutputFormat != StructuredOutputFormatYAML {
+		return errors.ErrInvalidStructuredOutputFormatProvided
+	}
+
+	_, err := field.Properties.compileSchema()
+	if err != nil {
+		return errors.ErrInvalidSchemaProvided
+	}
+
+	return nil
+}
+
+// decodeJSONDocument decodes a single JSON document, keeping numbers as they were provided
+func decodeJSONDocument(raw string) (interface{}, error) {
+	var document interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&document)
+	if err != nil {
+		if syntaxError, ok := err.(*json.SyntaxError); ok {
+			return nil, newStructuredSyntaxError(lineAtOffset(raw, syntaxError.Offset), syntaxError.Error())
+		}
+		return nil, newStructuredSyntaxError(lineAtOffset(raw, decoder.InputOffset()), err.Error())
+	}
+
+	// make sure only a single document was provided
+	if decoder.More() {
+		return nil, newStructuredSyntaxError(lineAtOffset(raw, decoder.InputOffset()), "unexpected data after the end of the JSON document")
+	}
+
+	return document, nil
+}
+
+// decodeYAMLDocument decodes a single YAML document into values that can be validated
+// against a JSON Schema and marshalled to JSON
+func decodeYAMLDocument(raw string) (interface{}, error) {
+	var document interface{}
+
+	err := yamlv3.Unmarshal([]byte(raw), &document)
+	if err != nil {
+		var line int
+		if match := yamlErrorLineRegex.FindStringSubmatch(err.Error()); match != nil && match[1] != "" {
+			line, _ = strconv.Atoi(match[1])
+		}
+		return nil, newStructuredSyntaxError(line, yamlErrorLineRegex.ReplaceAllString(err.Error(), ""))
+	}
+
+	converted, err := toJSONCompatible(document)
+	if err != nil {
+		return nil, newStructuredSyntaxError(0, err.Error())
+	}
+
+	return converted, nil
+}
+
+// toJSONCompatible converts decoded YAML values into the types produced when decoding JSON
+func toJSONCompatible(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			converted, err := toJSONCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			convertedItem, err := toJSONCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[fmt.Sprintf("%v", key)] = convertedItem
+		}
+		return converted, nil
+
+	case []interface{}:
+		for i, item := range v {
+			converted, err := toJSONCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+
+	case nil, bool, string:
+		return v, nil
+	case int:
+		return json.Number(strconv.Itoa(v)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10)), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("non-finite numbers (%v) are not supported", v)
+		}
+		return json.Number(strconv.FormatFloat(v, 'f', -1, 64)), nil
+
+	default:
+		// round trip anything else (i.e. timestamps) through JSON
+		valueBytes, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var converted interface{}
+		decoder := json.NewDecoder(bytes.NewReader(valueBytes))
+		decoder.UseNumber()
+		err = decoder.Decode(&converted)
+		return converted, err
+	}
+}
+
+// toYAMLCompatible converts the numbers kept as they were provided (json.Number) into integers or
+// floats, as the YAML encoder would otherwise output them as quoted strings
+func toYAMLCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[key] = toYAMLCompatible(item)
+		}
+		return converted
+
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = toYAMLCompatible(item)
+		}
+		return converted
+
+	case json.Number:
+		if integer, err := v.Int64(); err == nil {
+			return integer
+		}
+		if float, err := v.Float64(); err == nil {
+			return float
+		}
+		return v.String()
+
+	default:
+		return v
+	}
+}
+
+// newStructuredSyntaxError returns a FieldValidationError for a json/yaml value that could not be parsed
+func newStructuredSyntaxError(line int, reason string) *FieldValidationError {
+	if line > 0 {
+		return &FieldValidationError{Line: line, Message: fmt.Sprintf("line %d: %s", line, reason)}
+	}
+	return &FieldValidationError{Message: reason}
+}
+
+// schemaValidationErrorToFieldValidationError converts the most specific cause of a JSON Schema
+// validation error into a FieldValidationError, looking up the line of the offending value.
+func schemaValidationErrorToFieldValidationError(label, raw string, schemaValidationError *jsonschema.ValidationError) *FieldValidationError {
+
+	// the leaf causes hold the most specific reason(s) for the failure
+	cause := schemaValidationError
+	for len(cause.Causes) > 0 {
+		cause = cause.Causes[0]
+	}
+
+	instanceLocation := cause.InstanceLocation
+	if instanceLocation == "" {
+		instanceLocation = "/"
+	}
+
+	line := lineOfInstanceLocation(raw, cause.InstanceLocation)
+	if line > 0 {
+		return &FieldValidationError{
+			Label:   label,
+			Line:    line,
+			Message: fmt.Sprintf("line %d: %s (at %s)", line, cause.Message, instanceLocation),
+		}
+	}
+
+	return &FieldValidationError{
+		Label:   label,
+		Message: fmt.Sprintf("%s (at %s)", cause.Message, instanceLocation),
+	}
+}
+
+// lineAtOffset returns the (1-indexed) line number of the given byte offset within the text
+func lineAtOffset(text string, offset int64) int {
+	if offset > int64(len(text)) {
+		offset = int64(len(text))
+	}
+	return strings.Count(text[:offset], "\n") + 1
+}
+
+// lineOfInstanceLocation returns the line number of the value found at the given JSON pointer
+// within the raw json/yaml document. It returns 0 if the line can't be determined.
+func lineOfInstanceLocation(raw, instanceLocation string) int {
+	var document yamlv3.Node
+
+	// JSON is (for our purposes) a subset of YAML, so the YAML parser can be
+	// used to find where values are located in both formats
+	err := yamlv3.Unmarshal([]byte(raw), &document)
+	if err != nil || len(document.Content) == 0 {
+		return 0
+	}
+
+	node := document.Content[0]
+	for _, token := range strings.Split(strings.TrimPrefix(instanceLocation, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			var found *yamlv3.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == token {
+					found = node.Content[i+1]
+					break
+				}
+			}
+			if found == nil {
+				return node.Line
+			}
+			node = found
+
+		case yamlv3.SequenceNode:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return node.Line
+			}
+			node = node.Content[index]
+
+		default:
+			return node.Line
+		}
+	}
+
+	return node.Line
+}
@@ -0,0 +1,340 @@
+package fields
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+)
+
+// FieldValidationError is returned when a value submitted for a field does not satisfy
+// the field's definition. It holds a human-friendly message that can be shown to the user.
+type FieldValidationError struct {
+
+	// Label is the label of the field the value was submitted for
+	Label string
+
+	// Message is the human-friendly reason the value was rejected
+	Message string
+
+	// Line is the line of the submitted value the issue was found on (0 when not applicable)
+	Line int
+}
+
+// Error returns the human-friendly reason the value was rejected
+func (e *FieldValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Label, e.Message)
+}
+
+// Unwrap allows the error to be matched against ErrInvalidFieldValueProvided
+func (e *FieldValidationError) Unwrap() error {
+	return errors.ErrInvalidFieldValueProvided
+}
+
+// newFieldValidationError returns a FieldValidationError for the given label with a formatted message
+func newFieldValidationError(label, format string, args ...any) *FieldValidationError {
+	return &FieldValidationError{
+		Label:   label,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// NormaliseValues validates the value(s) collected outside of the portal for the field against
+// its definition, returning the value(s) to output
+func (f *Field) NormaliseValues(values []string) ([]string, error) {
+
+	switch {
+	case f.IsGroup():
+		normalisedValue, err := f.NormaliseGroupValue(strings.Join(values, ","))
+		if err != nil {
+			return nil, err
+		}
+		return []string{normalisedValue}, nil
+
+	case f.IsStructured():
+		normalisedValue, err := f.NormaliseStructuredValue(strings.Join(values, ","))
+		if err != nil {
+			return nil, err
+		}
+		return []string{normalisedValue}, nil
+	}
+
+	// drop empty values so that they are treated as not provided
+	providedValues := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			providedValues = append(providedValues, value)
+		}
+	}
+
+	switch f.Properties.Type {
+	case "multiselect":
+		if f.Properties.Required && len(providedValues) == 0 {
+			return nil, newFieldValidationError(f.Label, "at least one choice is required")
+		}
+
+		choices, err := f.Properties.GetChoices()
+		if err != nil {
+			return nil, newFieldValidationError(f.Label, "choices could not be loaded")
+		}
+		for _, value := range providedValues {
+			if !toolbox.StringInSlice(value, choices) {
+				return nil, newFieldValidationError(f.Label, "'%s' is not one of: %s", value, strings.Join(choices, ", "))
+			}
+		}
+		return providedValues, nil
+
+	case "file", "multifile":
+		if f.Properties.Required && len(providedValues) == 0 {
+			return nil, newFieldValidationError(f.Label, "at least one file is required")
+		}
+		if f.Properties.Type == "file" && len(providedValues) > 1 {
+			return nil, newFieldValidationError(f.Label, "only one file can be provided")
+		}
+		if maxFiles := f.Properties.GetMaxFiles(); maxFiles > 0 && len(providedValues) > maxFiles {
+			return nil, newFieldValidationError(f.Label, "at most %d files can be provided", maxFiles)
+		}
+		return providedValues, nil
+	}
+
+	if len(providedValues) > 1 {
+		return nil, newFieldValidationError(f.Label, "must be a single value")
+	}
+
+	var value string
+	if len(providedValues) == 1 {
+		value = providedValues[0]
+	}
+
+	err := f.Properties.validateScalarValue(value)
+	if err != nil {
+		return nil, newFieldValidationError(f.Label, "%s", err.Error())
+	}
+
+	return []string{value}, nil
+}
+
+// NormaliseGroupValue validates the rows (a JSON array of objects) submitted for a group (table) field,
+// returning them with typed number and boolean cells and empty cells as null
+func (f *Field) NormaliseGroupValue(raw string) (string, error) {
+	var submittedRows []map[string]interface{}
+
+	if strings.TrimSpace(raw) != "" {
+		err := json.Unmarshal([]byte(raw), &submittedRows)
+		if err != nil {
+			return "", newFieldValidationError(f.Label, "rows must be a JSON array of objects")
+		}
+	}
+
+	if f.Properties.Required && len(submittedRows) == 0 {
+		return "", newFieldValidationError(f.Label, "at least one row is required")
+	}
+
+	if len(submittedRows) < f.Properties.MinRows {
+		return "", newFieldValidationError(f.Label, "at least %d row(s) are required, got %d", f.Properties.MinRows, len(submittedRows))
+	}
+
+	if f.Properties.MaxRows > 0 && len(submittedRows) > f.Properties.MaxRows {
+		return "", newFieldValidationError(f.Label, "at most %d row(s) are allowed, got %d", f.Properties.MaxRows, len(submittedRows))
+	}
+
+	normalisedRows := make([]map[string]interface{}, 0, len(submittedRows))
+	for rowIndex, submittedRow := range submittedRows {
+		normalisedRow := make(map[string]interface{}, len(f.Properties.SubFields))
+
+		for key := range submittedRow {
+			if !f.hasSubField(key) {
+				return "", newFieldValidationError(f.Label, "row %d: unknown column '%s'", rowIndex+1, key)
+			}
+		}
+
+		for _, subField := range f.Properties.SubFields {
+			value := stringifyGroupCellValue(submittedRow[subField.Label])
+
+			err := subField.Properties.validateScalarValue(value)
+			if err != nil {
+				return "", newFieldValidationError(f.Label, "row %d: %s %s", rowIndex+1, subField.Label, err.Error())
+			}
+
+			normalisedRow[subField.Label] = typedGroupCellValue(subField.Properties.Type, value)
+		}
+
+		normalisedRows = append(normalisedRows, normalisedRow)
+	}
+
+	normalisedRowsBytes, err := json.Marshal(normalisedRows)
+	if err != nil {
+		return "", err
+	}
+
+	return string(normalisedRowsBytes), nil
+}
+
+// GroupRowDefaults returns a JSON object keyed by the sub-field labels of a group (table) field
+// holding their default values. It is used by the portal to seed newly added rows.
+func (f *Field) GroupRowDefaults() string {
+	rowDefaults := make(map[string]string, len(f.Properties.SubFields))
+	for _, subField := range f.Properties.SubFields {
+		rowDefaults[subField.Label] = subField.Properties.DefaultValue
+	}
+
+	rowDefaultsBytes, err := json.Marshal(rowDefaults)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(rowDefaultsBytes)
+}
+
+// SecretValues returns the values that must be masked in the logs out of those submitted for the
+// field, including the cells of secret sub-fields
+func (f *Field) SecretValues(values []string) []string {
+	var secretValues []string = []string{}
+
+	for _, value := range values {
+		if f.Properties.Secret {
+			secretValues = appendSecretValue(secretValues, value)
+		}
+
+		if !f.IsGroup() {
+			continue
+		}
+
+		var rows []map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &rows); err != nil {
+			continue
+		}
+
+		for _, row := range rows {
+			for _, subField := range f.Properties.SubFields {
+				if (!f.Properties.Secret && !subField.Properties.Secret) || subField.Properties.Type == "boolean" {
+					continue
+				}
+				secretValues = appendSecretValue(secretValues, stringifyGroupCellValue(row[subField.Label]))
+			}
+		}
+	}
+
+	return secretValues
+}
+
+// appendSecretValue appends the value, and each of its lines when it has more than one, to the
+// secret values, skipping those that are empty
+func appendSecretValue(secretValues []string, value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return secretValues
+	}
+
+	secretValues = append(secretValues, value)
+
+	if lines := strings.Split(value, "\n"); len(lines) > 1 {
+		for _, line := range lines {
+			if line = strings.TrimSpace(line); line != "" {
+				secretValues = append(secretValues, line)
+			}
+		}
+	}
+
+	return secretValues
+}
+
+// hasSubField returns whether the group (table) field has a sub-field with the given label
+func (f *Field) hasSubField(label string) bool {
+	for _, subField := range f.Properties.SubFields {
+		if subField.Label == label {
+			return true
+		}
+	}
+	return false
+}
+
+// validateScalarValue checks the value against the properties of a single value field,
+// returning an error phrased to follow the field's name, i.e. "is required"
+func (fp *FieldProperties) validateScalarValue(value string) error {
+
+	if value == "" {
+		if fp.Required {
+			return fmt.Errorf("is required")
+		}
+		return nil
+	}
+
+	switch fp.Type {
+	case "text", "textarea":
+		if fp.MaxLength > 0 && utf8.RuneCountInString(value) > fp.MaxLength {
+			return fmt.Errorf("must be at most %d characters long", fp.MaxLength)
+		}
+
+	case "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return fmt.Errorf("must be a number")
+		}
+
+		// the portal only applies the limits when they are set (non-zero)
+		if fp.NumberMin != 0 && number < float64(fp.NumberMin) {
+			return fmt.Errorf("must be at least %d", fp.NumberMin)
+		}
+		if fp.NumberMax != 0 && number > float64(fp.NumberMax) {
+			return fmt.Errorf("must be at most %d", fp.NumberMax)
+		}
+
+	case "boolean":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("must be either true or false")
+		}
+
+	case "select":
+		choices, err := fp.GetChoices()
+		if err != nil {
+			return fmt.Errorf("choices could not be loaded")
+		}
+		if !toolbox.StringInSlice(value, choices) {
+			return fmt.Errorf("must be one of: %s", strings.Join(choices, ", "))
+		}
+	}
+
+	return nil
+}
+
+// stringifyGroupCellValue converts a decoded JSON cell value into its string representation
+func stringifyGroupCellValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// typedGroupCellValue converts a validated cell value into the JSON type matching its sub-field type,
+// empty cells are null whatever their sub-field type
+func typedGroupCellValue(subFieldType, value string) interface{} {
+	if value == "" {
+		return nil
+	}
+
+	switch subFieldType {
+	case "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil
+		}
+		return json.Number(strconv.FormatFloat(number, 'f', -1, 64))
+	case "boolean":
+		return value == "true"
+	default:
+		return value
+	}
+}
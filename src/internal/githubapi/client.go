@@ -0,0 +1,290 @@
+package githubapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseUrl is the base URL of the GitHub API on github.com
+	DefaultBaseUrl = "https://api.github.com"
+
+	// apiVersion is the version of the GitHub REST API requests are made against
+	apiVersion = "2022-11-28"
+
+	// requestTimeout is how long a request to the GitHub API can take
+	requestTimeout = 30 * time.Second
+)
+
+// NewClientRequest holds everything needed to create a GitHub API client
+type NewClientRequest struct {
+
+	// BaseUrl is the base URL of the GitHub API (default: DefaultBaseUrl)
+	BaseUrl string
+
+	// Token is the token requests are authenticated with
+	Token string
+}
+
+// Client makes requests to the GitHub REST API
+type Client struct {
+
+	// baseUrl is the base URL of the GitHub API
+	baseUrl string
+
+	// token is the token requests are authenticated with
+	token string
+
+	// httpClient is the client requests are made with
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the GitHub REST API
+func NewClient(r *NewClientRequest) *Client {
+	baseUrl := strings.TrimSuffix(r.BaseUrl, "/")
+	if baseUrl == "" {
+		baseUrl = DefaultBaseUrl
+	}
+
+	return &Client{
+		baseUrl:    baseUrl,
+		token:      r.Token,
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// CancelWorkflowRun requests the cancellation of the workflow run in the repository (i.e. owner/repo)
+func (c *Client) CancelWorkflowRun(ctx context.Context, repository string, runId int64) error {
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/actions/runs/%d/cancel", repository, runId), nil, nil)
+}
+
+// do makes a request to the GitHub API, encoding the body and decoding the response into
+// the result when they are provided
+func (c *Client) do(ctx context.Context, method, path string, body any, result any) error {
+	var requestBody io.Reader
+	if body != nil {
+		bodyJson, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewReader(bodyJson)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.baseUrl+path, requestBody)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("Authorization", "Bearer "+c.token)
+	request.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		var apiError struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&apiError)
+
+		return fmt.Errorf("the GitHub API responded to %s %s with status %d: %s", method, path, response.StatusCode, apiError.Message)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// WorkflowJob is a job within a workflow run
+type WorkflowJob struct {
+
+	// Id is the ID of the job
+	Id int64 `json:"id"`
+
+	// Name is the name of the job, including its matrix values (i.e. "build (ubuntu-latest)")
+	Name string `json:"name"`
+
+	// Status is the status of the job, i.e. in_progress
+	Status string `json:"status"`
+
+	// RunnerName is the name of the runner the job is running on
+	RunnerName string `json:"runner_name"`
+
+	// HtmlUrl is the URL of the job on GitHub
+	HtmlUrl string `json:"html_url"`
+}
+
+// ListWorkflowRunJobs returns the jobs of the latest attempt of the workflow run in the repository
+func (c *Client) ListWorkflowRunJobs(ctx context.Context, repository string, runId int64) ([]WorkflowJob, error) {
+	var jobs []WorkflowJob
+
+	for page := 1; ; page++ {
+		var response struct {
+			TotalCount int           `json:"total_count"`
+			Jobs       []WorkflowJob `json:"jobs"`
+		}
+
+		err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/actions/runs/%d/jobs?filter=latest&per_page=100&page=%d", repository, runId, page), nil, &response)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, response.Jobs...)
+		if len(response.Jobs) == 0 || len(jobs) >= response.TotalCount {
+			return jobs, nil
+		}
+	}
+}
+
+// Repository is a GitHub repository
+type Repository struct {
+
+	// FullName is the name of the repository, including its owner (i.e. owner/repo)
+	FullName string `json:"full_name"`
+
+	// Private is whether the repository is private, which internal repositories are too
+	Private bool `json:"private"`
+}
+
+// GetRepository returns the repository (i.e. owner/repo), failing when the token can't access it
+func (c *Client) GetRepository(ctx context.Context, repository string) (*Repository, error) {
+	var result Repository
+
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s", repository), nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// PullRequest is a pull request within a repository
+type PullRequest struct {
+
+	// Number is the number of the pull request
+	Number int `json:"number"`
+
+	// State is the state of the pull request, i.e. open
+	State string `json:"state"`
+}
+
+// ListPullRequestsAssociatedWithCommit returns the pull requests of the repository the commit is part of
+func (c *Client) ListPullRequestsAssociatedWithCommit(ctx context.Context, repository, sha string) ([]PullRequest, error) {
+	var pullRequests []PullRequest
+
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/commits/%s/pulls", repository, sha), nil, &pullRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	return pullRequests, nil
+}
+
+// IssueComment is a comment on an issue or pull request
+type IssueComment struct {
+
+	// Id is the ID of the comment
+	Id int64 `json:"id"`
+
+	// HtmlUrl is the URL of the comment on GitHub
+	HtmlUrl string `json:"html_url"`
+
+	// Body is the markdown of the comment
+	Body string `json:"body"`
+
+	// User is who made the comment
+	User User `json:"user"`
+
+	// AuthorAssociation is how the commenter is associated with the repository, i.e. OWNER,
+	// MEMBER or COLLABORATOR
+	AuthorAssociation string `json:"author_association"`
+
+	// CreatedAt is when the comment was made
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the comment was last edited, or made when it hasn't been
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// User is a GitHub user or bot
+type User struct {
+
+	// Login is the username of the user
+	Login string `json:"login"`
+
+	// Type is the type of the user, i.e. User or Bot
+	Type string `json:"type"`
+}
+
+// ListIssueComments returns the comments on the issue or pull request of the repository with the
+// given number that have been made or updated since the given time, oldest first
+func (c *Client) ListIssueComments(ctx context.Context, repository string, number int, since time.Time) ([]IssueComment, error) {
+	var comments []IssueComment
+
+	for page := 1; ; page++ {
+		var pageComments []IssueComment
+
+		err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/issues/%d/comments?since=%s&per_page=100&page=%d", repository, number, since.UTC().Format(time.RFC3339), page), nil, &pageComments)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, pageComments...)
+		if len(pageComments) < 100 {
+			return comments, nil
+		}
+	}
+}
+
+// CreateIssueComment comments on the issue or pull request of the repository with the given number
+func (c *Client) CreateIssueComment(ctx context.Context, repository string, number int, body string) (*IssueComment, error) {
+	var result IssueComment
+
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", repository, number), map[string]string{"body": body}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateIssueComment replaces the body of the comment with the given ID
+func (c *Client) UpdateIssueComment(ctx context.Context, repository string, commentId int64, body string) error {
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", repository, commentId), map[string]string{"body": body}, nil)
+}
+
+// CommitStatus is the status of a commit under a context
+type CommitStatus struct {
+
+	// State is the state of the status, i.e. pending, success or failure
+	State string `json:"state"`
+
+	// TargetUrl is the URL the status links to
+	TargetUrl string `json:"target_url,omitempty"`
+
+	// Description is a short description of the status, which GitHub limits to 140 characters
+	Description string `json:"description,omitempty"`
+
+	// Context identifies the status, statuses with the same context replace each other
+	Context string `json:"context"`
+}
+
+// CreateCommitStatus sets the status of the commit of the repository
+func (c *Client) CreateCommitStatus(ctx context.Context, repository, sha string, status CommitStatus) error {
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/statuses/%s", repository, sha), status, nil)
+}
@@ -0,0 +1,78 @@
+package githubapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+)
+
+func TestClient_CancelWorkflowRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		delay         time.Duration
+		expectedError string
+	}{
+		{
+			name:   "cancellation accepted",
+			status: http.StatusAccepted,
+		},
+		{
+			name:          "run that can't be cancelled",
+			status:        http.StatusConflict,
+			body:          `{"message":"Cannot cancel a workflow run that is completed."}`,
+			expectedError: "the GitHub API responded to POST /repos/owner/repo/actions/runs/42/cancel with status 409: Cannot cancel a workflow run that is completed.",
+		},
+		{
+			name:          "request outlasting the context",
+			status:        http.StatusAccepted,
+			delay:         time.Second,
+			expectedError: context.DeadlineExceeded.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				select {
+				case <-time.After(tt.delay):
+				case <-r.Context().Done():
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+
+			client := githubapi.NewClient(&githubapi.NewClientRequest{BaseUrl: server.URL + "/", Token: "token"})
+
+			ctx, ctxCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer ctxCancel()
+
+			err := client.CancelWorkflowRun(ctx, "owner/repo", 42)
+
+			// closing the server waits for the request to be handled
+			server.Close()
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if assert.NotNil(t, request) {
+				assert.Equal(t, http.MethodPost, request.Method)
+				assert.Equal(t, "/repos/owner/repo/actions/runs/42/cancel", request.URL.Path)
+				assert.Equal(t, "Bearer token", request.Header.Get("Authorization"))
+				assert.Equal(t, "application/vnd.github+json", request.Header.Get("Accept"))
+			}
+		})
+	}
+}
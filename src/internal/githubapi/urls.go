@@ -0,0 +1,75 @@
+package githubapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sethvargo/go-githubactions"
+)
+
+// RunUrl returns the URL of the workflow run the action is running in
+func RunUrl(actionContext *githubactions.GitHubContext) string {
+	return fmt.Sprintf("%s/%s/actions/runs/%d", actionContext.ServerURL, actionContext.Repository, actionContext.RunID)
+}
+
+// JobOrRunUrl returns the URL of the job the action is running in, as resolved by
+// ResolveJobUrl, or the URL of its run when the job's URL isn't known
+func JobOrRunUrl(jobUrl string, actionContext *githubactions.GitHubContext) string {
+	if jobUrl != "" {
+		return jobUrl
+	}
+
+	return RunUrl(actionContext)
+}
+
+// ResolveJobUrl returns the URL of the job the action is running in, falling back to the
+// URL of the run when the job can't be found
+func (c *Client) ResolveJobUrl(ctx context.Context, actionContext *githubactions.GitHubContext, runnerName string) (string, error) {
+	runUrl := RunUrl(actionContext)
+
+	jobs, err := c.ListWorkflowRunJobs(ctx, actionContext.Repository, actionContext.RunID)
+	if err != nil {
+		return runUrl, err
+	}
+
+	job := findCurrentJob(jobs, runnerName, actionContext.Job)
+	if job == nil {
+		return runUrl, fmt.Errorf("no in progress job of run %d matches runner %q and job %q", actionContext.RunID, runnerName, actionContext.Job)
+	}
+
+	if job.HtmlUrl != "" {
+		return job.HtmlUrl, nil
+	}
+
+	return fmt.Sprintf("%s/job/%d", runUrl, job.Id), nil
+}
+
+// findCurrentJob returns the in progress job running on the runner, or when more than one is,
+// the one whose name matches the job's name (or ID), or nil when there's no single match
+func findCurrentJob(jobs []WorkflowJob, runnerName, jobName string) *WorkflowJob {
+	var candidates []*WorkflowJob
+	for i := range jobs {
+		if jobs[i].Status == "in_progress" && (runnerName == "" || jobs[i].RunnerName == runnerName) {
+			candidates = append(candidates, &jobs[i])
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	var matches []*WorkflowJob
+	for _, candidate := range candidates {
+		// matrix jobs are named after the job followed by their matrix values, i.e. "build (ubuntu-latest)"
+		if candidate.Name == jobName || strings.HasPrefix(candidate.Name, jobName+" (") {
+			matches = append(matches, candidate)
+		}
+	}
+
+	if len(matches) == 1 {
+		return matches[0]
+	}
+
+	return nil
+}
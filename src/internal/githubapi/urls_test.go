@@ -0,0 +1,166 @@
+package githubapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+)
+
+const testRunUrl = "https://github.com/owner/repo/actions/runs/42"
+
+func newTestActionContext() *githubactions.GitHubContext {
+	return &githubactions.GitHubContext{
+		ServerURL:  "https://github.com",
+		Repository: "owner/repo",
+		RunID:      42,
+		Job:        "build",
+	}
+}
+
+// newJobsServer returns a server responding to requests for the jobs of run 42 of owner/repo
+// with the pages of jobs, or with the status when it isn't http.StatusOK
+func newJobsServer(t *testing.T, status int, pages ...[]githubapi.WorkflowJob) *httptest.Server {
+	t.Helper()
+
+	var totalCount int
+	for _, page := range pages {
+		totalCount += len(page)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/actions/runs/42/jobs" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"message":"Server Error"}`))
+			return
+		}
+
+		var jobs []githubapi.WorkflowJob
+		if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page >= 1 && page <= len(pages) {
+			jobs = pages[page-1]
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"total_count": totalCount, "jobs": jobs})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestJobOrRunUrl(t *testing.T) {
+	assert.Equal(t, "https://github.com/owner/repo/actions/runs/42/job/7", githubapi.JobOrRunUrl("https://github.com/owner/repo/actions/runs/42/job/7", newTestActionContext()))
+	assert.Equal(t, testRunUrl, githubapi.JobOrRunUrl("", newTestActionContext()))
+}
+
+func TestClient_ResolveJobUrl(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		pages      [][]githubapi.WorkflowJob
+		runnerName string
+		wantUrl    string
+		wantErr    bool
+	}{
+		{
+			name:   "the only in progress job on the runner",
+			status: http.StatusOK,
+			pages: [][]githubapi.WorkflowJob{{
+				{Id: 1, Name: "build", Status: "in_progress", RunnerName: "runner-1", HtmlUrl: testRunUrl + "/job/1"},
+				{Id: 2, Name: "build", Status: "in_progress", RunnerName: "runner-2", HtmlUrl: testRunUrl + "/job/2"},
+				{Id: 3, Name: "build", Status: "completed", RunnerName: "runner-2", HtmlUrl: testRunUrl + "/job/3"},
+			}},
+			runnerName: "runner-2",
+			wantUrl:    testRunUrl + "/job/2",
+		},
+		{
+			name:   "the job is matched on its name when the runner isn't known",
+			status: http.StatusOK,
+			pages: [][]githubapi.WorkflowJob{{
+				{Id: 1, Name: "lint", Status: "in_progress", RunnerName: "runner-1", HtmlUrl: testRunUrl + "/job/1"},
+				{Id: 2, Name: "build", Status: "in_progress", RunnerName: "runner-2", HtmlUrl: testRunUrl + "/job/2"},
+			}},
+			wantUrl: testRunUrl + "/job/2",
+		},
+		{
+			name:   "matrix jobs are matched on their name's prefix",
+			status: http.StatusOK,
+			pages: [][]githubapi.WorkflowJob{{
+				{Id: 1, Name: "lint", Status: "in_progress", RunnerName: "runner-1", HtmlUrl: testRunUrl + "/job/1"},
+				{Id: 2, Name: "build (ubuntu-latest)", Status: "in_progress", RunnerName: "runner-2", HtmlUrl: testRunUrl + "/job/2"},
+			}},
+			wantUrl: testRunUrl + "/job/2",
+		},
+		{
+			name:   "jobs on later pages are found",
+			status: http.StatusOK,
+			pages: [][]githubapi.WorkflowJob{
+				{{Id: 1, Name: "lint", Status: "completed", RunnerName: "runner-1", HtmlUrl: testRunUrl + "/job/1"}},
+				{{Id: 2, Name: "build", Status: "in_progress", RunnerName: "runner-2", HtmlUrl: testRunUrl + "/job/2"}},
+			},
+			runnerName: "runner-2",
+			wantUrl:    testRunUrl + "/job/2",
+		},
+		{
+			name:   "the job's URL is built from its ID when it isn't provided",
+			status: http.StatusOK,
+			pages: [][]githubapi.WorkflowJob{{
+				{Id: 2, Name: "build", Status: "in_progress", RunnerName: "runner-2"},
+			}},
+			runnerName: "runner-2",
+			wantUrl:    testRunUrl + "/job/2",
+		},
+		{
+			name:   "more than one matching job falls back to the run",
+			status: http.StatusOK,
+			pages: [][]githubapi.WorkflowJob{{
+				{Id: 1, Name: "build (ubuntu-latest)", Status: "in_progress", RunnerName: "runner-1", HtmlUrl: testRunUrl + "/job/1"},
+				{Id: 2, Name: "build (windows-latest)", Status: "in_progress", RunnerName: "runner-2", HtmlUrl: testRunUrl + "/job/2"},
+			}},
+			wantUrl: testRunUrl,
+			wantErr: true,
+		},
+		{
+			name:   "no in progress job on the runner falls back to the run",
+			status: http.StatusOK,
+			pages: [][]githubapi.WorkflowJob{{
+				{Id: 1, Name: "build", Status: "completed", RunnerName: "runner-1", HtmlUrl: testRunUrl + "/job/1"},
+			}},
+			runnerName: "runner-1",
+			wantUrl:    testRunUrl,
+			wantErr:    true,
+		},
+		{
+			name:    "an API error falls back to the run",
+			status:  http.StatusInternalServerError,
+			wantUrl: testRunUrl,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newJobsServer(t, tt.status, tt.pages...)
+			client := githubapi.NewClient(&githubapi.NewClientRequest{BaseUrl: server.URL, Token: "token"})
+
+			jobUrl, err := client.ResolveJobUrl(context.Background(), newTestActionContext(), tt.runnerName)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantUrl, jobUrl)
+		})
+	}
+}
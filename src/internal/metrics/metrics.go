@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentType is the content type of the Prometheus text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// notifierSendDurationBuckets are the upper bounds, in seconds, of the notifier send latency histogram
+var notifierSendDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// NewCollectorRequest holds everything needed to create a metrics collector
+type NewCollectorRequest struct {
+
+	// StartedAt is when the portal started, which the time to the first page view and
+	// to submit are measured from (default: now)
+	StartedAt time.Time
+}
+
+// Collector collects the portal's metrics and serves them in the Prometheus text exposition format
+type Collector struct {
+
+	// startedAt is when the portal started
+	startedAt time.Time
+
+	// firstPageViewAfter is how long after the portal started its page was first viewed, 0 until it is
+	firstPageViewAfter time.Duration
+
+	// submittedAfter is how long after the portal started the inputs were submitted, 0 until they are
+	submittedAfter time.Duration
+
+	// pageViews is how many times the portal's page has been viewed
+	pageViews int
+
+	// uploads is how many uploads have been stored
+	uploads int
+
+	// uploadedFiles is how many files have been stored
+	uploadedFiles int
+
+	// uploadedBytes is how many bytes of files have been stored
+	uploadedBytes int64
+
+	// errors is how many error responses the portal has sent, keyed by their error key
+	errors map[string]int
+
+	// notifierSends holds the latency of the messages sent with each notifier, keyed by the notifier's name
+	notifierSends map[string]*histogram
+
+	// notifierFailures is how many messages failed to send with each notifier, keyed by the notifier's name
+	notifierFailures map[string]int
+
+	// mutex guards the collected metrics
+	mutex sync.Mutex
+}
+
+// histogram counts observations into cumulative buckets
+type histogram struct {
+
+	// bucketCounts holds how many observations fell within each of notifierSendDurationBuckets
+	bucketCounts []int
+
+	// count is how many observations were made
+	count int
+
+	// sum is the sum of the observations
+	sum float64
+}
+
+// NewCollector returns a collector for the portal's metrics
+func NewCollector(r *NewCollectorRequest) *Collector {
+	startedAt := r.StartedAt
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+
+	return &Collector{
+		startedAt:        startedAt,
+		errors:           make(map[string]int),
+		notifierSends:    make(map[string]*histogram),
+		notifierFailures: make(map[string]int),
+	}
+}
+
+// ObservePageView counts a view of the portal's page, recording when it was first viewed
+func (c *Collector) ObservePageView() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.pageViews++
+	if c.firstPageViewAfter == 0 {
+		c.firstPageViewAfter = time.Since(c.startedAt)
+	}
+}
+
+// ObserveSubmit records when the inputs were submitted
+func (c *Collector) ObserveSubmit() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.submittedAfter == 0 {
+		c.submittedAfter = time.Since(c.startedAt)
+	}
+}
+
+// ObserveUpload counts an upload of the given number of files and bytes
+func (c *Collector) ObserveUpload(files int, bytes int64) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.uploads++
+	c.uploadedFiles += files
+	c.uploadedBytes += bytes
+}
+
+// ObserveError counts an error response sent for the error key
+func (c *Collector) ObserveError(errKey string) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.errors[errKey]++
+}
+
+// ObserveNotifierSend records how long sending a message with the notifier took, and whether it failed
+func (c *Collector) ObserveNotifierSend(notifier string, duration time.Duration, err error) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	sends, ok := c.notifierSends[notifier]
+	if !ok {
+		sends = &histogram{bucketCounts: make([]int, len(notifierSendDurationBuckets))}
+		c.notifierSends[notifier] = sends
+	}
+
+	seconds := duration.Seconds()
+	for i, upperBound := range notifierSendDurationBuckets {
+		if seconds <= upperBound {
+			sends.bucketCounts[i]++
+		}
+	}
+	sends.count++
+	sends.sum += seconds
+
+	if err != nil {
+		c.notifierFailures[notifier]++
+	}
+}
+
+// ServeHTTP serves the collected metrics in the Prometheus text exposition format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	c.WriteTo(w)
+}
+
+// WriteTo writes the collected metrics in the Prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var b strings.Builder
+
+	writeHeader(&b, "iaip_portal_page_views_total", "counter", "How many times the portal's page has been viewed.")
+	fmt.Fprintf(&b, "iaip_portal_page_views_total %d\n", c.pageViews)
+
+	if c.firstPageViewAfter > 0 {
+		writeHeader(&b, "iaip_portal_time_to_first_page_view_seconds", "gauge", "How long after the portal started its page was first viewed.")
+		fmt.Fprintf(&b, "iaip_portal_time_to_first_page_view_seconds %s\n", formatFloat(c.firstPageViewAfter.Seconds()))
+	}
+
+	if c.submittedAfter > 0 {
+		writeHeader(&b, "iaip_portal_time_to_submit_seconds", "gauge", "How long after the portal started the inputs were submitted.")
+		fmt.Fprintf(&b, "iaip_portal_time_to_submit_seconds %s\n", formatFloat(c.submittedAfter.Seconds()))
+	}
+
+	writeHeader(&b, "iaip_portal_uploads_total", "counter", "How many uploads have been stored.")
+	fmt.Fprintf(&b, "iaip_portal_uploads_total %d\n", c.uploads)
+
+	writeHeader(&b, "iaip_portal_uploaded_files_total", "counter", "How many files have been stored.")
+	fmt.Fprintf(&b, "iaip_portal_uploaded_files_total %d\n", c.uploadedFiles)
+
+	writeHeader(&b, "iaip_portal_uploaded_bytes_total", "counter", "How many bytes of files have been stored.")
+	fmt.Fprintf(&b, "iaip_portal_uploaded_bytes_total %d\n", c.uploadedBytes)
+
+	writeHeader(&b, "iaip_portal_errors_total", "counter", "How many error responses the portal has sent, by error.")
+	for _, errKey := range sortedKeys(c.errors) {
+		fmt.Fprintf(&b, "iaip_portal_errors_total{error=%q} %d\n", errKey, c.errors[errKey])
+	}
+
+	writeHeader(&b, "iaip_notifier_send_duration_seconds", "histogram", "How long sending a message with each notifier took.")
+	for _, notifier := range sortedKeys(c.notifierSends) {
+		sends := c.notifierSends[notifier]
+		for i, upperBound := range notifierSendDurationBuckets {
+			fmt.Fprintf(&b, "iaip_notifier_send_duration_seconds_bucket{notifier=%q,le=%q} %d\n", notifier, formatFloat(upperBound), sends.bucketCounts[i])
+		}
+		fmt.Fprintf(&b, "iaip_notifier_send_duration_seconds_bucket{notifier=%q,le=\"+Inf\"} %d\n", notifier, sends.count)
+		fmt.Fprintf(&b, "iaip_notifier_send_duration_seconds_sum{notifier=%q} %s\n", notifier, formatFloat(sends.sum))
+		fmt.Fprintf(&b, "iaip_notifier_send_duration_seconds_count{notifier=%q} %d\n", notifier, sends.count)
+	}
+
+	writeHeader(&b, "iaip_notifier_send_failures_total", "counter", "How many messages failed to send with each notifier.")
+	for _, notifier := range sortedKeys(c.notifierSends) {
+		fmt.Fprintf(&b, "iaip_notifier_send_failures_total{notifier=%q} %d\n", notifier, c.notifierFailures[notifier])
+	}
+
+	written, err := io.WriteString(w, b.String())
+	return int64(written), err
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric
+func writeHeader(b *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// formatFloat formats the value the way Prometheus expects
+func formatFloat(value float64) string {
+	return fmt.Sprintf("%g", value)
+}
+
+// sortedKeys returns the keys of the map in order, so the metrics are always written the same way
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,192 @@
+package metrics_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/boasihq/interactive-inputs/internal/metrics"
+)
+
+// writeMetrics returns the collected metrics in the Prometheus text exposition format
+func writeMetrics(t *testing.T, collector *metrics.Collector) string {
+	t.Helper()
+
+	var b bytes.Buffer
+	written, err := collector.WriteTo(&b)
+	require.NoError(t, err)
+	assert.Equal(t, int64(b.Len()), written)
+
+	return b.String()
+}
+
+// gaugeValue returns the value of the gauge in the exposition, and whether it was written
+func gaugeValue(t *testing.T, exposition, name string) (float64, bool) {
+	t.Helper()
+
+	match := regexp.MustCompile(`(?m)^` + name + ` (\S+)$`).FindStringSubmatch(exposition)
+	if match == nil {
+		return 0, false
+	}
+
+	value, err := strconv.ParseFloat(match[1], 64)
+	require.NoError(t, err)
+
+	return value, true
+}
+
+func TestCollector_Counters(t *testing.T) {
+	collector := metrics.NewCollector(&metrics.NewCollectorRequest{})
+
+	collector.ObservePageView()
+	collector.ObservePageView()
+	collector.ObserveUpload(2, 1024)
+	collector.ObserveUpload(1, 512)
+	collector.ObserveError("UploadTooLarge")
+	collector.ObserveError("AccessDenied")
+	collector.ObserveError("UploadTooLarge")
+
+	exposition := writeMetrics(t, collector)
+
+	for _, expectedLine := range []string{
+		"iaip_portal_page_views_total 2",
+		"iaip_portal_uploads_total 2",
+		"iaip_portal_uploaded_files_total 3",
+		"iaip_portal_uploaded_bytes_total 1536",
+		`iaip_portal_errors_total{error="AccessDenied"} 1`,
+		`iaip_portal_errors_total{error="UploadTooLarge"} 2`,
+	} {
+		assert.Contains(t, exposition, expectedLine+"\n")
+	}
+
+	// the labels are always written in the same order
+	assert.Less(t, strings.Index(exposition, `error="AccessDenied"`), strings.Index(exposition, `error="UploadTooLarge"`))
+}
+
+func TestCollector_Gauges(t *testing.T) {
+	startedAt := time.Now().Add(-2 * time.Minute)
+	collector := metrics.NewCollector(&metrics.NewCollectorRequest{StartedAt: startedAt})
+
+	// the gauges aren't written until they have been observed
+	exposition := writeMetrics(t, collector)
+	_, written := gaugeValue(t, exposition, "iaip_portal_time_to_first_page_view_seconds")
+	assert.False(t, written)
+	_, written = gaugeValue(t, exposition, "iaip_portal_time_to_submit_seconds")
+	assert.False(t, written)
+
+	collector.ObservePageView()
+	collector.ObserveSubmit()
+
+	exposition = writeMetrics(t, collector)
+	firstPageView, written := gaugeValue(t, exposition, "iaip_portal_time_to_first_page_view_seconds")
+	assert.True(t, written)
+	assert.InDelta(t, 120, firstPageView, 5)
+
+	submitted, written := gaugeValue(t, exposition, "iaip_portal_time_to_submit_seconds")
+	assert.True(t, written)
+	assert.InDelta(t, 120, submitted, 5)
+
+	// only the first page view and submit are recorded
+	time.Sleep(10 * time.Millisecond)
+	collector.ObservePageView()
+	collector.ObserveSubmit()
+
+	exposition = writeMetrics(t, collector)
+	laterFirstPageView, _ := gaugeValue(t, exposition, "iaip_portal_time_to_first_page_view_seconds")
+	laterSubmitted, _ := gaugeValue(t, exposition, "iaip_portal_time_to_submit_seconds")
+	assert.Equal(t, firstPageView, laterFirstPageView)
+	assert.Equal(t, submitted, laterSubmitted)
+}
+
+func TestCollector_NotifierSends(t *testing.T) {
+	collector := metrics.NewCollector(&metrics.NewCollectorRequest{})
+
+	collector.ObserveNotifierSend("slack", 200*time.Millisecond, nil)
+	collector.ObserveNotifierSend("slack", 3*time.Second, errors.New("failed"))
+	collector.ObserveNotifierSend("slack", 20*time.Second, nil)
+	collector.ObserveNotifierSend("discord", 50*time.Millisecond, nil)
+
+	exposition := writeMetrics(t, collector)
+
+	expected := `# HELP iaip_notifier_send_duration_seconds How long sending a message with each notifier took.
+# TYPE iaip_notifier_send_duration_seconds histogram
+iaip_notifier_send_duration_seconds_bucket{notifier="discord",le="0.1"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="discord",le="0.25"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="discord",le="0.5"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="discord",le="1"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="discord",le="2.5"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="discord",le="5"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="discord",le="10"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="discord",le="+Inf"} 1
+iaip_notifier_send_duration_seconds_sum{notifier="discord"} 0.05
+iaip_notifier_send_duration_seconds_count{notifier="discord"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="slack",le="0.1"} 0
+iaip_notifier_send_duration_seconds_bucket{notifier="slack",le="0.25"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="slack",le="0.5"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="slack",le="1"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="slack",le="2.5"} 1
+iaip_notifier_send_duration_seconds_bucket{notifier="slack",le="5"} 2
+iaip_notifier_send_duration_seconds_bucket{notifier="slack",le="10"} 2
+iaip_notifier_send_duration_seconds_bucket{notifier="slack",le="+Inf"} 3
+iaip_notifier_send_duration_seconds_sum{notifier="slack"} 23.2
+iaip_notifier_send_duration_seconds_count{notifier="slack"} 3
+# HELP iaip_notifier_send_failures_total How many messages failed to send with each notifier.
+# TYPE iaip_notifier_send_failures_total counter
+iaip_notifier_send_failures_total{notifier="discord"} 0
+iaip_notifier_send_failures_total{notifier="slack"} 1
+`
+	assert.True(t, strings.HasSuffix(exposition, expected), exposition)
+}
+
+func TestCollector_ServeHTTP(t *testing.T) {
+	collector := metrics.NewCollector(&metrics.NewCollectorRequest{})
+	collector.ObservePageView()
+
+	recorder := httptest.NewRecorder()
+	collector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	// every metric is described before its samples, and every sample is a name, optional labels and a value
+	sampleRegex := regexp.MustCompile(`^[a-z_]+(\{[a-z]+="[^"]*"(,[a-z]+="[^"]*")*\})? \S+$`)
+	var described []string
+	for _, line := range strings.Split(strings.TrimSuffix(recorder.Body.String(), "\n"), "\n") {
+		if strings.HasPrefix(line, "# HELP ") {
+			described = append(described, strings.Fields(line)[2])
+			continue
+		}
+		if strings.HasPrefix(line, "# TYPE ") {
+			assert.Equal(t, described[len(described)-1], strings.Fields(line)[2])
+			continue
+		}
+
+		assert.Regexp(t, sampleRegex, line)
+		require.NotEmpty(t, described)
+		assert.True(t, strings.HasPrefix(line, described[len(described)-1]), line)
+	}
+
+	assert.Contains(t, recorder.Body.String(), "iaip_portal_page_views_total 1\n")
+}
+
+func TestCollector_Nil(t *testing.T) {
+	var collector *metrics.Collector
+
+	// the portal's handlers observe their metrics without checking whether they are collected
+	assert.NotPanics(t, func() {
+		collector.ObservePageView()
+		collector.ObserveSubmit()
+		collector.ObserveUpload(1, 1)
+		collector.ObserveError("UploadTooLarge")
+		collector.ObserveNotifierSend("slack", time.Second, nil)
+	})
+}
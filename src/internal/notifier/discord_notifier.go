@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+	"github.com/boasihq/interactive-inputs/internal/metrics"
 	"github.com/sethvargo/go-githubactions"
 )
 
@@ -32,6 +35,13 @@ type NewDiscordNotifierRequest struct {
 	// ThreadId is the ID of the Discord thread the message should be sent to
 	// (as a threaded message)
 	ThreadId string
+
+	// Metrics collects how long sending each message takes, and whether it fails (optional)
+	Metrics *metrics.Collector
+
+	// JobUrl is the URL of the job the action is running in, which the notifications link
+	// to. The URL of the run is used when not provided
+	JobUrl string
 }
 
 // NewDiscordNotifier returns a new instance of a discord Notifier
@@ -56,6 +66,8 @@ func NewDiscordNotifier(r *NewDiscordNotifierRequest) Notifier {
 		action:               r.ActionPkg,
 		verificationEndpoint: verificationEndpoint,
 		threadId:             r.ThreadId,
+		metrics:              r.Metrics,
+		jobUrl:               r.JobUrl,
 	}
 }
 
@@ -80,9 +92,25 @@ type DiscordNotifier struct {
 	// threadId is the ID of the Discord thread the message should be sent to
 	// (as a threaded message)
 	threadId string
+
+	// metrics collects how long sending each message takes, and whether it fails
+	metrics *metrics.Collector
+
+	// jobUrl is the URL of the job the action is running in, empty when it isn't known
+	jobUrl string
 }
 
+// Notify sends a notification to the Discord webhook, recording how long it took
 func (n *DiscordNotifier) Notify(title, message string) (string, error) {
+	sentAt := time.Now()
+	id, err := n.notify(title, message)
+	n.metrics.ObserveNotifierSend(DiscordNotifierName, time.Since(sentAt), err)
+
+	return id, err
+}
+
+// notify sends a notification to the Discord webhook
+func (n *DiscordNotifier) notify(title, message string) (string, error) {
 
 	var discordCompleteWebhookUrl string = n.webhookUrl
 
@@ -210,14 +238,6 @@ func (n *DiscordNotifier) renderStandardDiscordNofityMessage(title, message stri
 
 %s`
 
-	// build out url for action
-	repoOwner, repoName := actionCtx.Repo()
-	additionalContext := fmt.Sprintf(
-		"%s/%s/actions/runs/%d",
-		actionCtx.ServerURL,
-		repoOwner+"/"+repoName,
-		actionCtx.RunID,
-	)
-
-	return fmt.Sprintf(defaultNotifyMessageFmt, optionalSentence, additionalContext, actionCtx.Actor, message), nil
+	// link to the job the action is running in, or its run when the job isn't known
+	return fmt.Sprintf(defaultNotifyMessageFmt, optionalSentence, githubapi.JobOrRunUrl(n.jobUrl, actionCtx), actionCtx.Actor, message), nil
 }
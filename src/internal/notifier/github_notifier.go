@@ -0,0 +1,381 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+	"github.com/boasihq/interactive-inputs/internal/metrics"
+	"github.com/sethvargo/go-githubactions"
+)
+
+const (
+	// githubStatusContext is the context the commit statuses are created under
+	githubStatusContext = "Interactive Inputs"
+
+	// githubStatusDescriptionLimit is the number of characters GitHub allows in a commit status' description
+	githubStatusDescriptionLimit = 140
+)
+
+// markdownLinkRegex matches a markdown link, capturing its text and URL
+var markdownLinkRegex = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// NewGithubNotifierRequest is the request object for creating a new
+// instance of a GitHub Notifier.
+type NewGithubNotifierRequest struct {
+	// Enabled whether the notifier is enabled or not
+	Enabled bool
+
+	// Client is the client used to make requests to the GitHub API
+	Client *githubapi.Client
+
+	// CommitStatus posts the portal's link as a pending commit status, rather than as a
+	// comment on the run's pull request or issue
+	CommitStatus bool
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+
+	// Metrics collects how long sending each message takes, and whether it fails (optional)
+	Metrics *metrics.Collector
+
+	// JobUrl is the URL of the job the action is running in, which the notifications link
+	// to. The URL of the run is used when not provided
+	JobUrl string
+}
+
+// NewGithubNotifier returns a new instance of a GitHub Notifier
+func NewGithubNotifier(r *NewGithubNotifierRequest) Notifier {
+	return &GithubNotifier{
+		enabled:      r.Enabled,
+		client:       r.Client,
+		commitStatus: r.CommitStatus,
+		action:       r.ActionPkg,
+		metrics:      r.Metrics,
+		jobUrl:       r.JobUrl,
+	}
+}
+
+// GithubNotifier is a struct that implements the Notifier and Completer interfaces
+type GithubNotifier struct {
+
+	// enabled whether the notifier is enabled or not
+	enabled bool
+
+	// client is the client used to make requests to the GitHub API
+	client *githubapi.Client
+
+	// commitStatus is whether the portal's link is posted as a commit status, it is also
+	// set when the run has no pull request or issue to comment on
+	commitStatus bool
+
+	// action represents the githubactions package
+	action *githubactions.Action
+
+	// metrics collects how long sending each message takes, and whether it fails
+	metrics *metrics.Collector
+
+	// jobUrl is the URL of the job the action is running in, empty when it isn't known
+	jobUrl string
+
+	// repository is the repository the run belongs to (i.e. owner/repo), set on verification
+	repository string
+
+	// publicRepository is whether anyone can read the repository, set on verification
+	publicRepository bool
+
+	// issueNumber is the number of the run's pull request or issue, set on verification
+	issueNumber int
+
+	// sha is the commit the statuses are created on, set on verification
+	sha string
+
+	// title is the title of the portal the notifications were sent for
+	title string
+
+	// commentIds holds the IDs of the comments that have been posted
+	commentIds []int64
+
+	// commentedAt is when the first comment was posted
+	commentedAt time.Time
+
+	// statusContexts holds the contexts of the commit statuses that have been created
+	statusContexts []string
+
+	// mutex guards the comments and statuses that have been sent
+	mutex sync.Mutex
+}
+
+// Notify posts the message to the run's pull request, issue or commit, recording how long it took
+func (n *GithubNotifier) Notify(title, message string) (string, error) {
+	sentAt := time.Now()
+	id, err := n.notify(title, message)
+	n.metrics.ObserveNotifierSend(GithubNotifierName, time.Since(sentAt), err)
+
+	return id, err
+}
+
+// notify posts the message as a comment on the run's pull request or issue, or when posting commit
+// statuses, creates a pending status linking to the message's link
+func (n *GithubNotifier) notify(title, message string) (string, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.title = title
+
+	if n.commitStatus {
+		return n.createPendingStatus(message)
+	}
+
+	renderedMessage, err := n.renderStandardGithubNotifyMessage("User Input Required", title, message)
+	if err != nil {
+		return "", err
+	}
+
+	comment, err := n.client.CreateIssueComment(context.Background(), n.repository, n.issueNumber, renderedMessage)
+	if err != nil {
+		n.action.Errorf("Unable to comment on #%d. Error: %v", n.issueNumber, err)
+		return "", errors.ErrFailedToSendMessageWithNotifier
+	}
+
+	if len(n.commentIds) == 0 {
+		n.commentedAt = comment.CreatedAt
+	}
+	n.commentIds = append(n.commentIds, comment.Id)
+
+	n.action.Debugf("Successfully commented on #%d.", n.issueNumber)
+
+	return strconv.FormatInt(comment.Id, 10), nil
+}
+
+// createPendingStatus creates a pending commit status linking to the message's link. Messages
+// without a link aren't sent, as a status can't be created without somewhere to link to
+func (n *GithubNotifier) createPendingStatus(message string) (string, error) {
+	link := markdownLinkRegex.FindStringSubmatch(message)
+	if link == nil {
+		n.action.Debugf("Not creating a commit status for a message without a link.")
+		return "", nil
+	}
+
+	statusContext := githubStatusContext
+	if len(n.statusContexts) > 0 {
+		statusContext = fmt.Sprintf("%s (%d)", githubStatusContext, len(n.statusContexts)+1)
+	}
+
+	err := n.client.CreateCommitStatus(context.Background(), n.repository, n.sha, githubapi.CommitStatus{
+		State:       "pending",
+		TargetUrl:   link[2],
+		Description: statusDescription(message),
+		Context:     statusContext,
+	})
+	if err != nil {
+		n.action.Errorf("Unable to create a commit status on %s. Error: %v", n.sha, err)
+		return "", errors.ErrFailedToSendMessageWithNotifier
+	}
+
+	n.statusContexts = append(n.statusContexts, statusContext)
+
+	n.action.Debugf("Successfully created a pending commit status on %s.", n.sha)
+
+	return statusContext, nil
+}
+
+// Complete updates the comments that have been posted, removing the portal's link, or sets the
+// commit statuses that have been created to success or failure, linking them to the job instead
+func (n *GithubNotifier) Complete(succeeded bool, description string) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	var completeErr error
+
+	heading := "User Input Received"
+	state := "success"
+	if !succeeded {
+		heading = "User Input No Longer Required"
+		state = "failure"
+	}
+
+	if len(n.commentIds) > 0 {
+		renderedMessage, err := n.renderStandardGithubNotifyMessage(heading, n.title, description)
+		if err != nil {
+			return err
+		}
+
+		for _, commentId := range n.commentIds {
+			err := n.client.UpdateIssueComment(context.Background(), n.repository, commentId, renderedMessage)
+			if err != nil {
+				n.action.Errorf("Unable to update comment %d on #%d. Error: %v", commentId, n.issueNumber, err)
+				completeErr = errors.ErrFailedToSendMessageWithNotifier
+			}
+		}
+	}
+
+	if len(n.statusContexts) > 0 {
+		actionCtx, err := n.action.Context()
+		if err != nil {
+			n.action.Errorf("Failed to get action context. Error: %v", err)
+			return err
+		}
+
+		for _, statusContext := range n.statusContexts {
+			err := n.client.CreateCommitStatus(context.Background(), n.repository, n.sha, githubapi.CommitStatus{
+				State:       state,
+				TargetUrl:   githubapi.JobOrRunUrl(n.jobUrl, actionCtx),
+				Description: statusDescription(description),
+				Context:     statusContext,
+			})
+			if err != nil {
+				n.action.Errorf("Unable to update the %q commit status on %s. Error: %v", statusContext, n.sha, err)
+				completeErr = errors.ErrFailedToSendMessageWithNotifier
+			}
+		}
+	}
+
+	return completeErr
+}
+
+// Verify checks the GitHub token can access the run's repository, and finds the pull request
+// or issue to comment on. When the run has neither, commit statuses are created instead.
+func (n *GithubNotifier) Verify() error {
+	n.action.Debugf("Initiating the verification of the GitHub notifier.")
+
+	actionCtx, err := n.action.Context()
+	if err != nil {
+		n.action.Errorf("Failed to get action context. Error: %v", err)
+		return err
+	}
+
+	n.repository = actionCtx.Repository
+
+	repository, err := n.client.GetRepository(context.Background(), n.repository)
+	if err != nil {
+		n.action.Errorf("Unable to access the repository %s with the github-token provided. Error: %v", n.repository, err)
+		return errors.ErrGithubNotifierVerificationFailed
+	}
+
+	n.publicRepository = !repository.Private
+
+	n.issueNumber, n.sha = issueFromEvent(actionCtx.Event)
+	if n.sha == "" {
+		n.sha = actionCtx.SHA
+	}
+
+	if !n.commitStatus && n.issueNumber == 0 && n.sha != "" {
+		pullRequests, err := n.client.ListPullRequestsAssociatedWithCommit(context.Background(), n.repository, n.sha)
+		if err != nil {
+			n.action.Debugf("Unable to find the pull requests of commit %s. Error: %v", n.sha, err)
+		}
+
+		for _, pullRequest := range pullRequests {
+			if pullRequest.State == "open" {
+				n.issueNumber = pullRequest.Number
+				break
+			}
+		}
+	}
+
+	if !n.commitStatus && n.issueNumber == 0 {
+		n.action.Infof("No pull request or issue was found for the run, the GitHub notifier will create a commit status instead.")
+		n.commitStatus = true
+	}
+
+	if n.commitStatus && n.sha == "" {
+		n.action.Errorf("Unable to find the commit of the run to create a commit status on.")
+		return errors.ErrGithubNotifierVerificationFailed
+	}
+
+	n.action.Debugf("Successfully verified the GitHub notifier.")
+
+	return nil
+}
+
+// Enabled returns whether the notifier is enabled or not
+func (n *GithubNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// PublicRepository returns whether the run's repository can be read by anyone
+func (n *GithubNotifier) PublicRepository() bool {
+	return n.publicRepository
+}
+
+// CommentThread returns the number of the pull request or issue the portal's link was commented
+// on and when it was first commented, or 0 when no comment has been posted
+func (n *GithubNotifier) CommentThread() (int, time.Time) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if len(n.commentIds) == 0 {
+		return 0, time.Time{}
+	}
+
+	return n.issueNumber, n.commentedAt
+}
+
+// renderStandardGithubNotifyMessage renders the standard GitHub notification message.
+func (n *GithubNotifier) renderStandardGithubNotifyMessage(heading, title, message string) (string, error) {
+
+	// get action context
+	actionCtx, err := n.action.Context()
+	if err != nil {
+		n.action.Errorf("Failed to get action context. Error: %v", err)
+		return "", err
+	}
+
+	var optionalSentence string = ""
+
+	if title != "" {
+		optionalSentence = fmt.Sprintf("**Title:** _`\"%s\"`_ | ", title)
+	}
+
+	defaultNotifyMessageFmt := "### %s" + `
+
+%s[**Go to run**](%s)
+**Initiator:** %s
+
+%s
+`
+
+	return fmt.Sprintf(defaultNotifyMessageFmt, heading, optionalSentence, githubapi.JobOrRunUrl(n.jobUrl, actionCtx), actionCtx.Actor, message), nil
+}
+
+// issueFromEvent returns the number of the pull request or issue the event that triggered the run
+// belongs to, along with the head commit of the pull request
+func issueFromEvent(event map[string]any) (int, string) {
+	if pullRequest, ok := event["pull_request"].(map[string]any); ok {
+		number, _ := pullRequest["number"].(float64)
+
+		var sha string
+		if head, ok := pullRequest["head"].(map[string]any); ok {
+			sha, _ = head["sha"].(string)
+		}
+
+		return int(number), sha
+	}
+
+	// comments on pull requests are sent as issue events too, with the pull request attached
+	if issue, ok := event["issue"].(map[string]any); ok {
+		number, _ := issue["number"].(float64)
+		return int(number), ""
+	}
+
+	return 0, ""
+}
+
+// statusDescription returns the message as a commit status description, replacing its links
+// with their text and shortening it to the length GitHub allows
+func statusDescription(message string) string {
+	description := strings.NewReplacer("**", "", "__", "").Replace(markdownLinkRegex.ReplaceAllString(message, "$1"))
+
+	if runes := []rune(description); len(runes) > githubStatusDescriptionLimit {
+		description = string(runes[:githubStatusDescriptionLimit-3]) + "..."
+	}
+
+	return description
+}
@@ -0,0 +1,224 @@
+package notifier_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+	"github.com/boasihq/interactive-inputs/internal/notifier"
+)
+
+const testPortalLink = "https://example.ngrok.app/?token=secret-token"
+
+// fakeGithubApi records the comments and commit statuses the GitHub notifier sends
+type fakeGithubApi struct {
+	private      bool
+	pullRequests []githubapi.PullRequest
+
+	mutex    sync.Mutex
+	comments map[string]string
+	statuses map[string]githubapi.CommitStatus
+}
+
+func (f *fakeGithubApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo":
+		_ = json.NewEncoder(w).Encode(map[string]any{"full_name": "owner/repo", "private": f.private})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/repos/owner/repo/commits/"):
+		_ = json.NewEncoder(w).Encode(f.pullRequests)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/repos/owner/repo/issues/"):
+		var body struct {
+			Body string `json:"body"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.comments[strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/issues/")] = body.Body
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 1})
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/repos/owner/repo/statuses/"):
+		var status githubapi.CommitStatus
+		_ = json.NewDecoder(r.Body).Decode(&status)
+		f.statuses[strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/statuses/")] = status
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// newTestGithubNotifier returns a verified GitHub notifier for a run of owner/repo on commit
+// run-sha, triggered by the event, that sends its notifications to the fake API
+func newTestGithubNotifier(t *testing.T, api *fakeGithubApi, event map[string]any, commitStatus bool) notifier.Notifier {
+	t.Helper()
+
+	api.comments = map[string]string{}
+	api.statuses = map[string]githubapi.CommitStatus{}
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	eventJson, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	eventPath := filepath.Join(t.TempDir(), "event.json")
+	require.NoError(t, os.WriteFile(eventPath, eventJson, 0o600))
+
+	env := map[string]string{
+		"GITHUB_EVENT_PATH": eventPath,
+		"GITHUB_REPOSITORY": "owner/repo",
+		"GITHUB_SERVER_URL": "https://github.com",
+		"GITHUB_RUN_ID":     "42",
+		"GITHUB_SHA":        "run-sha",
+		"GITHUB_ACTOR":      "octocat",
+	}
+
+	githubNotifier := notifier.NewGithubNotifier(&notifier.NewGithubNotifierRequest{
+		Enabled:      true,
+		Client:       githubapi.NewClient(&githubapi.NewClientRequest{BaseUrl: server.URL, Token: "token"}),
+		CommitStatus: commitStatus,
+		ActionPkg: githubactions.New(
+			githubactions.WithWriter(io.Discard),
+			githubactions.WithGetenv(func(key string) string { return env[key] }),
+		),
+	})
+	require.NoError(t, githubNotifier.Verify())
+
+	return githubNotifier
+}
+
+func TestGithubNotifier_NotifyFindsIssueFromEvent(t *testing.T) {
+	tests := []struct {
+		name            string
+		event           map[string]any
+		pullRequests    []githubapi.PullRequest
+		commitStatus    bool
+		expectedComment string
+		expectedStatus  string
+	}{
+		{
+			name:            "pull request event",
+			event:           map[string]any{"pull_request": map[string]any{"number": 7, "head": map[string]any{"sha": "head-sha"}}},
+			expectedComment: "7/comments",
+		},
+		{
+			name:            "issue comment event",
+			event:           map[string]any{"issue": map[string]any{"number": 9}},
+			expectedComment: "9/comments",
+		},
+		{
+			name:            "push event with an open pull request for its commit",
+			event:           map[string]any{"ref": "refs/heads/feature"},
+			pullRequests:    []githubapi.PullRequest{{Number: 10, State: "closed"}, {Number: 11, State: "open"}},
+			expectedComment: "11/comments",
+		},
+		{
+			name:           "push event without a pull request",
+			event:          map[string]any{"ref": "refs/heads/main"},
+			pullRequests:   []githubapi.PullRequest{{Number: 10, State: "closed"}},
+			expectedStatus: "run-sha",
+		},
+		{
+			name:           "statuses are created on the pull request's head commit",
+			event:          map[string]any{"pull_request": map[string]any{"number": 7, "head": map[string]any{"sha": "head-sha"}}},
+			commitStatus:   true,
+			expectedStatus: "head-sha",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeGithubApi{private: true, pullRequests: tt.pullRequests}
+			githubNotifier := newTestGithubNotifier(t, api, tt.event, tt.commitStatus)
+
+			_, err := githubNotifier.Notify("Deploy", "[**Enter required input**]("+testPortalLink+")")
+			require.NoError(t, err)
+
+			if tt.expectedComment != "" {
+				assert.Contains(t, api.comments[tt.expectedComment], testPortalLink)
+				assert.Empty(t, api.statuses)
+			}
+
+			if tt.expectedStatus != "" {
+				assert.Equal(t, testPortalLink, api.statuses[tt.expectedStatus].TargetUrl)
+				assert.Empty(t, api.comments)
+			}
+		})
+	}
+}
+
+func TestGithubNotifier_NotifyStatusDescription(t *testing.T) {
+	tests := []struct {
+		name                string
+		message             string
+		expectedDescription string
+	}{
+		{
+			name:                "links are replaced with their text",
+			message:             "[**Enter required input**](" + testPortalLink + ")",
+			expectedDescription: "Enter required input",
+		},
+		{
+			name:                "recipients are kept",
+			message:             "alice: [**Enter required input**](" + testPortalLink + ")",
+			expectedDescription: "alice: Enter required input",
+		},
+		{
+			name:                "long descriptions are shortened to the limit",
+			message:             "[**Enter required input**](" + testPortalLink + ") " + strings.Repeat("é", 150),
+			expectedDescription: "Enter required input " + strings.Repeat("é", 116) + "...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeGithubApi{private: true}
+			githubNotifier := newTestGithubNotifier(t, api, map[string]any{}, true)
+
+			_, err := githubNotifier.Notify("Deploy", tt.message)
+			require.NoError(t, err)
+
+			status := api.statuses["run-sha"]
+			assert.Equal(t, "pending", status.State)
+			assert.Equal(t, tt.expectedDescription, status.Description)
+			assert.LessOrEqual(t, len([]rune(status.Description)), 140)
+		})
+	}
+}
+
+func TestGithubNotifier_PublicRepository(t *testing.T) {
+	tests := []struct {
+		name                     string
+		private                  bool
+		expectedPublicRepository bool
+	}{
+		{name: "private repository", private: true},
+		{name: "public repository", expectedPublicRepository: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeGithubApi{private: tt.private}
+			githubNotifier := newTestGithubNotifier(t, api, map[string]any{"issue": map[string]any{"number": 9}}, false)
+
+			linkNotifier, ok := githubNotifier.(*notifier.GithubNotifier)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedPublicRepository, linkNotifier.PublicRepository())
+
+			// the message is posted as it is given, the runner decides which links can be posted
+			_, err := githubNotifier.Notify("Deploy", "Input is required, the portal's link isn't posted on public repositories")
+			require.NoError(t, err)
+			assert.Contains(t, api.comments["9/comments"], "Input is required, the portal's link isn't posted on public repositories")
+		})
+	}
+}
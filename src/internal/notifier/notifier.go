@@ -1,5 +1,16 @@
 package notifier
 
+const (
+	// SlackNotifierName is the name the Slack notifier is recorded under in the metrics
+	SlackNotifierName = "slack"
+
+	// DiscordNotifierName is the name the Discord notifier is recorded under in the metrics
+	DiscordNotifierName = "discord"
+
+	// GithubNotifierName is the name the GitHub notifier is recorded under in the metrics
+	GithubNotifierName = "github"
+)
+
 type Notifier interface {
 
 	// Notify sends a notification to respective integration
@@ -13,3 +24,21 @@ type Notifier interface {
 	// Enabled returns whether the notifier is enabled or not
 	Enabled() bool
 }
+
+// Completer is implemented by notifiers whose notifications can be updated
+// once the portal has completed, i.e. to mark them as no longer needing input
+type Completer interface {
+
+	// Complete updates the notifications that have been sent with whether the portal
+	// completed successfully, along with a description of how it completed
+	Complete(succeeded bool, description string) error
+}
+
+// RecipientNotifier is implemented by notifiers that can send a recipient their link to the
+// portal privately, i.e. as a direct message
+type RecipientNotifier interface {
+
+	// NotifyRecipient sends the recipient their link to the portal, returning
+	// false when the recipient can't be reached by the notifier
+	NotifyRecipient(recipient, title, portalLink string) (bool, error)
+}
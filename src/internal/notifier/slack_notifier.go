@@ -5,8 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+	"github.com/boasihq/interactive-inputs/internal/metrics"
 	"github.com/sethvargo/go-githubactions"
 )
 
@@ -31,8 +36,18 @@ type NewSlackNotifierRequest struct {
 	// VerifificationEndpoint is the endpoint to verify the token
 	VerificationEndpoint string
 
+	// ApiUrl is the base URL of the Slack API messages are sent with. Defaults to https://slack.com/api
+	ApiUrl string
+
 	// ThreadTs is the timestamp of the message to reply to in the thread
 	ThreadTs string
+
+	// Metrics collects how long sending each message takes, and whether it fails (optional)
+	Metrics *metrics.Collector
+
+	// JobUrl is the URL of the job the action is running in, which the notifications link
+	// to. The URL of the run is used when not provided
+	JobUrl string
 }
 
 // NewSlackNotifier returns a new instance of a Slack Notifier
@@ -40,11 +55,16 @@ func NewSlackNotifier(r *NewSlackNotifierRequest) Notifier {
 
 	var botName string = "Interactive Inputs"
 	var verificationEndpoint string = "https://slack.com/api/auth.test"
+	var apiUrl string = "https://slack.com/api"
 
 	if r.VerificationEndpoint != "" {
 		verificationEndpoint = r.VerificationEndpoint
 	}
 
+	if r.ApiUrl != "" {
+		apiUrl = r.ApiUrl
+	}
+
 	if r.BotName != "" {
 		botName = r.BotName
 	}
@@ -56,7 +76,10 @@ func NewSlackNotifier(r *NewSlackNotifierRequest) Notifier {
 		botName:              botName,
 		action:               r.ActionPkg,
 		verificationEndpoint: verificationEndpoint,
+		apiUrl:               apiUrl,
 		threadTs:             r.ThreadTs,
+		metrics:              r.Metrics,
+		jobUrl:               r.JobUrl,
 	}
 }
 
@@ -81,15 +104,90 @@ type SlackNotifier struct {
 	// verificationEndpoint is the endpoint to verify the auth
 	verificationEndpoint string
 
+	// apiUrl is the base URL of the Slack API messages are sent with
+	apiUrl string
+
 	// threadTs is the timestamp of the message to reply to in the thread
 	threadTs string
+
+	// metrics collects how long sending each message takes, and whether it fails
+	metrics *metrics.Collector
+
+	// jobUrl is the URL of the job the action is running in, empty when it isn't known
+	jobUrl string
 }
 
-// Notify sends a notification to the Slack channel
+// Notify sends a notification to the Slack channel, recording how long it took
 func (n *SlackNotifier) Notify(title, message string) (string, error) {
+	sentAt := time.Now()
+	ts, err := n.postMessage(n.channel, n.threadTs, title, message)
+	n.metrics.ObserveNotifierSend(SlackNotifierName, time.Since(sentAt), err)
+
+	return ts, err
+}
+
+// NotifyRecipient sends the recipient their link to the portal as a direct message. Only recipients
+// listed by the email of their Slack account can be reached
+func (n *SlackNotifier) NotifyRecipient(recipient, title, portalLink string) (bool, error) {
+	if !strings.Contains(recipient, "@") {
+		return false, nil
+	}
+
+	sentAt := time.Now()
+	userId, err := n.lookupUserByEmail(recipient)
+	if err != nil || userId == "" {
+		n.metrics.ObserveNotifierSend(SlackNotifierName, time.Since(sentAt), err)
+		return false, err
+	}
+
+	_, err = n.postMessage(userId, "", title, fmt.Sprintf("<%s|*Enter your required input*>", portalLink))
+	n.metrics.ObserveNotifierSend(SlackNotifierName, time.Since(sentAt), err)
+
+	return err == nil, err
+}
+
+// lookupUserByEmail returns the ID of the Slack user with the email, or an empty string when
+// there isn't one
+func (n *SlackNotifier) lookupUserByEmail(email string) (string, error) {
+	var lookupResponse SlackLookupUserResponse
+
+	request, err := http.NewRequest(http.MethodGet, n.apiUrl+"/users.lookupByEmail?email="+url.QueryEscape(email), nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Add("Authorization", "Bearer "+n.token)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		n.action.Errorf("An error occured while looking up a recipient's Slack user. Error: %v", err)
+		return "", err
+	}
+
+	defer response.Body.Close()
+
+	err = json.NewDecoder(response.Body).Decode(&lookupResponse)
+	if err != nil {
+		n.action.Errorf("Unexpected error while decoding response. Error: %v", err)
+		return "", err
+	}
+
+	if lookupResponse.Error == "users_not_found" {
+		return "", nil
+	}
+
+	if !lookupResponse.Ok || lookupResponse.User == nil {
+		n.action.Errorf("Unable to look up a recipient's Slack user. Error: %v", lookupResponse.Error)
+		return "", errors.ErrFailedToSendMessageWithNotifier
+	}
+
+	return lookupResponse.User.Id, nil
+}
+
+// postMessage sends a notification to the Slack channel, in the thread when threadTs is provided
+func (n *SlackNotifier) postMessage(channel, threadTs, title, message string) (string, error) {
 
 	var notificationResponse SlackChatPostMessageResponse
-	var slackPostChatMessageUrl string = "https://slack.com/api/chat.postMessage"
+	var slackPostChatMessageUrl string = n.apiUrl + "/chat.postMessage"
 
 	// Shape the message to be sent
 	renderedMessage, err := n.renderStandardSlackNofityMessage(title, message)
@@ -98,7 +196,7 @@ func (n *SlackNotifier) Notify(title, message string) (string, error) {
 	}
 
 	notificationMessage := SlackChatPostMessageRequest{
-		Channel:     n.channel,
+		Channel:     channel,
 		UnfurlLinks: false,
 		UnfurlMedia: false,
 		Username:    n.botName,
@@ -115,8 +213,8 @@ func (n *SlackNotifier) Notify(title, message string) (string, error) {
 	}
 
 	// Check if thread ts provided
-	if n.threadTs != "" {
-		notificationMessage.ThreadTs = n.threadTs
+	if threadTs != "" {
+		notificationMessage.ThreadTs = threadTs
 	}
 
 	notificationMessageBytes, err := json.Marshal(notificationMessage)
@@ -234,14 +332,6 @@ func (n *SlackNotifier) renderStandardSlackNofityMessage(title, message string)
 %s
 `
 
-	// build out url for action
-	repoOwner, repoName := actionCtx.Repo()
-	additionalContext := fmt.Sprintf(
-		"%s/%s/actions/runs/%d",
-		actionCtx.ServerURL,
-		repoOwner+"/"+repoName,
-		actionCtx.RunID,
-	)
-
-	return fmt.Sprintf(defaultNotifyMessageFmt, optionalSentence, additionalContext, actionCtx.Actor, message), nil
+	// link to the job the action is running in, or its run when the job isn't known
+	return fmt.Sprintf(defaultNotifyMessageFmt, optionalSentence, githubapi.JobOrRunUrl(n.jobUrl, actionCtx), actionCtx.Actor, message), nil
 }
@@ -0,0 +1,152 @@
+package notifier_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/boasihq/interactive-inputs/internal/notifier"
+)
+
+// fakeSlackApi knows the Slack users by their email, and records the messages sent to each channel
+type fakeSlackApi struct {
+	users map[string]string
+
+	mutex    sync.Mutex
+	messages map[string]string
+}
+
+func (f *fakeSlackApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer xoxb-token" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "not_authed"})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/users.lookupByEmail":
+		userId, ok := f.users[r.URL.Query().Get("email")]
+		if !ok {
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "users_not_found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "user": map[string]any{"id": userId}})
+	case "/chat.postMessage":
+		var message notifier.SlackChatPostMessageRequest
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		f.messages[message.Channel] = message.Blocks[0].Text.Text
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "channel": message.Channel, "ts": "1.2"})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// newTestSlackNotifier returns a Slack notifier for the channel that sends its messages to the fake API
+func newTestSlackNotifier(t *testing.T, api *fakeSlackApi, token string) notifier.Notifier {
+	t.Helper()
+
+	api.messages = map[string]string{}
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	env := map[string]string{
+		"GITHUB_REPOSITORY": "owner/repo",
+		"GITHUB_SERVER_URL": "https://github.com",
+		"GITHUB_RUN_ID":     "42",
+		"GITHUB_ACTOR":      "octocat",
+	}
+
+	return notifier.NewSlackNotifier(&notifier.NewSlackNotifierRequest{
+		Enabled: true,
+		Token:   token,
+		Channel: "#deploys",
+		ApiUrl:  server.URL,
+		ActionPkg: githubactions.New(
+			githubactions.WithWriter(io.Discard),
+			githubactions.WithGetenv(func(key string) string { return env[key] }),
+		),
+	})
+}
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	api := &fakeSlackApi{}
+	slackNotifier := newTestSlackNotifier(t, api, "xoxb-token")
+
+	ts, err := slackNotifier.Notify("Deploy", "Input is required from: alice")
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.2", ts)
+	assert.Contains(t, api.messages["#deploys"], "Input is required from: alice")
+	assert.Contains(t, api.messages["#deploys"], "Deploy")
+}
+
+func TestSlackNotifier_NotifyRecipient(t *testing.T) {
+	recipientLink := "https://example.ngrok.app/?recipient=alice%40example.com&token=signature"
+
+	tests := []struct {
+		name            string
+		recipient       string
+		token           string
+		expectedSent    bool
+		expectedError   bool
+		expectedChannel string
+	}{
+		{
+			name:            "recipient with a Slack account is sent a direct message",
+			recipient:       "alice@example.com",
+			token:           "xoxb-token",
+			expectedSent:    true,
+			expectedChannel: "U123",
+		},
+		{
+			name:      "recipient without a Slack account isn't sent anything",
+			recipient: "bob@example.com",
+			token:     "xoxb-token",
+		},
+		{
+			name:      "GitHub usernames can't be reached",
+			recipient: "octocat",
+			token:     "xoxb-token",
+		},
+		{
+			name:          "failing to look up the recipient fails",
+			recipient:     "alice@example.com",
+			token:         "xoxb-invalid",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeSlackApi{users: map[string]string{"alice@example.com": "U123"}}
+			slackNotifier := newTestSlackNotifier(t, api, tt.token)
+
+			recipientNotifier, ok := slackNotifier.(notifier.RecipientNotifier)
+			require.True(t, ok)
+
+			sent, err := recipientNotifier.NotifyRecipient(tt.recipient, "Deploy", recipientLink)
+			assert.Equal(t, tt.expectedError, err != nil)
+			assert.Equal(t, tt.expectedSent, sent)
+
+			if !tt.expectedSent {
+				assert.Empty(t, api.messages)
+				return
+			}
+
+			// the link is only sent to the recipient, never to the channel
+			assert.Len(t, api.messages, 1)
+			assert.Contains(t, api.messages[tt.expectedChannel], "<"+recipientLink+"|*Enter your required input*>")
+		})
+	}
+}
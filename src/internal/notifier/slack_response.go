@@ -69,3 +69,24 @@ type SlackAttachment struct {
 	// Fallback is the fallback text of the attachment
 	Fallback string `json:"fallback,omitempty"`
 }
+
+// SlackLookupUserResponse represents the response from the Slack API users.lookupByEmail endpoint
+type SlackLookupUserResponse struct {
+
+	// Ok is a boolean indicating whether the user was found or not
+	Ok bool `json:"ok,omitempty"`
+
+	// User is the user the email belongs to
+	User *SlackUser `json:"user,omitempty"`
+
+	// Error is a string representing the reason if the user was not found
+	// i.e. users_not_found
+	Error string `json:"error,omitempty"`
+}
+
+// SlackUser represents a Slack user
+type SlackUser struct {
+
+	// Id is the ID of the user, which direct messages are sent to
+	Id string `json:"id,omitempty"`
+}
@@ -0,0 +1,241 @@
+package portal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+)
+
+// accessTokenSize is the number of random bytes the portal's access token is made up of
+const accessTokenSize = 32
+
+// submittedByContextKey is the key of the recipient a request was made by in the request's context
+type submittedByContextKey struct{}
+
+// NewAccessToken returns a random token the portal can only be used with
+func NewAccessToken() (string, error) {
+	return newRandomToken(accessTokenSize)
+}
+
+// newRandomToken returns a URL safe token made up of the given number of random bytes
+func newRandomToken(size int) (string, error) {
+	token := make([]byte, size)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// AccessLink returns the portal's URL with the access token added
+func AccessLink(portalUrl, accessToken string) string {
+	if accessToken == "" {
+		return portalUrl
+	}
+
+	return addQueryParams(portalUrl, map[string]string{AccessTokenQueryParam: accessToken})
+}
+
+// RecipientLink returns the portal's URL with the recipient and their signature added
+func (h *Handler) RecipientLink(portalUrl, recipient string) string {
+	return addQueryParams(portalUrl, map[string]string{
+		RecipientQueryParam:   recipient,
+		AccessTokenQueryParam: h.RecipientSignature(recipient),
+	})
+}
+
+// RecipientSignature returns the signature of the recipient's link, signed with the recipients' link key
+func (h *Handler) RecipientSignature(recipient string) string {
+	return h.sign(h.recipientLinkKey, "recipient:"+recipient)
+}
+
+// RevokeAccessLinks stops the portal's links and session cookies from being accepted, returning
+// false when they were already revoked
+func (h *Handler) RevokeAccessLinks() bool {
+	return h.accessLinksRevoked.CompareAndSwap(false, true)
+}
+
+// SubmittedBy returns the recipient the request was made by, or an empty string when the portal
+// isn't shared with recipients
+func SubmittedBy(r *http.Request) string {
+	submittedBy, _ := r.Context().Value(submittedByContextKey{}).(string)
+	return submittedBy
+}
+
+// addQueryParams returns the URL with the query parameters added
+func addQueryParams(rawUrl string, params map[string]string) string {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
+	}
+
+	if parsedUrl.Path == "" {
+		parsedUrl.Path = "/"
+	}
+
+	query := parsedUrl.Query()
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	parsedUrl.RawQuery = query.Encode()
+
+	return parsedUrl.String()
+}
+
+// RequireAccessToken only lets requests through that provide the portal's access token, or the
+// session cookie it is exchanged for
+func (h *Handler) RequireAccessToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if (h.accessToken == "" && len(h.recipients) == 0) || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if h.accessLinksRevoked.Load() {
+			h.respondWithAccessDenied(w, r)
+			return
+		}
+
+		client := h.clientAddress(r)
+		if h.isLockedOut(w, r, client) {
+			return
+		}
+
+		if providedToken := r.URL.Query().Get(AccessTokenQueryParam); providedToken != "" {
+			recipient, ok := h.verifyAccessToken(providedToken, r.URL.Query().Get(RecipientQueryParam))
+			if !ok {
+				h.actionPkg.Warningf("A request was made to the portal with an invalid access token by %s", client)
+				h.recordFailedAccessAttempt(r, "invalid access token")
+				h.respondWithAccessDenied(w, r)
+				return
+			}
+
+			http.SetCookie(w, &http.Cookie{
+				Name:     SessionCookieName,
+				Value:    h.sessionCookieValue(recipient),
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   isSecureRequest(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+
+			if r.Method == http.MethodGet {
+				query := r.URL.Query()
+				query.Del(AccessTokenQueryParam)
+				query.Del(RecipientQueryParam)
+
+				redirectUrl := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+				http.Redirect(w, r, redirectUrl.String(), http.StatusSeeOther)
+				return
+			}
+
+			next.ServeHTTP(w, withSubmittedBy(r, recipient))
+			return
+		}
+
+		sessionCookie, err := r.Cookie(SessionCookieName)
+		if err != nil {
+			h.respondWithAccessDenied(w, r)
+			return
+		}
+
+		recipient, ok := h.verifySessionCookie(sessionCookie.Value)
+		if !ok {
+			h.recordFailedAccessAttempt(r, "invalid session")
+			h.respondWithAccessDenied(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, withSubmittedBy(r, recipient))
+	})
+}
+
+// verifyAccessToken returns the recipient the provided token was made for, and whether it
+// is valid. When the portal is shared with recipients, only their signatures are valid.
+func (h *Handler) verifyAccessToken(providedToken, recipient string) (string, bool) {
+	if len(h.recipients) == 0 {
+		return "", subtle.ConstantTimeCompare([]byte(providedToken), []byte(h.accessToken)) == 1
+	}
+
+	if !toolbox.StringInSlice(recipient, h.recipients) {
+		return "", false
+	}
+
+	return recipient, subtle.ConstantTimeCompare([]byte(providedToken), []byte(h.RecipientSignature(recipient))) == 1
+}
+
+// verifySessionCookie returns the recipient the session cookie was issued to, and whether it is valid
+func (h *Handler) verifySessionCookie(value string) (string, bool) {
+	var recipient string
+
+	if len(h.recipients) > 0 {
+		encodedRecipient, _, found := strings.Cut(value, ".")
+		if !found {
+			return "", false
+		}
+
+		decodedRecipient, err := base64.RawURLEncoding.DecodeString(encodedRecipient)
+		if err != nil || !toolbox.StringInSlice(string(decodedRecipient), h.recipients) {
+			return "", false
+		}
+		recipient = string(decodedRecipient)
+	}
+
+	return recipient, subtle.ConstantTimeCompare([]byte(value), []byte(h.sessionCookieValue(recipient))) == 1
+}
+
+// sessionCookieValue returns the value of the session cookie the access token is exchanged for
+func (h *Handler) sessionCookieValue(recipient string) string {
+	if len(h.recipients) == 0 {
+		return h.sign(h.accessToken, SessionCookieName)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(recipient)) + "." + h.sign(h.recipientLinkKey, SessionCookieName+":"+recipient)
+}
+
+// sign returns the hex encoded HMAC-SHA256 of the message
+func (h *Handler) sign(key, message string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// withSubmittedBy returns the request with the recipient it was made by added to its context
+func withSubmittedBy(r *http.Request, recipient string) *http.Request {
+	if recipient == "" {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), submittedByContextKey{}, recipient))
+}
+
+// respondWithAccessDenied returns the access denied error
+func (h *Handler) respondWithAccessDenied(w http.ResponseWriter, r *http.Request) {
+	h.respondWithPortalError(w, r, ErrKeyPortalAccessDenied)
+}
+
+// respondWithPortalError returns the error of the given key, as JSON for API requests and as text otherwise
+func (h *Handler) respondWithPortalError(w http.ResponseWriter, r *http.Request, errKey string) {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		h.respondWithError(w, errors.New(errKey))
+		return
+	}
+
+	h.observeError(errKey)
+	http.Error(w, portalErrorMap[errKey].Detail, portalErrorMap[errKey].StatusCode)
+}
+
+// isSecureRequest returns true when the request was made over HTTPS, either directly or
+// through a tunnel that terminates TLS (i.e. ngrok)
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
@@ -0,0 +1,362 @@
+package portal_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPortalUrl        = "http://portal.example.com"
+	testAccessToken      = "access-token"
+	testRecipientKey     = "recipient-link-key"
+	testRecipient        = "leon"
+	testOtherRecipient   = "mallory"
+	testUnknownRecipient = "eve"
+)
+
+// getSessionCookie returns the session cookie set on the response, or nil when it isn't set
+func getSessionCookie(recorder *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == portal.SessionCookieName {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestAccessLink(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		portalUrl    string
+		accessToken  string
+		expectedLink string
+	}{
+		{
+			name:         "no access token",
+			portalUrl:    testPortalUrl,
+			expectedLink: testPortalUrl,
+		},
+		{
+			name:         "access token is added",
+			portalUrl:    testPortalUrl,
+			accessToken:  testAccessToken,
+			expectedLink: testPortalUrl + "/?token=access-token",
+		},
+		{
+			name:         "existing query is kept",
+			portalUrl:    testPortalUrl + "/?env=staging",
+			accessToken:  testAccessToken,
+			expectedLink: testPortalUrl + "/?env=staging&token=access-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedLink, portal.AccessLink(tt.portalUrl, tt.accessToken))
+		})
+	}
+}
+
+func TestHandler_RecipientLink(t *testing.T) {
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		Recipients:       []string{testRecipient, "ops@example.com"},
+		RecipientLinkKey: testRecipientKey,
+	})
+
+	tests := []struct {
+		name         string
+		portalUrl    string
+		recipient    string
+		expectedLink string
+	}{
+		{
+			name:         "recipient and signature are added",
+			portalUrl:    testPortalUrl,
+			recipient:    testRecipient,
+			expectedLink: testPortalUrl + "/?recipient=leon&token=" + handler.RecipientSignature(testRecipient),
+		},
+		{
+			name:         "email recipient is escaped",
+			portalUrl:    testPortalUrl,
+			recipient:    "ops@example.com",
+			expectedLink: testPortalUrl + "/?recipient=ops%40example.com&token=" + handler.RecipientSignature("ops@example.com"),
+		},
+		{
+			name:         "existing query is kept",
+			portalUrl:    testPortalUrl + "/?env=staging",
+			recipient:    testRecipient,
+			expectedLink: testPortalUrl + "/?env=staging&recipient=leon&token=" + handler.RecipientSignature(testRecipient),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedLink, handler.RecipientLink(tt.portalUrl, tt.recipient))
+		})
+	}
+}
+
+func TestHandler_RecipientSignature(t *testing.T) {
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		Recipients:       []string{testRecipient, testOtherRecipient},
+		RecipientLinkKey: testRecipientKey,
+	})
+	otherRunHandler := newTestHandler(&portal.NewHandlerRequest{
+		Recipients:       []string{testRecipient, testOtherRecipient},
+		RecipientLinkKey: "other-recipient-link-key",
+	})
+
+	signature := handler.RecipientSignature(testRecipient)
+	assert.NotEmpty(t, signature)
+	assert.Equal(t, signature, handler.RecipientSignature(testRecipient), "the signature is the same for the same recipient and key")
+	assert.NotEqual(t, signature, handler.RecipientSignature(testOtherRecipient), "each recipient has their own signature")
+	assert.NotEqual(t, signature, otherRunHandler.RecipientSignature(testRecipient), "each run's key makes its own signatures")
+	assert.NotContains(t, signature, testRecipientKey)
+}
+
+func TestHandler_RequireAccessToken(t *testing.T) {
+
+	tokenHandler := newTestHandler(&portal.NewHandlerRequest{AccessToken: testAccessToken})
+
+	// the session cookie the access token is exchanged for
+	exchangeRecorder := httptest.NewRecorder()
+	tokenHandler.RequireAccessToken(okHandler).ServeHTTP(exchangeRecorder, httptest.NewRequest(http.MethodGet, portal.AccessLink(testPortalUrl, testAccessToken), nil))
+	sessionCookie := getSessionCookie(exchangeRecorder)
+	assert.NotNil(t, sessionCookie)
+
+	tests := []struct {
+		name             string
+		handler          *portal.Handler
+		method           string
+		url              string
+		cookie           *http.Cookie
+		expectedStatus   int
+		expectedLocation string
+		expectedCookie   bool
+	}{
+		{
+			name:           "success - no access token required",
+			handler:        newTestHandler(&portal.NewHandlerRequest{}),
+			method:         http.MethodGet,
+			url:            testPortalUrl + "/",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:             "success - page request with the token is redirected without it",
+			handler:          tokenHandler,
+			method:           http.MethodGet,
+			url:              testPortalUrl + "/?env=staging&token=" + testAccessToken,
+			expectedStatus:   http.StatusSeeOther,
+			expectedLocation: "/?env=staging",
+			expectedCookie:   true,
+		},
+		{
+			name:           "success - API request with the token",
+			handler:        tokenHandler,
+			method:         http.MethodPost,
+			url:            testPortalUrl + "/api/v1/portal/submit?token=" + testAccessToken,
+			expectedStatus: http.StatusOK,
+			expectedCookie: true,
+		},
+		{
+			name:           "success - session cookie",
+			handler:        tokenHandler,
+			method:         http.MethodPost,
+			url:            testPortalUrl + "/api/v1/portal/submit",
+			cookie:         sessionCookie,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "success - preflight requests don't need the token",
+			handler:        tokenHandler,
+			method:         http.MethodOptions,
+			url:            testPortalUrl + "/api/v1/portal/submit",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "failed - no token or session cookie",
+			handler:        tokenHandler,
+			method:         http.MethodGet,
+			url:            testPortalUrl + "/",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "failed - invalid token",
+			handler:        tokenHandler,
+			method:         http.MethodGet,
+			url:            testPortalUrl + "/?token=guessed-token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "failed - session cookie holding the token",
+			handler:        tokenHandler,
+			method:         http.MethodGet,
+			url:            testPortalUrl + "/",
+			cookie:         &http.Cookie{Name: portal.SessionCookieName, Value: testAccessToken},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "failed - session cookie of another portal",
+			handler:        newTestHandler(&portal.NewHandlerRequest{AccessToken: "another-access-token"}),
+			method:         http.MethodGet,
+			url:            testPortalUrl + "/",
+			cookie:         sessionCookie,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			request := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.cookie != nil {
+				request.AddCookie(tt.cookie)
+			}
+
+			recorder := httptest.NewRecorder()
+			tt.handler.RequireAccessToken(okHandler).ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedLocation, recorder.Header().Get("Location"))
+
+			newCookie := getSessionCookie(recorder)
+			if !tt.expectedCookie {
+				assert.Nil(t, newCookie)
+				return
+			}
+
+			if assert.NotNil(t, newCookie) {
+				assert.NotContains(t, newCookie.Value, testAccessToken)
+				assert.True(t, newCookie.HttpOnly)
+			}
+		})
+	}
+}
+
+func TestHandler_RequireAccessTokenRevoked(t *testing.T) {
+
+	handler := newTestHandler(&portal.NewHandlerRequest{AccessToken: testAccessToken})
+
+	recorder := httptest.NewRecorder()
+	handler.RequireAccessToken(okHandler).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, portal.AccessLink(testPortalUrl, testAccessToken), nil))
+	sessionCookie := getSessionCookie(recorder)
+
+	handler.RevokeAccessLinks()
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, portal.AccessLink(testPortalUrl, testAccessToken), nil),
+		httptest.NewRequest(http.MethodGet, testPortalUrl+"/", nil),
+	} {
+		request.AddCookie(sessionCookie)
+
+		recorder := httptest.NewRecorder()
+		handler.RequireAccessToken(okHandler).ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	}
+}
+
+func TestHandler_RequireAccessTokenRecipients(t *testing.T) {
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		Recipients:       []string{testRecipient, testOtherRecipient},
+		RecipientLinkKey: testRecipientKey,
+	})
+
+	var submittedBy string
+	recipientHandler := handler.RequireAccessToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		submittedBy = portal.SubmittedBy(r)
+	}))
+
+	recipientLink := handler.RecipientLink(testPortalUrl, testRecipient)
+
+	otherRunHandler := newTestHandler(&portal.NewHandlerRequest{
+		Recipients:       []string{testRecipient, testOtherRecipient},
+		RecipientLinkKey: "other-recipient-link-key",
+	})
+
+	// swaps the recipient of the link, keeping the signature
+	forgedLink, err := url.Parse(recipientLink)
+	assert.NoError(t, err)
+	forgedQuery := forgedLink.Query()
+	forgedQuery.Set(portal.RecipientQueryParam, testOtherRecipient)
+	forgedLink.RawQuery = forgedQuery.Encode()
+
+	tests := []struct {
+		name                string
+		url                 string
+		expectedStatus      int
+		expectedSubmittedBy string
+	}{
+		{
+			name:                "success - recipient's link",
+			url:                 recipientLink,
+			expectedStatus:      http.StatusOK,
+			expectedSubmittedBy: testRecipient,
+		},
+		{
+			name:           "failed - recipient's signature used for another recipient",
+			url:            forgedLink.String(),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "failed - signature for a recipient the portal isn't shared with",
+			url:            testPortalUrl + "/?recipient=" + testUnknownRecipient + "&token=" + handler.RecipientSignature(testUnknownRecipient),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "failed - access token link isn't accepted",
+			url:            portal.AccessLink(testPortalUrl, testRecipientKey),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "failed - recipient's link signed with another run's key",
+			url:            otherRunHandler.RecipientLink(testPortalUrl, testRecipient),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "failed - recipient's link without its signature",
+			url:            testPortalUrl + "/?recipient=" + testRecipient,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			submittedBy = ""
+
+			// API requests aren't redirected, so the recipient reaches the handler
+			request := httptest.NewRequest(http.MethodPost, tt.url, nil)
+
+			recorder := httptest.NewRecorder()
+			recipientHandler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedSubmittedBy, submittedBy)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			// the session cookie is attributed to the same recipient
+			sessionRequest := httptest.NewRequest(http.MethodPost, testPortalUrl+"/api/v1/portal/submit", nil)
+			sessionRequest.AddCookie(getSessionCookie(recorder))
+
+			submittedBy = ""
+			sessionRecorder := httptest.NewRecorder()
+			recipientHandler.ServeHTTP(sessionRecorder, sessionRequest)
+
+			assert.Equal(t, http.StatusOK, sessionRecorder.Code)
+			assert.Equal(t, tt.expectedSubmittedBy, submittedBy)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package portal
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+)
+
+// requestClientContextKey is the key of the client making the request in the request's context
+type requestClientContextKey struct{}
+
+// requestClient describes the client making a request
+type requestClient struct {
+
+	// address is the address of the client
+	address string
+
+	// userAgent is the user agent of the client
+	userAgent string
+}
+
+// IdentifyClient adds the address and user agent of the client making the request to the
+// request's context
+func (h *Handler) IdentifyClient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := requestClient{
+			address:   h.clientAddress(r),
+			userAgent: r.UserAgent(),
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestClientContextKey{}, client)))
+	})
+}
+
+// NewAuditEvent returns an audit event of the given type, attributed to the client (and the
+// recipient whose link was used) making the request the context belongs to
+func NewAuditEvent(ctx context.Context, eventType string, details map[string]any) audit.Event {
+	client, _ := ctx.Value(requestClientContextKey{}).(requestClient)
+	submittedBy, _ := ctx.Value(submittedByContextKey{}).(string)
+
+	return audit.Event{
+		Type:      eventType,
+		Client:    client.address,
+		UserAgent: client.userAgent,
+		Actor:     submittedBy,
+		Details:   details,
+	}
+}
+
+// recordAuditEvent records an audit event of the given type for the request the context belongs to
+func (h *Handler) recordAuditEvent(ctx context.Context, eventType string, details map[string]any) {
+	h.auditLog.Record(NewAuditEvent(ctx, eventType, details))
+}
+
+// redactedValues returns the submitted values for the audit log, with the values of secret
+// fields (or group fields with secret sub-fields) redacted
+func (h *Handler) redactedValues(values url.Values) map[string]string {
+	redacted := make(map[string]string, len(values))
+
+	for label, value := range values {
+		if field := h.getField(label); field != nil && isSecretField(field) {
+			redacted[label] = audit.RedactedValue
+			continue
+		}
+
+		redacted[label] = strings.Join(value, ",")
+	}
+
+	return redacted
+}
+
+// isSecretField returns true when the field, or one of its sub-fields, is secret
+func isSecretField(field *fields.Field) bool {
+	if field.Properties.Secret {
+		return true
+	}
+
+	for _, subField := range field.Properties.SubFields {
+		if subField.Properties.Secret {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,104 @@
+package portal_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+// readAuditEvents returns the events recorded to the audit log's file
+func readAuditEvents(t *testing.T, filePath string) []audit.Event {
+	var events []audit.Event
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var event audit.Event
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestNewAuditEvent(t *testing.T) {
+
+	var event audit.Event
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		Recipients:       []string{testRecipient},
+		RecipientLinkKey: testRecipientKey,
+	})
+
+	identified := handler.IdentifyClient(handler.RequireAccessToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event = portal.NewAuditEvent(r.Context(), audit.EventInputsSubmitted, map[string]any{"comment": "https://github.com/o/r/pull/1#c1"})
+	})))
+
+	request := httptest.NewRequest(http.MethodPost, handler.RecipientLink(testPortalUrl, testRecipient), nil)
+	request.RemoteAddr = "203.0.113.7:5000"
+	request.Header.Set("User-Agent", "curl/8.0")
+	identified.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, audit.Event{
+		Type:      audit.EventInputsSubmitted,
+		Client:    "203.0.113.7",
+		UserAgent: "curl/8.0",
+		Actor:     testRecipient,
+		Details:   map[string]any{"comment": "https://github.com/o/r/pull/1#c1"},
+	}, event)
+}
+
+func TestHandler_SubmitReplyRedactsSecrets(t *testing.T) {
+
+	auditLogPath := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	auditLog, err := audit.NewLog(&audit.NewLogRequest{
+		FilePath:  auditLogPath,
+		ActionPkg: githubactions.New(githubactions.WithWriter(io.Discard)),
+	})
+	assert.NoError(t, err)
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		IsRunningLocal: true,
+		AuditLog:       auditLog,
+		Fields: &fields.Fields{Fields: []fields.Field{
+			{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+			{Label: "api-key", Properties: fields.FieldProperties{Type: "text", Secret: true}},
+			{Label: "deploy-keys", Properties: fields.FieldProperties{Type: "group", SubFields: []fields.Field{
+				{Label: "key", Properties: fields.FieldProperties{Type: "text", Secret: true}},
+			}}},
+		}},
+	})
+
+	handler.SubmitReply(url.Values{
+		"name":        {"Leon"},
+		"api-key":     {"s3cr3t-api-key"},
+		"deploy-keys": {`[{"key":"ssh-ed25519 AAAA"}]`},
+	}, "leon", "https://github.com/o/r/pull/1#c1")
+
+	assert.NoError(t, auditLog.Close())
+
+	events := readAuditEvents(t, auditLogPath)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, audit.EventInputsSubmitted, events[0].Type)
+		assert.Equal(t, "leon", events[0].Actor)
+		assert.Equal(t, map[string]any{
+			"name":        "Leon",
+			"api-key":     audit.RedactedValue,
+			"deploy-keys": audit.RedactedValue,
+		}, events[0].Details["values"])
+	}
+}
@@ -1,9 +1,62 @@
 package portal
 
 const (
+	// FieldValidationFailedEventName holds the name of the event triggered on the portal
+	// when submitted field value(s) are rejected
+	FieldValidationFailedEventName = "field-validation-failed"
+
 	// InputFieldLabelUriVariableId holds the identifer used for the input label in the URI
 	InputFieldLabelUriVariableId = "inputFieldVariableId"
 
+	// UploadInputFieldLabelQueryParam holds the query parameter used for the label of the
+	// input field file(s) are uploaded for
+	UploadInputFieldLabelQueryParam = "label"
+
+	// UploadSessionIdUriVariableId holds the identifer used for the upload session id in the URI
+	UploadSessionIdUriVariableId = "uploadSessionId"
+
+	// UploadFileIndexUriVariableId holds the identifer used for the index of a file of an upload session in the URI
+	UploadFileIndexUriVariableId = "uploadFileIndex"
+
+	// UploadOffsetHeader holds the header used for the offset a chunk of an upload session's file
+	// is written at, and the offset the next chunk is expected at
+	UploadOffsetHeader = "Upload-Offset"
+
+	// ManifestOutputSuffix holds the suffix of the output holding the path of a file/multifile
+	// field's file manifest
+	ManifestOutputSuffix = "-manifest"
+
+	// ExtractedFilesOutputSuffix holds the suffix of the output listing the files extracted from
+	// a file/multifile field's archives
+	ExtractedFilesOutputSuffix = "-extracted"
+
+	// ChecksumsOutputSuffix holds the suffix of the output holding the SHA-256 checksums of a
+	// file/multifile field's files
+	ChecksumsOutputSuffix = "-sha256"
+
+	// AccessTokenQueryParam holds the query parameter the portal's access token is provided with
+	AccessTokenQueryParam = "token"
+
+	// SessionCookieName holds the name of the cookie the portal's access token is exchanged for
+	SessionCookieName = "iaip_session"
+
+	// RecipientQueryParam holds the query parameter naming the recipient a portal link was made for
+	RecipientQueryParam = "recipient"
+
+	// CsrfCookieName holds the name of the cookie holding the portal's CSRF token, which the
+	// portal's scripts send back in the CsrfTokenHeader
+	CsrfCookieName = "iaip_csrf"
+
+	// CsrfTokenHeader holds the header requests that change the portal's state must send the CSRF token in
+	CsrfTokenHeader = "X-CSRF-Token"
+
+	// SubmittedByOutput holds the name of the output holding the recipient who submitted the inputs
+	SubmittedByOutput = "submitted-by"
+
+	// CancelledOutput holds the name of the output set to true when the portal is cancelled with
+	// the succeed-with-flag cancel behaviour
+	CancelledOutput = "cancelled"
+
 	// ErrKeyInvalidInputFieldId is returned when the input field label cannot be found for
 	// a targetted request
 	ErrKeyInvalidInputFieldId = "InvalidInputFieldId"
@@ -20,4 +73,67 @@ const (
 
 	// ErrKeyUnableToRemoveCacheDirContents is returned when the cache directory contents cannot be removed
 	ErrKeyUnableToRemoveCacheDirContents = "UnableToRemoveCacheDirContents"
+
+	// ErrKeyMalformedUploadRequest is returned when the upload request can't be read as multipart form data
+	ErrKeyMalformedUploadRequest = "MalformedUploadRequest"
+
+	// ErrKeyFileTooLarge is returned when an uploaded file is larger than the input field's maxFileSize
+	ErrKeyFileTooLarge = "FileTooLarge"
+
+	// ErrKeyUploadTooLarge is returned when the uploaded files are larger than the input field's upload limit
+	ErrKeyUploadTooLarge = "UploadTooLarge"
+
+	// ErrKeyTooManyFilesProvided is returned when more files are uploaded than the input field's maxFiles
+	ErrKeyTooManyFilesProvided = "TooManyFilesProvided"
+
+	// ErrKeyInputFieldNotAFileField is returned when files are uploaded for an input field that
+	// isn't a file or multifile field
+	ErrKeyInputFieldNotAFileField = "InputFieldNotAFileField"
+
+	// ErrKeyFileTypeNotAccepted is returned when an uploaded file isn't one of the input field's accepted file types
+	ErrKeyFileTypeNotAccepted = "FileTypeNotAccepted"
+
+	// ErrKeyMalformedUploadSessionRequest is returned when the request to start an upload session can't be read
+	ErrKeyMalformedUploadSessionRequest = "MalformedUploadSessionRequest"
+
+	// ErrKeyUploadSessionNotFound is returned when the upload session doesn't exist, i.e. it has completed or expired
+	ErrKeyUploadSessionNotFound = "UploadSessionNotFound"
+
+	// ErrKeyUploadFileNotFound is returned when the upload session doesn't have a file at the given index
+	ErrKeyUploadFileNotFound = "UploadFileNotFound"
+
+	// ErrKeyMalformedUploadChunk is returned when a chunk is sent without a valid offset, or is larger
+	// than the chunk size or the rest of the file
+	ErrKeyMalformedUploadChunk = "MalformedUploadChunk"
+
+	// ErrKeyUploadOffsetMismatch is returned when a chunk is sent for an offset other than the one expected
+	ErrKeyUploadOffsetMismatch = "UploadOffsetMismatch"
+
+	// ErrKeyUploadIncomplete is returned when an upload session is completed before all of its files are received
+	ErrKeyUploadIncomplete = "UploadIncomplete"
+
+	// ErrKeyTooManyUploadSessions is returned when an upload session is started while too many are in progress
+	ErrKeyTooManyUploadSessions = "TooManyUploadSessions"
+
+	// ErrKeyArchiveNotExtracted is returned when an uploaded archive can't be safely extracted within the input field's limits
+	ErrKeyArchiveNotExtracted = "ArchiveNotExtracted"
+
+	// ErrKeyUploadNotScanned is returned when an uploaded file can't be scanned by the upload scanner
+	ErrKeyUploadNotScanned = "UploadNotScanned"
+
+	// ErrKeyUploadFlaggedByScanner is returned when the upload scanner flags an uploaded file
+	ErrKeyUploadFlaggedByScanner = "UploadFlaggedByScanner"
+
+	// ErrKeyPortalAccessDenied is returned when a request is made without the portal's access token or session cookie
+	ErrKeyPortalAccessDenied = "PortalAccessDenied"
+
+	// ErrKeyPortalRequestForgeryDetected is returned when a request that changes the portal's state is made
+	// from another origin or without the portal's CSRF token
+	ErrKeyPortalRequestForgeryDetected = "PortalRequestForgeryDetected"
+
+	// ErrKeyPortalRateLimited is returned when a client makes more requests than the portal's rate limit allows
+	ErrKeyPortalRateLimited = "PortalRateLimited"
+
+	// ErrKeyPortalLockedOut is returned when a client is locked out after too many failed access attempts
+	ErrKeyPortalLockedOut = "PortalLockedOut"
 )
@@ -13,4 +13,24 @@ var portalErrorMap = map[string]reply.ErrorManifestItem{
 	ErrKeyNoInputFieldCacheDirFound:      {Title: "Bad Request", Detail: "No cache directory found for input field label", StatusCode: http.StatusBadRequest},
 	ErrKeyUnableToReadCacheDir:           {Title: "Internal Server Error", Detail: "Unable to read cache directory", StatusCode: http.StatusInternalServerError},
 	ErrKeyUnableToRemoveCacheDirContents: {Title: "Internal Server Error", Detail: "Unable to remove cache directory content(s)", StatusCode: http.StatusInternalServerError},
+	ErrKeyMalformedUploadRequest:         {Title: "Bad Request", Detail: "Upload request malformatted. Verify file(s) are submitted as multipart form data", StatusCode: http.StatusBadRequest},
+	ErrKeyFileTooLarge:                   {Title: "Request Entity Too Large", Detail: "An uploaded file exceeds the maximum file size of the input field", StatusCode: http.StatusRequestEntityTooLarge},
+	ErrKeyUploadTooLarge:                 {Title: "Request Entity Too Large", Detail: "The uploaded file(s) exceed the maximum total size of the input field", StatusCode: http.StatusRequestEntityTooLarge},
+	ErrKeyTooManyFilesProvided:           {Title: "Request Entity Too Large", Detail: "More files were uploaded than the input field accepts", StatusCode: http.StatusRequestEntityTooLarge},
+	ErrKeyInputFieldNotAFileField:        {Title: "Bad Request", Detail: "Target input field is not a file or multifile field", StatusCode: http.StatusBadRequest},
+	ErrKeyFileTypeNotAccepted:            {Title: "Unsupported Media Type", Detail: "An uploaded file is not one of the accepted file types of the input field", StatusCode: http.StatusUnsupportedMediaType},
+	ErrKeyMalformedUploadSessionRequest:  {Title: "Bad Request", Detail: "Upload session request malformatted. Verify the name and size of each file are provided", StatusCode: http.StatusBadRequest},
+	ErrKeyUploadSessionNotFound:          {Title: "Not Found", Detail: "Upload session not found, it may have completed or expired", StatusCode: http.StatusNotFound},
+	ErrKeyUploadFileNotFound:             {Title: "Not Found", Detail: "Upload session has no file at the given index", StatusCode: http.StatusNotFound},
+	ErrKeyMalformedUploadChunk:           {Title: "Bad Request", Detail: "Upload chunk malformatted. Verify the Upload-Offset header is provided and the chunk fits within the chunk size and the file", StatusCode: http.StatusBadRequest},
+	ErrKeyUploadOffsetMismatch:           {Title: "Conflict", Detail: "Upload chunk sent for an unexpected offset, resume from the offset provided", StatusCode: http.StatusConflict},
+	ErrKeyUploadIncomplete:               {Title: "Conflict", Detail: "Not all the files of the upload session have been received", StatusCode: http.StatusConflict},
+	ErrKeyTooManyUploadSessions:          {Title: "Too Many Requests", Detail: "Too many uploads are in progress, wait for one to finish and try again", StatusCode: http.StatusTooManyRequests},
+	ErrKeyArchiveNotExtracted:            {Title: "Unprocessable Entity", Detail: "An uploaded archive could not be safely extracted within the input field's limits", StatusCode: http.StatusUnprocessableEntity},
+	ErrKeyUploadNotScanned:               {Title: "Bad Gateway", Detail: "An uploaded file could not be scanned, so the upload was rejected", StatusCode: http.StatusBadGateway},
+	ErrKeyUploadFlaggedByScanner:         {Title: "Unprocessable Entity", Detail: "An uploaded file was flagged by the upload scanner, so the upload was rejected", StatusCode: http.StatusUnprocessableEntity},
+	ErrKeyPortalAccessDenied:             {Title: "Unauthorized", Detail: "The portal can only be used from the link that was shared with you", StatusCode: http.StatusUnauthorized},
+	ErrKeyPortalRequestForgeryDetected:   {Title: "Forbidden", Detail: "The request couldn't be verified, reload the portal and try again", StatusCode: http.StatusForbidden},
+	ErrKeyPortalRateLimited:              {Title: "Too Many Requests", Detail: "Too many requests have been made to the portal, wait a moment and try again", StatusCode: http.StatusTooManyRequests},
+	ErrKeyPortalLockedOut:                {Title: "Too Many Requests", Detail: "Too many attempts have been made to use the portal with an invalid link, try again later", StatusCode: http.StatusTooManyRequests},
 }
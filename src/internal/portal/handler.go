@@ -1,17 +1,32 @@
 package portal
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 
+	"github.com/boasihq/interactive-inputs/internal/archive"
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+	"github.com/boasihq/interactive-inputs/internal/metrics"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
 	"github.com/gorilla/mux"
 	"github.com/ooaklee/reply"
 	"github.com/sethvargo/go-githubactions"
@@ -27,6 +42,7 @@ type actionPkg interface {
 	Errorf(msg string, args ...any)
 	Fatalf(msg string, args ...any)
 	SetOutput(k string, v string)
+	AddMask(p string)
 }
 
 // Handler manages portal requests
@@ -49,20 +65,175 @@ type Handler struct {
 
 	// inputFieldLabelToCacheDirMapping mapping of input field label to its cache directory
 	inputFieldLabelToCacheDirMapping map[string]string
+
+	// fields the fields displayed on the portal
+	fields *fields.Fields
+
+	// uploadSessions holds the chunked upload sessions in progress, keyed by their id
+	uploadSessions map[string]*uploadSession
+
+	// uploadSessionsMutex guards uploadSessions
+	uploadSessionsMutex sync.Mutex
+
+	// uploadScanner scans the uploaded files before they are stored, nil when uploads aren't scanned
+	uploadScanner scanner.Scanner
+
+	// quarantineFlaggedUploads is true when flagged files are quarantined rather than the upload rejected
+	quarantineFlaggedUploads bool
+
+	// onSubmitted is called with whoever submitted the inputs once they have been handled
+	onSubmitted func(submittedBy string)
+
+	// onCancelled is called with whoever cancelled the portal
+	onCancelled func(cancelledBy string)
+
+	// accessToken is the token the portal can only be used with, empty when it isn't required
+	accessToken string
+
+	// recipients are who the portal is shared with, each with their own signed link
+	recipients []string
+
+	// recipientLinkKey is the key the recipients' links are signed with
+	recipientLinkKey string
+
+	// accessLinksRevoked is true once the portal is closing, after which its links aren't accepted
+	accessLinksRevoked atomic.Bool
+
+	// rateLimiter limits the requests each client can make to the submit, upload and reset routes,
+	// nil when requests aren't rate limited
+	rateLimiter *clientLimiter
+
+	// authLockout locks clients out after too many failed access attempts, nil when clients aren't locked out
+	authLockout *clientLimiter
+
+	// trustForwardedFor is true when the portal is reached through a tunnel provider that adds
+	// the client's address to the X-Forwarded-For header itself
+	trustForwardedFor bool
+
+	// onLimitTriggered is called with a description of the rate limit or lockout that was triggered
+	onLimitTriggered func(message string)
+
+	// auditLog records the portal's activity, nil when it isn't audited
+	auditLog *audit.Log
+
+	// metrics collects the portal's metrics, nil when they aren't collected
+	metrics *metrics.Collector
+
+	// jobUrl is the URL of the job the action is running in, empty when it isn't known
+	jobUrl string
+
+	// ready is true once the notifiers have been verified and the portal is being served
+	ready atomic.Bool
+}
+
+// NewHandlerRequest holds everything needed to create a portal handler
+type NewHandlerRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg actionPkg
+
+	// IsRunningLocal is true when running locally
+	IsRunningLocal bool
+
+	// EmbeddedContent embedded content of the web app
+	EmbeddedContent fs.FS
+
+	// EmbeddedContentFilePathPrefix path prefix of the embedded content
+	EmbeddedContentFilePathPrefix string
+
+	// GithubToken is the github token used to make Api calls
+	GithubToken string
+
+	// InputFieldLabelToCacheDirMapping mapping of input field label to its cache directory
+	InputFieldLabelToCacheDirMapping map[string]string
+
+	// Fields the fields displayed on the portal
+	Fields *fields.Fields
+
+	// UploadScanner scans the uploaded files before they are stored (optional)
+	UploadScanner scanner.Scanner
+
+	// QuarantineFlaggedUploads is true when flagged files are quarantined rather than the upload rejected
+	QuarantineFlaggedUploads bool
+
+	// OnSubmitted is called with whoever submitted the inputs once they have been handled,
+	// the process exits when not provided
+	OnSubmitted func(submittedBy string)
+
+	// OnCancelled is called with whoever cancelled the portal, the step fails straight away
+	// when not provided
+	OnCancelled func(cancelledBy string)
+
+	// AccessToken is the token the portal can only be used with (optional)
+	AccessToken string
+
+	// Recipients are who the portal is shared with, each with their own signed link (optional)
+	Recipients []string
+
+	// RecipientLinkKey is the key the recipients' links are signed with, generated for each run
+	RecipientLinkKey string
+
+	// RateLimitRequests is how many requests each client can make to the submit, upload and reset
+	// routes within the RateLimitWindow. Requests aren't rate limited when 0
+	RateLimitRequests int
+
+	// RateLimitWindow is the window the RateLimitRequests are counted over
+	RateLimitWindow time.Duration
+
+	// AuthLockoutAttempts is how many failed access attempts each client can make within the
+	// AuthLockoutWindow before it is locked out for the window. Clients aren't locked out when 0
+	AuthLockoutAttempts int
+
+	// AuthLockoutWindow is the window the AuthLockoutAttempts are counted over
+	AuthLockoutWindow time.Duration
+
+	// OnLimitTriggered is called with a description of the rate limit or lockout that was triggered (optional)
+	OnLimitTriggered func(message string)
+
+	// AuditLog records the portal's activity (optional)
+	AuditLog *audit.Log
+
+	// Metrics collects the portal's metrics (optional)
+	Metrics *metrics.Collector
+
+	// JobUrl is the URL of the job the action is running in, which the portal links back to.
+	// The URL of the run is used when not provided
+	JobUrl string
 }
 
 // NewHandler returns portal handler
-func NewHandler(actionPkg actionPkg, isRunningLocal bool, embeddedContent fs.FS, embeddedContentFilePathPrefix, githubToken string, inputFieldLabelToCacheDirMapping map[string]string) *Handler {
+func NewHandler(r *NewHandlerRequest) *Handler {
 	return &Handler{
-		isRunningLocal:                   isRunningLocal,
-		actionPkg:                        actionPkg,
-		embeddedContent:                  embeddedContent,
-		embeddedContentFilePathPrefix:    embeddedContentFilePathPrefix,
-		githubToken:                      githubToken,
-		inputFieldLabelToCacheDirMapping: inputFieldLabelToCacheDirMapping,
+		isRunningLocal:                   r.IsRunningLocal,
+		actionPkg:                        r.ActionPkg,
+		embeddedContent:                  r.EmbeddedContent,
+		embeddedContentFilePathPrefix:    r.EmbeddedContentFilePathPrefix,
+		githubToken:                      r.GithubToken,
+		inputFieldLabelToCacheDirMapping: r.InputFieldLabelToCacheDirMapping,
+		fields:                           r.Fields,
+		uploadSessions:                   make(map[string]*uploadSession),
+		uploadScanner:                    r.UploadScanner,
+		quarantineFlaggedUploads:         r.QuarantineFlaggedUploads,
+		onSubmitted:                      r.OnSubmitted,
+		onCancelled:                      r.OnCancelled,
+		accessToken:                      r.AccessToken,
+		recipients:                       r.Recipients,
+		recipientLinkKey:                 r.RecipientLinkKey,
+		rateLimiter:                      newClientLimiter(r.RateLimitRequests, r.RateLimitWindow),
+		authLockout:                      newClientLimiter(r.AuthLockoutAttempts, r.AuthLockoutWindow),
+		onLimitTriggered:                 r.OnLimitTriggered,
+		auditLog:                         r.AuditLog,
+		metrics:                          r.Metrics,
+		jobUrl:                           r.JobUrl,
 	}
 }
 
+// SetTrustForwardedFor sets whether the client's address is taken from the X-Forwarded-For header,
+// before the portal starts serving requests
+func (h *Handler) SetTrustForwardedFor(trustForwardedFor bool) {
+	h.trustForwardedFor = trustForwardedFor
+}
+
 // CancelPortal returns response for request to cancel the portal
 func (h *Handler) CancelPortal(w http.ResponseWriter, r *http.Request) {
 
@@ -77,14 +248,7 @@ func (h *Handler) CancelPortal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	repoOwner, repoName := actionContext.Repo()
-
-	additionalContext["JobUrl"] = fmt.Sprintf(
-		"%s/%s/actions/runs/%d",
-		actionContext.ServerURL,
-		repoOwner+"/"+repoName,
-		actionContext.RunID,
-	)
+	additionalContext["JobUrl"] = githubapi.JobOrRunUrl(h.jobUrl, actionContext)
 
 	// Parse template
 	parsedTemplates, err := template.ParseFS(h.embeddedContent, fmt.Sprintf("%sweb/ui/html/partials/responses/cancel.tmpl.html", h.embeddedContentFilePathPrefix))
@@ -94,6 +258,13 @@ func (h *Handler) CancelPortal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the portal is closing, so its links can't be used anymore. Only the first of concurrent
+	// cancellations or submissions, through the portal or a reply, closes it
+	if !h.RevokeAccessLinks() {
+		h.respondWithAccessDenied(w, r)
+		return
+	}
+
 	// Added templates needed for htmx replacement
 	w.Header().Set("HX-Trigger", "template-executed")
 	w.Header().Set("HX-Trigger-After-Swap", "template-swapped")
@@ -108,15 +279,24 @@ func (h *Handler) CancelPortal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.actionPkg.Infof("Cancel request received")
+	h.recordAuditEvent(r.Context(), audit.EventPortalCancelled, nil)
 
-	go func(actionContext *githubactions.GitHubContext) {
+	cancelledBy := SubmittedBy(r)
+	if cancelledBy != "" {
+		h.actionPkg.Infof("Cancel request received from %s", cancelledBy)
+	} else {
+		h.actionPkg.Infof("Cancel request received")
+	}
 
-		runId := actionContext.RunID
-		h.actionPkg.Infof("Cancelling job within run %d", runId)
+	// shut the portal down in background, once the response has been shown
+	go func(actionContext *githubactions.GitHubContext) {
 		time.Sleep(3 * time.Second)
+		h.removeUploadSessionsIdleSince(time.Now())
 
-		h.actionPkg.Fatalf("Job within run %d cancelled", runId)
+		if h.onCancelled == nil {
+			h.actionPkg.Fatalf("Job within run %d cancelled", actionContext.RunID)
+		}
+		h.onCancelled(cancelledBy)
 
 	}(actionContext)
 
@@ -129,53 +309,31 @@ func (h *Handler) SubmitPortal(w http.ResponseWriter, r *http.Request) {
 		"JobUrl": "",
 	}
 
-	if h.isRunningLocal {
-		h.actionPkg.Infof("Running locally, will only print the form data to stdout")
+	// validate and normalise the values of fields that can't be
+	// submitted as-is
+	fieldValidationErrors := h.normaliseSubmittedFormValues(r.Form)
+	if len(fieldValidationErrors) > 0 {
+		h.respondWithFieldValidationErrors(w, fieldValidationErrors)
+		return
 	}
 
-	for key, value := range r.Form {
-
-		// handle file/multifile inputs
-		if cacheDir := h.getInputFieldCacheDir(key); cacheDir != "" {
-
-			h.actionPkg.Infof("%s: %s", key, cacheDir)
-
-			if !h.isRunningLocal {
-				// Can't use when running locally
-				h.actionPkg.SetOutput(key, cacheDir)
-			}
-
-			continue
-		}
-
-		h.actionPkg.Infof("%s: %s", key, value)
-
-		if !h.isRunningLocal {
-			// Can't use when running locally
-			h.actionPkg.SetOutput(key, strings.Join(value, ","))
-		}
+	// the portal is closing, so its links can't be used to submit again. Only the first of
+	// concurrent submissions, through the portal or a reply, is output
+	if !h.RevokeAccessLinks() {
+		h.respondWithAccessDenied(w, r)
+		return
 	}
 
+	submittedBy := SubmittedBy(r)
+	h.outputSubmission(r.Context(), r.Form, submittedBy, map[string]any{})
+
 	actionContext, err := h.actionPkg.Context()
 	if err != nil {
 		h.actionPkg.Errorf("Unable to get action context: %v", zap.Error(err))
 	}
 
 	if err == nil {
-		repoOwner, repoName := actionContext.Repo()
-
-		additionalContext["JobUrl"] = fmt.Sprintf(
-			"%s/%s/actions/runs/%d",
-			actionContext.ServerURL,
-			repoOwner+"/"+repoName,
-			actionContext.RunID,
-		)
-
-		// TODO: Figure out if there is a way to get the the exact job Id of current
-		// job.
-		// if actionContext.Job != "" {
-		// 	additionalContext["JobUrl"] = additionalContext["JobUrl"] + "/job/" + actionContext.Job
-		// }
+		additionalContext["JobUrl"] = githubapi.JobOrRunUrl(h.jobUrl, actionContext)
 	}
 
 	// Parse template
@@ -202,157 +360,522 @@ func (h *Handler) SubmitPortal(w http.ResponseWriter, r *http.Request) {
 
 	h.actionPkg.Infof("Your inputs have successfully been received!")
 
-	// put an exit command in background so that the action can finish
+	// shut the portal down in background so that the action can finish
 	go func() {
 		time.Sleep(5 * time.Second)
-		os.Exit(0)
+		h.removeUploadSessionsIdleSince(time.Now())
+
+		if h.onSubmitted == nil {
+			os.Exit(0)
+		}
+		h.onSubmitted(submittedBy)
 	}()
 }
 
+// outputSubmission outputs the submitted values, along with who submitted them, recording the
+// submission with the given audit details. The portal's links must already have been revoked
+func (h *Handler) outputSubmission(ctx context.Context, values url.Values, submittedBy string, auditDetails map[string]any) {
+	if submittedBy != "" {
+		h.actionPkg.Infof("%s: %s", SubmittedByOutput, submittedBy)
+		if !h.isRunningLocal {
+			// Can't use when running locally
+			h.actionPkg.SetOutput(SubmittedByOutput, submittedBy)
+		}
+	}
+
+	auditDetails["values"] = h.redactedValues(values)
+	h.recordAuditEvent(ctx, audit.EventInputsSubmitted, auditDetails)
+	h.metrics.ObserveSubmit()
+
+	OutputSubmittedValues(&OutputSubmittedValuesRequest{
+		ActionPkg:                        h.actionPkg,
+		IsRunningLocal:                   h.isRunningLocal,
+		InputFieldLabelToCacheDirMapping: h.inputFieldLabelToCacheDirMapping,
+		Fields:                           h.fields,
+		Values:                           values,
+	})
+}
+
 // UploadToPortal returns response for request to upload file(s) to portal
-// for later use
+// for later use. The file(s) are streamed to disk as they are received, and
+// only replace the files previously uploaded for the input field once the
+// whole upload has been received within the input field's limits.
 func (h *Handler) UploadToPortal(w http.ResponseWriter, r *http.Request) {
 
 	const indexKeySplitter string = "__index__"
-	var totalFiles int
 	var fileCount int = 0
-	var successFileUploads []string = []string{}
+	var totalUploadSize int64 = 0
+	var stagedFiles []stagedUploadFile = []stagedUploadFile{}
+	var fileNamesInUse map[string]bool = map[string]bool{fields.FileManifestName: true}
 	var failedFileUploads []string = []string{}
-	var cleanExistingCacheDir bool = true
-	var cacheCleanOverviewTmpl string = `
-Cache clean overview:
-	• Status: %s
-	• Total Files: %d
-	• Total Files Deleted: %d
-	• Deleted Files: %+v
-	• Failed Files: %+v
-	• Error: %+v
-				`
 
 	h.actionPkg.Infof("Uploading File(s)...")
 
-	r.ParseMultipartForm(10 << 20)
+	// Get the input field the file(s) are uploaded for
+	inputField := h.getUploadInputField(w, r)
+	if inputField == nil {
+		return
+	}
+	inputFieldLabel := inputField.Label
+	inputCacheDir := h.getInputFieldCacheDir(inputFieldLabel)
 
-	// If no files are uploaded, return an error
-	if r.MultipartForm == nil {
+	limits := getUploadLimits(inputField)
+	if limits.maxUploadSize == 0 {
+		limits.maxUploadSize = defaultMaxUploadSize
+	}
+	h.actionPkg.Debugf("  • Upload limits for %s: %+v", inputFieldLabel, limits)
+
+	// Refuse the upload early if it's declared to be too large, and
+	// make sure it can't grow past the limit while being received
+	maxRequestSize := limits.maxUploadSize + multipartOverheadAllowance
+	if r.ContentLength > maxRequestSize {
+		h.actionPkg.Errorf("Upload for %s is too large (%s)", inputFieldLabel, toolbox.BytesToHumanReadable(r.ContentLength))
+		h.respondWithUploadLimitExceeded(w, ErrKeyUploadTooLarge, toolbox.BytesToHumanReadable(limits.maxUploadSize))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+
+	multipartReader, err := r.MultipartReader()
+	if err != nil {
 		h.actionPkg.Errorf("No files detected in upload request")
 
-		//nolint will set up default fallback later
-		getBaseResponseHandler().NewHTTPErrorResponse(w, errors.New(ErrNoFilesProvidedWithUploadRequest))
+		h.respondWithError(w, errors.New(ErrNoFilesProvidedWithUploadRequest))
 		return
 	}
 
-	// Get a reference to the parsed multipart form
-	form := r.MultipartForm
-	files := form.File
+	// Stage the file(s) next to the input field's cache directory
+	stagingDir, err := os.MkdirTemp(filepath.Dir(inputCacheDir), fmt.Sprintf(".staging-%s-", inputFieldLabel))
+	if err != nil {
+		h.actionPkg.Errorf("Unable to create staging directory for upload: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(stagingDir)
+
+	for {
+		part, err := multipartReader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				h.actionPkg.Errorf("Upload for %s exceeded the limit of %s", inputFieldLabel, toolbox.BytesToHumanReadable(limits.maxUploadSize))
+				h.respondWithUploadLimitExceeded(w, ErrKeyUploadTooLarge, toolbox.BytesToHumanReadable(limits.maxUploadSize))
+				return
+			}
+
+			h.actionPkg.Errorf("Unable to read upload request: %v", err)
 
-	totalFiles = len(files)
-	h.actionPkg.Debugf("Total pushed files: %d", totalFiles)
+			h.respondWithError(w, errors.New(ErrKeyMalformedUploadRequest))
+			return
+		}
 
-	// Get a reference to the parsed file
-	for k, _ := range files {
+		// skip any parts that aren't files
+		originalFileName := getPartOriginalFileName(part)
+		if originalFileName == "" {
+			part.Close()
+			continue
+		}
 
 		fileCount++
 
-		h.actionPkg.Infof("  • [%d of %d] Initiating file upload flow", fileCount, totalFiles)
+		h.actionPkg.Infof("  • [%d] Initiating file upload flow", fileCount)
 
-		file, handler, err := r.FormFile(k)
-		if err != nil {
-			h.actionPkg.Errorf("[%d of %d] Error Retrieving the file: %v", fileCount, totalFiles, err)
-			failedFileUploads = append(failedFileUploads, k)
+		if limits.maxFiles > 0 && fileCount > limits.maxFiles {
+			h.actionPkg.Errorf("Upload for %s exceeded the limit of %d file(s)", inputFieldLabel, limits.maxFiles)
+			h.respondWithUploadLimitExceeded(w, ErrKeyTooManyFilesProvided, strconv.Itoa(limits.maxFiles))
+			return
+		}
+
+		// split index from file name to get the input name
+		partInputFieldLabel := strings.Split(part.FormName(), indexKeySplitter)[0]
+		if partInputFieldLabel != inputFieldLabel {
+			h.actionPkg.Errorf("[%d] File %s was provided for %s, expected %s", fileCount, originalFileName, partInputFieldLabel, inputFieldLabel)
+			failedFileUploads = append(failedFileUploads, originalFileName)
+			part.Close()
 			continue
 		}
 
-		defer file.Close()
+		// Sniff the content type from the start of the file
+		partReader := bufio.NewReaderSize(part, contentTypeSniffLength)
+		sniffedContent, _ := partReader.Peek(contentTypeSniffLength)
+		contentType := http.DetectContentType(sniffedContent)
+
+		// Store the file under a name that is safe to use on the runner
+		storedFileName := toolbox.UniqueFileName(toolbox.SanitiseFileName(originalFileName), fileNamesInUse)
+
+		h.actionPkg.Debugf("  • Input Field: %+v", partInputFieldLabel)
+		h.actionPkg.Debugf("  • Uploaded File: %+v", originalFileName)
+		h.actionPkg.Debugf("  • Stored As: %+v", storedFileName)
+		h.actionPkg.Debugf("  • Content Type: %+v", contentType)
+		h.actionPkg.Debugf("  • MIME Header: %+v", part.Header)
+
+		if !inputField.Properties.IsAcceptedFileType(originalFileName, contentType) {
+			h.actionPkg.Errorf("[%d] File %s (%s) is not one of the accepted file types: %s", fileCount, originalFileName, contentType, strings.Join(inputField.Properties.AcceptedFileTypes, ", "))
+
+			h.respondWithError(w, errors.New(ErrKeyFileTypeNotAccepted),
+				reply.WithMeta(map[string]interface{}{
+					"file":     originalFileName,
+					"accepted": inputField.Properties.AcceptedFileTypes,
+				}))
+			return
+		}
 
-		// split index from file name to get the input name
-		indexArray := strings.Split(k, indexKeySplitter)
-		inputFieldLabel := indexArray[0]
+		// Stream file to the staging directory
+		stagedFilePath := filepath.Join(stagingDir, strconv.Itoa(fileCount))
+		fileSize, checksum, err := streamToFile(partReader, stagedFilePath, limits.maxFileSize)
+		part.Close()
+
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesError):
+			h.actionPkg.Errorf("Upload for %s exceeded the limit of %s", inputFieldLabel, toolbox.BytesToHumanReadable(limits.maxUploadSize))
+			h.respondWithUploadLimitExceeded(w, ErrKeyUploadTooLarge, toolbox.BytesToHumanReadable(limits.maxUploadSize))
+			return
+
+		case err == errFileSizeLimitExceeded:
+			h.actionPkg.Errorf("[%d] File %s exceeded the limit of %s", fileCount, originalFileName, toolbox.BytesToHumanReadable(limits.maxFileSize))
+			h.respondWithUploadLimitExceeded(w, ErrKeyFileTooLarge, toolbox.BytesToHumanReadable(limits.maxFileSize))
+			return
+
+		case err != nil:
+			h.actionPkg.Errorf("[%d] Unable to write file to staging directory: %v", fileCount, err)
+			failedFileUploads = append(failedFileUploads, originalFileName)
+			continue
+		}
 
-		h.actionPkg.Debugf("  • Input Field: %+v", inputFieldLabel)
-		h.actionPkg.Debugf("  • Uploaded File: %+v", handler.Filename)
-		h.actionPkg.Debugf("  • File Size: %+v", handler.Size)
-		h.actionPkg.Debugf("  • MIME Header: %+v", handler.Header)
+		h.actionPkg.Debugf("  • File Size: %+v", fileSize)
+		h.actionPkg.Debugf("  • SHA-256: %+v", checksum)
 		h.actionPkg.Debugf("")
 
-		// Read file into byte array
-		fileBytes, err := io.ReadAll(file)
+		totalUploadSize += fileSize
+		if limits.maxUploadSize > 0 && totalUploadSize > limits.maxUploadSize {
+			h.actionPkg.Errorf("Upload for %s exceeded the limit of %s", inputFieldLabel, toolbox.BytesToHumanReadable(limits.maxUploadSize))
+			h.respondWithUploadLimitExceeded(w, ErrKeyUploadTooLarge, toolbox.BytesToHumanReadable(limits.maxUploadSize))
+			return
+		}
+
+		stagedFiles = append(stagedFiles, stagedUploadFile{
+			path:             stagedFilePath,
+			originalFileName: originalFileName,
+			fileName:         storedFileName,
+			size:             fileSize,
+			contentType:      fields.FileContentType(originalFileName, contentType),
+			sha256:           checksum,
+		})
+	}
+
+	// If no files are uploaded, return an error
+	if fileCount == 0 {
+		h.actionPkg.Errorf("No files detected in upload request")
+
+		h.respondWithError(w, errors.New(ErrNoFilesProvidedWithUploadRequest))
+		return
+	}
+
+	h.actionPkg.Debugf("Total pushed files: %d", fileCount)
+
+	h.commitStagedUploads(r.Context(), w, inputFieldLabel, stagedFiles, fileCount, failedFileUploads)
+}
+
+// scanStagedUploads scans the staged file(s) with the upload scanner, when one is configured, returning
+// the file(s) that passed the scan along with the manifest entries of the quarantined file(s)
+func (h *Handler) scanStagedUploads(ctx context.Context, w http.ResponseWriter, inputFieldLabel string, stagedFiles []stagedUploadFile) ([]stagedUploadFile, []fields.FileManifestEntry, bool) {
+	var passedFiles []stagedUploadFile = []stagedUploadFile{}
+	var quarantinedFiles []fields.FileManifestEntry = []fields.FileManifestEntry{}
+
+	if h.uploadScanner == nil {
+		return stagedFiles, quarantinedFiles, true
+	}
+
+	for _, stagedFile := range stagedFiles {
+
+		scanResult, err := h.uploadScanner.Scan(ctx, stagedFile.fileName, stagedFile.path)
 		if err != nil {
-			h.actionPkg.Errorf("[%d of %d] Unable to read file: %v", fileCount, totalFiles, err)
-			failedFileUploads = append(failedFileUploads, handler.Filename)
+			h.actionPkg.Errorf("Unable to scan %s: %v", stagedFile.originalFileName, err)
+
+			h.respondWithError(w, errors.New(ErrKeyUploadNotScanned),
+				reply.WithMeta(map[string]interface{}{"file": stagedFile.originalFileName}))
+			return nil, nil, false
+		}
+
+		stagedFile.scan = &fields.FileScanResult{
+			Scanner: h.uploadScanner.Name(),
+			Flagged: scanResult.Flagged,
+			Detail:  scanResult.Detail,
+		}
+
+		if !scanResult.Flagged {
+			h.actionPkg.Infof("  • %s passed the %s scan", stagedFile.originalFileName, h.uploadScanner.Name())
+			passedFiles = append(passedFiles, stagedFile)
 			continue
 		}
 
-		// if cache dir exists, remove contents
-		if cleanExistingCacheDir {
-			h.actionPkg.Debugf("Cleaning existing cache dir for input field: %s", inputFieldLabel)
+		h.actionPkg.Warningf("%s was flagged by the %s scan: %s", stagedFile.originalFileName, h.uploadScanner.Name(), scanResult.Detail)
 
-			status, totalFilesToDelete, totalFilesDeleted, deletedFiles, failedFiles, err := h.cleanUpCacheDir(inputFieldLabel, true)
-			if err != nil && err.Error() == ErrKeyUnableToRemoveCacheDirContents {
-				failedFileUploads = append(failedFileUploads, handler.Filename)
+		if !h.quarantineFlaggedUploads {
+			h.respondWithError(w, errors.New(ErrKeyUploadFlaggedByScanner),
+				reply.WithMeta(map[string]interface{}{
+					"file":   stagedFile.originalFileName,
+					"reason": scanResult.Detail,
+				}))
+			return nil, nil, false
+		}
 
-				h.actionPkg.Debugf(cacheCleanOverviewTmpl, status, totalFilesToDelete, totalFilesDeleted, deletedFiles, failedFiles, err)
+		quarantinedPath, err := h.quarantineStagedUpload(inputFieldLabel, stagedFile)
+		if err != nil {
+			h.actionPkg.Errorf("Unable to quarantine %s: %v", stagedFile.originalFileName, err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return nil, nil, false
+		}
+		h.actionPkg.Warningf("Quarantined %s to %s", stagedFile.originalFileName, quarantinedPath)
 
-				//nolint will set up default fallback later
-				getBaseResponseHandler().NewHTTPErrorResponse(w, errors.New(ErrKeyUnableToRemoveCacheDirContents),
-					reply.WithMeta(map[string]interface{}{"data": UploadToPortalResponse{
-						Status:        status,
-						UploadedFiles: successFileUploads,
-						FailedFiles:   failedFileUploads,
-					}}))
-				return
-			}
+		manifestEntry := stagedFile.manifestEntry()
+		manifestEntry.QuarantinedTo = quarantinedPath
+		quarantinedFiles = append(quarantinedFiles, manifestEntry)
+	}
 
-			if err != nil {
+	return passedFiles, quarantinedFiles, true
+}
 
-				h.actionPkg.Debugf(cacheCleanOverviewTmpl, status, totalFilesToDelete, totalFilesDeleted, deletedFiles, failedFiles, err)
+// quarantineStagedUpload moves the flagged staged file into the input field's quarantine directory,
+// returning the path it was moved to
+func (h *Handler) quarantineStagedUpload(inputFieldLabel string, stagedFile stagedUploadFile) (string, error) {
 
-				//nolint will set up default fallback later
-				getBaseResponseHandler().NewHTTPErrorResponse(w, err)
-				return
-			}
+	inputCacheDir := h.getInputFieldCacheDir(inputFieldLabel)
+	quarantineDir := filepath.Join(filepath.Dir(inputCacheDir), quarantineDirName, filepath.Base(inputCacheDir))
 
-			if totalFilesDeleted == totalFilesToDelete {
-				h.actionPkg.Debugf("Successfully cleaned up cache dir for input field: %s", inputFieldLabel)
-			}
+	err := os.MkdirAll(quarantineDir, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	// each upload gets its own directory, so flagged files with the same name don't replace each other
+	uploadQuarantineDir, err := os.MkdirTemp(quarantineDir, "upload-")
+	if err != nil {
+		return "", err
+	}
+
+	quarantinedPath := filepath.Join(uploadQuarantineDir, stagedFile.fileName)
+	err = os.Rename(stagedFile.path, quarantinedPath)
+	if err != nil {
+		return "", err
+	}
 
-			cleanExistingCacheDir = false
+	// make sure later steps can't execute the file by accident
+	return quarantinedPath, os.Chmod(quarantinedPath, 0400)
+}
+
+// extractStagedArchives extracts the staged file(s) that are archives when the input field extracts
+// archives, or responds with the error and returns false
+func (h *Handler) extractStagedArchives(w http.ResponseWriter, inputFieldLabel string, stagedFiles []stagedUploadFile) bool {
+	var fileNamesInUse map[string]bool = map[string]bool{fields.FileManifestName: true}
+
+	archiveExtractor, err := h.getField(inputFieldLabel).NewArchiveExtractor()
+	if err != nil {
+		h.actionPkg.Errorf("Unable to determine the extraction limits of %s: %v", inputFieldLabel, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return false
+	}
+
+	for _, stagedFile := range stagedFiles {
+		fileNamesInUse[strings.ToLower(stagedFile.fileName)] = true
+	}
+
+	for i := range stagedFiles {
+		stagedFile := &stagedFiles[i]
+
+		if !archiveExtractor.Extracts(stagedFile.fileName) {
+			continue
 		}
 
-		// create placeholder file in temp directory to hold uploaded file
-		inputCacheDir := h.getInputFieldCacheDir(inputFieldLabel)
-		err = os.WriteFile(fmt.Sprintf("%s/%s", inputCacheDir, handler.Filename), fileBytes, 0644)
+		extraction, err := archiveExtractor.Extract(stagedFile.fileName, stagedFile.path, stagedFile.path+extractedDirSuffix)
 		if err != nil {
-			h.actionPkg.Errorf("[%d of %d] Unable to write file to input field cache dir: %s", fileCount, totalFiles, inputCacheDir)
-			failedFileUploads = append(failedFileUploads, handler.Filename)
+			h.actionPkg.Errorf("Unable to extract archive %s: %v", stagedFile.originalFileName, err)
+
+			h.respondWithError(w, errors.New(ErrKeyArchiveNotExtracted),
+				reply.WithMeta(map[string]interface{}{
+					"file":   stagedFile.originalFileName,
+					"reason": err.Error(),
+				}))
+			return false
+		}
+
+		stagedFile.extractedDirName = toolbox.UniqueFileName(archive.ExtractedDirName(stagedFile.fileName), fileNamesInUse)
+		stagedFile.extraction = extraction
+
+		h.actionPkg.Infof("  • Extracted %d file(s) from %s", len(extraction.Files), stagedFile.originalFileName)
+		if len(extraction.SkippedEntries) > 0 {
+			h.actionPkg.Warningf("Skipped the links and special files of %s: %s", stagedFile.originalFileName, strings.Join(extraction.SkippedEntries, ", "))
+		}
+	}
+
+	return true
+}
+
+// getUploadInputField returns the file/multifile field targeted by the upload request's label
+// query parameter, or responds with the error and returns nil
+func (h *Handler) getUploadInputField(w http.ResponseWriter, r *http.Request) *fields.Field {
+
+	inputFieldLabel := r.URL.Query().Get(UploadInputFieldLabelQueryParam)
+	if inputFieldLabel == "" {
+		h.actionPkg.Errorf("Input field label not found in upload request")
+
+		h.respondWithError(w, errors.New(ErrKeyInvalidInputFieldId))
+		return nil
+	}
+
+	// Only accept uploads for the file and multifile fields of the portal
+	inputField := h.getField(inputFieldLabel)
+	if inputField == nil || !inputField.IsFile() {
+		h.actionPkg.Errorf("Upload request received for %s, which is not a file or multifile field", inputFieldLabel)
+
+		h.respondWithError(w, errors.New(ErrKeyInputFieldNotAFileField))
+		return nil
+	}
+
+	if h.getInputFieldCacheDir(inputFieldLabel) == "" {
+		h.actionPkg.Errorf("No cache directory found for input field label: %s", inputFieldLabel)
+
+		h.respondWithError(w, errors.New(ErrKeyNoInputFieldCacheDirFound))
+		return nil
+	}
+
+	return inputField
+}
+
+// commitStagedUploads replaces the files previously uploaded for the input field with the staged
+// file(s) and responds with the outcome of the upload
+func (h *Handler) commitStagedUploads(ctx context.Context, w http.ResponseWriter, inputFieldLabel string, stagedFiles []stagedUploadFile, fileCount int, failedFileUploads []string) {
+	var successFileUploads []string = []string{}
+	var inputCacheDir string = h.getInputFieldCacheDir(inputFieldLabel)
+	var cacheCleanOverviewTmpl string = `
+Cache clean overview:
+	• Status: %s
+	• Total Files: %d
+	• Total Files Deleted: %d
+	• Deleted Files: %+v
+	• Failed Files: %+v
+	• Error: %+v
+				`
+
+	// Scan the file(s) before anything else touches them
+	stagedFiles, quarantinedFiles, ok := h.scanStagedUploads(ctx, w, inputFieldLabel, stagedFiles)
+	if !ok {
+		return
+	}
+
+	// Extract the archive(s) before the previously uploaded files are removed, so
+	// that they are kept if an archive can't be safely extracted
+	if !h.extractStagedArchives(w, inputFieldLabel, stagedFiles) {
+		return
+	}
+
+	// Remove the previously uploaded files now the new file(s) have been received
+	h.actionPkg.Debugf("Cleaning existing cache dir for input field: %s", inputFieldLabel)
+
+	status, totalFilesToDelete, totalFilesDeleted, deletedFiles, failedFiles, err := h.cleanUpCacheDir(inputFieldLabel, true)
+	if err != nil && err.Error() == ErrKeyUnableToRemoveCacheDirContents {
+
+		h.actionPkg.Debugf(cacheCleanOverviewTmpl, status, totalFilesToDelete, totalFilesDeleted, deletedFiles, failedFiles, err)
+
+		h.respondWithError(w, errors.New(ErrKeyUnableToRemoveCacheDirContents),
+			reply.WithMeta(map[string]interface{}{"data": UploadToPortalResponse{
+				Status:        status,
+				UploadedFiles: successFileUploads,
+				FailedFiles:   failedFileUploads,
+			}}))
+		return
+	}
+
+	if err != nil {
+
+		h.actionPkg.Debugf(cacheCleanOverviewTmpl, status, totalFilesToDelete, totalFilesDeleted, deletedFiles, failedFiles, err)
+
+		h.respondWithError(w, err)
+		return
+	}
+
+	if totalFilesDeleted == totalFilesToDelete {
+		h.actionPkg.Debugf("Successfully cleaned up cache dir for input field: %s", inputFieldLabel)
+	}
+
+	// Move the staged file(s) into the input field's cache directory
+	var uploadedBytes int64
+	manifest := fields.FileManifest{Field: inputFieldLabel, Files: []fields.FileManifestEntry{}, Quarantined: quarantinedFiles}
+	for _, stagedFile := range stagedFiles {
+		err = os.Rename(stagedFile.path, filepath.Join(inputCacheDir, stagedFile.fileName))
+		if err != nil {
+			h.actionPkg.Errorf("Unable to move file to input field cache dir: %s", inputCacheDir)
+			failedFileUploads = append(failedFileUploads, stagedFile.originalFileName)
 			continue
 		}
 
 		// add file to successful uploads
-		successFileUploads = append(successFileUploads, handler.Filename)
+		successFileUploads = append(successFileUploads, stagedFile.originalFileName)
+		uploadedBytes += stagedFile.size
+		manifestEntry := stagedFile.manifestEntry()
+
+		if stagedFile.extraction != nil {
+			err = os.Rename(stagedFile.path+extractedDirSuffix, filepath.Join(inputCacheDir, stagedFile.extractedDirName))
+			if err != nil {
+				h.actionPkg.Errorf("Unable to move files extracted from %s to input field cache dir: %v", stagedFile.originalFileName, err)
+			} else {
+				manifestEntry.RecordExtraction(stagedFile.extractedDirName, stagedFile.extraction)
+			}
+		}
+
+		manifest.Files = append(manifest.Files, manifestEntry)
+	}
 
+	// Describe the uploaded file(s) for later steps
+	manifestPath, err := manifest.WriteToDir(inputCacheDir)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to write file manifest to input field cache dir: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
 	}
+	h.actionPkg.Debugf("File manifest written to %s", manifestPath)
 
-	h.actionPkg.Infof("Successfully uploaded %d of %d files!\n\n", len(successFileUploads), totalFiles)
+	h.actionPkg.Infof("Successfully uploaded %d of %d files!\n\n", len(successFileUploads), fileCount)
+	h.metrics.ObserveUpload(len(successFileUploads), uploadedBytes)
+
+	var quarantinedFileNames []string
+	for _, quarantinedFile := range quarantinedFiles {
+		quarantinedFileNames = append(quarantinedFileNames, quarantinedFile.OriginalName)
+	}
+
+	h.recordAuditEvent(ctx, audit.EventFilesUploaded, map[string]any{
+		"field":       inputFieldLabel,
+		"files":       successFileUploads,
+		"failed":      failedFileUploads,
+		"quarantined": quarantinedFileNames,
+	})
 
 	response := UploadToPortalResponse{
-		UploadedFiles: successFileUploads,
-		FailedFiles:   failedFileUploads,
+		UploadedFiles:    successFileUploads,
+		FailedFiles:      failedFileUploads,
+		Files:            manifest.Files,
+		QuarantinedFiles: manifest.Quarantined,
 	}
 
+	// quarantined file(s) weren't stored, so count as unsuccessful uploads
+	unsuccessfulFileUploads := len(failedFileUploads) + len(quarantinedFiles)
+
 	// TODO: better handle these failure/ partial failure situation
 	// might make sense to return a 4XX and allow the frontend to
 	// retrospectively remove the reference of the failed files from
 	// the input fiels's value/state (user will have to be notified
 	// that this is the case)
-	if len(failedFileUploads) > 0 && len(successFileUploads) > 0 {
+	if unsuccessfulFileUploads > 0 && len(successFileUploads) > 0 {
 		response.Status = "partial success"
 	}
-	if len(successFileUploads) == 0 && len(failedFileUploads) > 0 {
+	if len(successFileUploads) == 0 && unsuccessfulFileUploads > 0 {
 		response.Status = "failed"
 	}
 
-	if len(failedFileUploads) == 0 && len(successFileUploads) == totalFiles {
+	if unsuccessfulFileUploads == 0 && len(successFileUploads) == fileCount {
 		response.Status = "success"
 	}
 
@@ -370,16 +893,14 @@ func (h *Handler) ResetUpload(w http.ResponseWriter, r *http.Request) {
 	if inputFieldLabel = mux.Vars(r)[InputFieldLabelUriVariableId]; inputFieldLabel == "" {
 		h.actionPkg.Errorf("Input field label not found in request")
 
-		//nolint will set up default fallback later
-		getBaseResponseHandler().NewHTTPErrorResponse(w, errors.New(ErrKeyInvalidInputFieldId))
+		h.respondWithError(w, errors.New(ErrKeyInvalidInputFieldId))
 		return
 	}
 
 	status, totalFilesToDelete, totalFilesDeleted, deletedFiles, failedFiles, err := h.cleanUpCacheDir(inputFieldLabel, false)
 	if err != nil && err.Error() == ErrKeyUnableToRemoveCacheDirContents {
 
-		//nolint will set up default fallback later
-		getBaseResponseHandler().NewHTTPErrorResponse(w, errors.New(ErrKeyUnableToRemoveCacheDirContents),
+		h.respondWithError(w, errors.New(ErrKeyUnableToRemoveCacheDirContents),
 			reply.WithMeta(map[string]interface{}{"data": ResetUploadResponse{
 				Status:             status,
 				DeletedFiles:       deletedFiles,
@@ -391,12 +912,13 @@ func (h *Handler) ResetUpload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		//nolint will set up default fallback later
-		getBaseResponseHandler().NewHTTPErrorResponse(w, err)
+		h.respondWithError(w, err)
 		return
 	}
 
 	h.actionPkg.Infof("Cache directory contents reseted for input field label: %s\n\n", inputFieldLabel)
+	h.recordAuditEvent(r.Context(), audit.EventUploadReset, map[string]any{"field": inputFieldLabel, "files": deletedFiles})
+
 	//nolint will set up default fallback later
 	getBaseResponseHandler().NewHTTPDataResponse(w, http.StatusOK, &ResetUploadResponse{
 		Status:             status,
@@ -498,11 +1020,116 @@ func (h *Handler) cleanUpCacheDir(inputFieldLabel string, enableDebugOutput bool
 
 }
 
+// normaliseSubmittedFormValues validates and normalises the submitted group and json/yaml values,
+// returning the reason each rejected field was rejected
+func (h *Handler) normaliseSubmittedFormValues(form url.Values) map[string]string {
+	var fieldValidationErrors map[string]string = make(map[string]string)
+
+	if h.fields == nil {
+		return fieldValidationErrors
+	}
+
+	for i := range h.fields.Fields {
+		field := &h.fields.Fields[i]
+
+		var normalisedValue string
+		var err error
+
+		switch {
+		case field.IsGroup():
+			normalisedValue, err = field.NormaliseGroupValue(form.Get(field.Label))
+		case field.IsStructured():
+			normalisedValue, err = field.NormaliseStructuredValue(form.Get(field.Label))
+		default:
+			continue
+		}
+		if err != nil {
+			h.actionPkg.Errorf("Invalid value submitted for field '%s': %v", field.Label, err)
+			fieldValidationErrors[field.Label] = fieldValidationErrorMessage(err)
+			continue
+		}
+
+		form.Set(field.Label, normalisedValue)
+	}
+
+	return fieldValidationErrors
+}
+
+// respondWithFieldValidationErrors tells the portal which fields were rejected without replacing the form
+func (h *Handler) respondWithFieldValidationErrors(w http.ResponseWriter, fieldValidationErrors map[string]string) {
+
+	triggerBytes, err := json.Marshal(map[string]interface{}{
+		FieldValidationFailedEventName: map[string]interface{}{
+			"errors": fieldValidationErrors,
+		},
+	})
+	if err != nil {
+		h.actionPkg.Errorf("Unable to shape field validation errors: %v", zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", string(triggerBytes))
+	w.Header().Set("HX-Reswap", "none")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+}
+
+// fieldValidationErrorMessage returns the message to show the user for the given validation error
+func fieldValidationErrorMessage(err error) string {
+	var fieldValidationError *fields.FieldValidationError
+	if errors.As(err, &fieldValidationError) {
+		return fieldValidationError.Message
+	}
+	return err.Error()
+}
+
+// getField returns the field with the given label, or nil if the portal has no such field
+func (h *Handler) getField(label string) *fields.Field {
+	if h.fields == nil {
+		return nil
+	}
+
+	for i := range h.fields.Fields {
+		if h.fields.Fields[i].Label == label {
+			return &h.fields.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+// respondWithUploadLimitExceeded returns the error for the exceeded upload limit
+func (h *Handler) respondWithUploadLimitExceeded(w http.ResponseWriter, errKey string, limit string) {
+
+	h.respondWithError(w, errors.New(errKey),
+		reply.WithMeta(map[string]interface{}{"limit": limit}))
+}
+
 // getInputFieldCacheDir returns the cache directory path for the given input field name.
 func (h *Handler) getInputFieldCacheDir(inputFieldName string) string {
 	return h.inputFieldLabelToCacheDirMapping[inputFieldName]
 }
 
+// respondWithError returns the response of the error from the portal's error map, counting
+// it towards the portal's error metrics
+func (h *Handler) respondWithError(w http.ResponseWriter, err error, attributes ...reply.ResponseAttributes) error {
+	h.observeError(err.Error())
+	return getBaseResponseHandler().NewHTTPErrorResponse(w, err, attributes...)
+}
+
+// unmappedErrorMetricKey is what errors that aren't in the portal's error map are counted as,
+// as they are responded to as internal server errors
+const unmappedErrorMetricKey = "InternalServerError"
+
+// observeError counts an error response towards the portal's error metrics
+func (h *Handler) observeError(errKey string) {
+	if _, ok := portalErrorMap[errKey]; !ok {
+		errKey = unmappedErrorMetricKey
+	}
+
+	h.metrics.ObserveError(errKey)
+}
+
 // getBaseResponseHandler returns response handler configured with respective error map
 func getBaseResponseHandler() *reply.Replier {
 	return reply.NewReplier(append([]reply.ErrorManifest{}, portalErrorMap))
@@ -0,0 +1,304 @@
+package portal_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+)
+
+// testUploadFile is a file part of an upload request
+type testUploadFile struct {
+	formName string
+	fileName string
+	content  string
+}
+
+// fakeUploadScanner flags the files whose content holds "EICAR" and fails to scan those holding "UNSCANNABLE"
+type fakeUploadScanner struct{}
+
+func (fakeUploadScanner) Scan(ctx context.Context, fileName, filePath string) (*scanner.Result, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(string(content), "UNSCANNABLE") {
+		return nil, errors.New("scanner unavailable")
+	}
+	return &scanner.Result{Flagged: strings.Contains(string(content), "EICAR"), Detail: "Eicar-Signature"}, nil
+}
+
+func (fakeUploadScanner) Verify() error { return nil }
+
+func (fakeUploadScanner) Name() string { return "fake" }
+
+// newUploadRequest returns a multipart upload request for the field with the given label
+func newUploadRequest(t *testing.T, label string, files ...testUploadFile) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.formName, file.fileName)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/upload?label="+label, &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+// newUploadHandler returns a handler for the field, whose cache directory already holds a previous upload
+func newUploadHandler(t *testing.T, field fields.Field, uploadScanner scanner.Scanner) (*portal.Handler, string) {
+	t.Helper()
+
+	cacheDir := filepath.Join(t.TempDir(), field.Label)
+	require.NoError(t, os.Mkdir(cacheDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "previous.txt"), []byte("previous"), 0600))
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		InputFieldLabelToCacheDirMapping: map[string]string{field.Label: cacheDir},
+		Fields:                           &fields.Fields{Fields: []fields.Field{field}},
+		UploadScanner:                    uploadScanner,
+	})
+
+	return handler, cacheDir
+}
+
+// cacheDirFileNames returns the names of the files in the cache directory, and whether any staged
+// uploads were left next to it
+func cacheDirFileNames(t *testing.T, cacheDir string) ([]string, bool) {
+	t.Helper()
+
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+
+	var fileNames []string
+	for _, entry := range entries {
+		fileNames = append(fileNames, entry.Name())
+	}
+
+	staged, err := filepath.Glob(filepath.Join(filepath.Dir(cacheDir), ".staging-*"))
+	require.NoError(t, err)
+
+	return fileNames, len(staged) > 0
+}
+
+func TestHandler_UploadToPortal(t *testing.T) {
+	eicar := "X5O!P%@AP EICAR"
+
+	tests := []struct {
+		name              string
+		properties        fields.FieldProperties
+		scanner           scanner.Scanner
+		files             []testUploadFile
+		expectedStatus    int
+		expectedBody      string
+		expectedFileNames []string
+	}{
+		{
+			name:              "files replace the previous upload",
+			properties:        fields.FieldProperties{Type: "multifile"},
+			files:             []testUploadFile{{"artifact__index__0", "a.txt", "a"}, {"artifact__index__1", "b.txt", "b"}},
+			expectedStatus:    http.StatusOK,
+			expectedBody:      `"status":"success"`,
+			expectedFileNames: []string{fields.FileManifestName, "a.txt", "b.txt"},
+		},
+		{
+			name:              "too many files are rejected",
+			properties:        fields.FieldProperties{Type: "multifile", MaxFiles: 1},
+			files:             []testUploadFile{{"artifact__index__0", "a.txt", "a"}, {"artifact__index__1", "b.txt", "b"}},
+			expectedStatus:    http.StatusRequestEntityTooLarge,
+			expectedBody:      "More files were uploaded than the input field accepts",
+			expectedFileNames: []string{"previous.txt"},
+		},
+		{
+			name:              "a file larger than the max file size is rejected",
+			properties:        fields.FieldProperties{Type: "multifile", MaxFileSize: "1KB"},
+			files:             []testUploadFile{{"artifact__index__0", "a.txt", "a"}, {"artifact__index__1", "b.txt", strings.Repeat("b", 2048)}},
+			expectedStatus:    http.StatusRequestEntityTooLarge,
+			expectedBody:      "An uploaded file exceeds the maximum file size of the input field",
+			expectedFileNames: []string{"previous.txt"},
+		},
+		{
+			name:              "files larger than the max total size together are rejected",
+			properties:        fields.FieldProperties{Type: "multifile", MaxTotalSize: "1KB"},
+			files:             []testUploadFile{{"artifact__index__0", "a.txt", strings.Repeat("a", 800)}, {"artifact__index__1", "b.txt", strings.Repeat("b", 800)}},
+			expectedStatus:    http.StatusRequestEntityTooLarge,
+			expectedBody:      "The uploaded file(s) exceed the maximum total size of the input field",
+			expectedFileNames: []string{"previous.txt"},
+		},
+		{
+			name:              "files provided for another field are failed",
+			properties:        fields.FieldProperties{Type: "file"},
+			files:             []testUploadFile{{"other__index__0", "a.txt", "a"}},
+			expectedStatus:    http.StatusOK,
+			expectedBody:      `"status":"failed"`,
+			expectedFileNames: []string{fields.FileManifestName},
+		},
+		{
+			name:              "files that aren't an accepted type are rejected",
+			properties:        fields.FieldProperties{Type: "file", AcceptedFileTypes: []string{"image/*"}},
+			files:             []testUploadFile{{"artifact__index__0", "a.txt", "a"}},
+			expectedStatus:    http.StatusUnsupportedMediaType,
+			expectedBody:      "An uploaded file is not one of the accepted file types of the input field",
+			expectedFileNames: []string{"previous.txt"},
+		},
+		{
+			name:              "the previous upload is kept when a file can't be scanned",
+			properties:        fields.FieldProperties{Type: "file"},
+			scanner:           fakeUploadScanner{},
+			files:             []testUploadFile{{"artifact__index__0", "a.txt", "UNSCANNABLE"}},
+			expectedStatus:    http.StatusBadGateway,
+			expectedBody:      "An uploaded file could not be scanned, so the upload was rejected",
+			expectedFileNames: []string{"previous.txt"},
+		},
+		{
+			name:              "the previous upload is kept when a file is flagged",
+			properties:        fields.FieldProperties{Type: "file"},
+			scanner:           fakeUploadScanner{},
+			files:             []testUploadFile{{"artifact__index__0", "a.txt", eicar}},
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedBody:      "An uploaded file was flagged by the upload scanner, so the upload was rejected",
+			expectedFileNames: []string{"previous.txt"},
+		},
+		{
+			name:              "the previous upload is kept when an archive can't be extracted",
+			properties:        fields.FieldProperties{Type: "file", ExtractArchives: true},
+			files:             []testUploadFile{{"artifact__index__0", "a.zip", "not a zip"}},
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedBody:      "An uploaded archive could not be safely extracted within the input field's limits",
+			expectedFileNames: []string{"previous.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, cacheDir := newUploadHandler(t, fields.Field{Label: "artifact", Properties: tt.properties}, tt.scanner)
+
+			recorder := httptest.NewRecorder()
+			handler.UploadToPortal(recorder, newUploadRequest(t, "artifact", tt.files...))
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+
+			fileNames, staged := cacheDirFileNames(t, cacheDir)
+			assert.ElementsMatch(t, tt.expectedFileNames, fileNames)
+			assert.False(t, staged, "the staged files are removed")
+		})
+	}
+}
+
+func TestHandler_UploadToPortalLimitsTheRequestBody(t *testing.T) {
+	tests := []struct {
+		name          string
+		contentLength func(request *http.Request) int64
+	}{
+		{
+			name:          "declared request size",
+			contentLength: func(request *http.Request) int64 { return request.ContentLength },
+		},
+		{
+			name:          "streamed request size",
+			contentLength: func(request *http.Request) int64 { return -1 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, cacheDir := newUploadHandler(t, fields.Field{Label: "artifact", Properties: fields.FieldProperties{Type: "file", MaxTotalSize: "1KB"}}, nil)
+
+			// the request is larger than the field's limit and the allowance made for the multipart encoding
+			request := newUploadRequest(t, "artifact", testUploadFile{"artifact__index__0", "a.txt", strings.Repeat("a", 2<<20)})
+			request.ContentLength = tt.contentLength(request)
+
+			recorder := httptest.NewRecorder()
+			handler.UploadToPortal(recorder, request)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), "The uploaded file(s) exceed the maximum total size of the input field")
+
+			fileNames, staged := cacheDirFileNames(t, cacheDir)
+			assert.Equal(t, []string{"previous.txt"}, fileNames)
+			assert.False(t, staged, "the staged files are removed")
+		})
+	}
+}
+
+func TestHandler_UploadToPortalRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        func(t *testing.T) *http.Request
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "no label",
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/api/v1/upload", nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Target input field id (label) missing or malformatted",
+		},
+		{
+			name: "not a file field",
+			request: func(t *testing.T) *http.Request {
+				return newUploadRequest(t, "name", testUploadFile{"name__index__0", "a.txt", "a"})
+			},
+			expectedBody: "Target input field is not a file or multifile field",
+		},
+		{
+			name: "not multipart",
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/api/v1/upload?label=artifact", strings.NewReader("a"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "No files detected. Verify file(s) submitted with upload request",
+		},
+		{
+			name: "no files",
+			request: func(t *testing.T) *http.Request {
+				return newUploadRequest(t, "artifact")
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "No files detected. Verify file(s) submitted with upload request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			handler := newTestHandler(&portal.NewHandlerRequest{
+				InputFieldLabelToCacheDirMapping: map[string]string{"artifact": cacheDir},
+				Fields: &fields.Fields{Fields: []fields.Field{
+					{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+					{Label: "artifact", Properties: fields.FieldProperties{Type: "file"}},
+				}},
+			})
+
+			recorder := httptest.NewRecorder()
+			handler.UploadToPortal(recorder, tt.request(t))
+
+			if tt.expectedStatus != 0 {
+				assert.Equal(t, tt.expectedStatus, recorder.Code)
+			}
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+		})
+	}
+}
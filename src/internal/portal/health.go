@@ -0,0 +1,51 @@
+package portal
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// SetReady sets whether the portal is ready, which it should be once the notifiers have been
+// verified and the portal is being served
+func (h *Handler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// readyListener marks the portal as ready the first time the server accepts connections on it
+type readyListener struct {
+	net.Listener
+	handler *Handler
+	once    sync.Once
+}
+
+// Accept marks the portal as ready before waiting for the next connection
+func (l *readyListener) Accept() (net.Conn, error) {
+	l.once.Do(func() {
+		l.handler.SetReady(true)
+	})
+
+	return l.Listener.Accept()
+}
+
+// ReadyListener wraps the listener the portal is served on to mark the portal as ready once
+// the server accepts connections on it
+func (h *Handler) ReadyListener(listener net.Listener) net.Listener {
+	return &readyListener{Listener: listener, handler: h}
+}
+
+// Healthz returns response for request to check the portal is up
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	getBaseResponseHandler().NewHTTPDataResponse(w, http.StatusOK, &HealthResponse{Status: "ok"})
+}
+
+// Readyz returns response for request to check the portal is ready to be used, which
+// is only the case once the notifiers have been verified and the portal is being served
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if !h.ready.Load() {
+		getBaseResponseHandler().NewHTTPDataResponse(w, http.StatusServiceUnavailable, &HealthResponse{Status: "not ready"})
+		return
+	}
+
+	getBaseResponseHandler().NewHTTPDataResponse(w, http.StatusOK, &HealthResponse{Status: "ready"})
+}
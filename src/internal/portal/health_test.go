@@ -0,0 +1,50 @@
+package portal_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/boasihq/interactive-inputs/internal/portal"
+)
+
+func TestHandler_Healthz(t *testing.T) {
+	handler := newTestHandler(&portal.NewHandlerRequest{})
+
+	recorder := httptest.NewRecorder()
+	handler.Healthz(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestHandler_ReadyListener(t *testing.T) {
+	handler := newTestHandler(&portal.NewHandlerRequest{})
+
+	recorder := httptest.NewRecorder()
+	handler.Readyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	// wrapping the listener alone doesn't make the portal ready, only serving on it does
+	readyListener := handler.ReadyListener(listener)
+
+	recorder = httptest.NewRecorder()
+	handler.Readyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	server := &http.Server{Handler: http.HandlerFunc(handler.Readyz)}
+	go server.Serve(readyListener)
+	defer server.Close()
+
+	response, err := http.Get("http://" + listener.Addr().String() + "/readyz")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
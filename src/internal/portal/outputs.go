@@ -0,0 +1,133 @@
+package portal
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+)
+
+// OutputSubmittedValuesRequest holds everything needed to output the values submitted for the fields
+type OutputSubmittedValuesRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg actionPkg
+
+	// IsRunningLocal is true when running locally
+	IsRunningLocal bool
+
+	// InputFieldLabelToCacheDirMapping mapping of input field label to its cache directory
+	InputFieldLabelToCacheDirMapping map[string]string
+
+	// Fields the fields the values were submitted for
+	Fields *fields.Fields
+
+	// Values the submitted values keyed by field label
+	Values url.Values
+}
+
+// OutputSubmittedValues masks the values of secret fields and sets the submitted values as the
+// action's outputs, along with the manifest, checksums and extracted files of file fields
+func OutputSubmittedValues(r *OutputSubmittedValuesRequest) {
+
+	if r.IsRunningLocal {
+		r.ActionPkg.Infof("Running locally, will only print the form data to stdout")
+	}
+
+	// mask every secret value before anything is output
+	secretLabels := make(map[string]bool)
+	for key, value := range r.Values {
+		field := getSubmittedField(r.Fields, key)
+		if field == nil {
+			continue
+		}
+
+		secretValues := field.SecretValues(value)
+		for _, secretValue := range secretValues {
+			r.ActionPkg.AddMask(secretValue)
+		}
+		secretLabels[key] = len(secretValues) > 0
+	}
+
+	for key, value := range r.Values {
+
+		// handle file/multifile inputs
+		if cacheDir := r.InputFieldLabelToCacheDirMapping[key]; cacheDir != "" {
+
+			r.ActionPkg.Infof("%s: %s", key, cacheDir)
+
+			if !r.IsRunningLocal {
+				// Can't use when running locally
+				r.ActionPkg.SetOutput(key, cacheDir)
+			}
+
+			outputFileManifest(r, key, cacheDir)
+
+			continue
+		}
+
+		if secretLabels[key] {
+			r.ActionPkg.Infof("%s: %s", key, audit.RedactedValue)
+		} else {
+			r.ActionPkg.Infof("%s: %s", key, value)
+		}
+
+		if !r.IsRunningLocal {
+			// Can't use when running locally
+			r.ActionPkg.SetOutput(key, strings.Join(value, ","))
+		}
+	}
+}
+
+// getSubmittedField returns the field with the given label, or nil when there isn't one
+func getSubmittedField(submittedFields *fields.Fields, label string) *fields.Field {
+	if submittedFields == nil {
+		return nil
+	}
+
+	for i := range submittedFields.Fields {
+		if submittedFields.Fields[i].Label == label {
+			return &submittedFields.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+// outputFileManifest sets the path of the file manifest and the checksums of the files
+// it describes as outputs of the file/multifile field
+func outputFileManifest(r *OutputSubmittedValuesRequest, key, cacheDir string) {
+
+	manifest, err := fields.LoadFileManifestFromDir(cacheDir)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		r.ActionPkg.Warningf("Unable to load the file manifest of %s: %v", key, err)
+		return
+	}
+
+	for _, file := range manifest.Files {
+		r.ActionPkg.Infof("  • %s (sha256: %s)", file.StoredName, file.SHA256)
+		if file.ExtractedTo != "" {
+			r.ActionPkg.Infof("    extracted %d file(s) to %s", len(file.ExtractedFiles), file.ExtractedTo)
+		}
+	}
+
+	for _, file := range manifest.Quarantined {
+		r.ActionPkg.Warningf("%s was flagged by the %s scan and quarantined to %s", file.OriginalName, file.Scan.Scanner, file.QuarantinedTo)
+	}
+
+	if !r.IsRunningLocal {
+		// Can't use when running locally
+		r.ActionPkg.SetOutput(key+ManifestOutputSuffix, filepath.Join(cacheDir, fields.FileManifestName))
+		r.ActionPkg.SetOutput(key+ChecksumsOutputSuffix, manifest.Checksums())
+
+		if extractedFiles := manifest.ExtractedFiles(); len(extractedFiles) > 0 {
+			r.ActionPkg.SetOutput(key+ExtractedFilesOutputSuffix, strings.Join(extractedFiles, "\n"))
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package portal_test
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputSubmittedValues(t *testing.T) {
+
+	var output bytes.Buffer
+
+	outputFilePath := filepath.Join(t.TempDir(), "github_output")
+	t.Setenv("GITHUB_OUTPUT", outputFilePath)
+
+	portal.OutputSubmittedValues(&portal.OutputSubmittedValuesRequest{
+		ActionPkg: githubactions.New(githubactions.WithWriter(&output)),
+		Fields: &fields.Fields{Fields: []fields.Field{
+			{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+			{Label: "api-key", Properties: fields.FieldProperties{Type: "text", Secret: true}},
+			{Label: "deploy-keys", Properties: fields.FieldProperties{Type: "group", SubFields: []fields.Field{
+				{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+				{Label: "key", Properties: fields.FieldProperties{Type: "text", Secret: true}},
+			}}},
+		}},
+		Values: url.Values{
+			"name":        {"Leon"},
+			"api-key":     {"s3cr3t-api-key"},
+			"deploy-keys": {`[{"name":"api","key":"ssh-ed25519 AAAA"}]`},
+		},
+	})
+
+	lines := strings.Split(output.String(), "\n")
+
+	// every secret is masked before the first line that isn't masking one
+	var masked []string
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "::add-mask::") {
+			break
+		}
+		masked = append(masked, strings.TrimPrefix(line, "::add-mask::"))
+	}
+	assert.ElementsMatch(t, []string{"s3cr3t-api-key", "ssh-ed25519 AAAA"}, masked)
+
+	assert.Contains(t, lines, "name: [Leon]")
+	assert.Contains(t, lines, "api-key: [REDACTED]")
+	assert.Contains(t, lines, "deploy-keys: [REDACTED]")
+	assert.NotContains(t, strings.Join(lines[len(masked):], "\n"), "s3cr3t-api-key")
+	assert.NotContains(t, strings.Join(lines[len(masked):], "\n"), "ssh-ed25519 AAAA")
+
+	// the secrets are still set as outputs, so later steps can use them
+	outputs, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(outputs), "s3cr3t-api-key")
+	assert.Contains(t, string(outputs), "ssh-ed25519 AAAA")
+}
@@ -0,0 +1,207 @@
+package portal
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+)
+
+// clientLimiterPruneSize is how many clients a limiter tracks before those whose window
+// has ended are removed
+const clientLimiterPruneSize = 1024
+
+// clientLimiter counts events (i.e. requests or failed access attempts) for each client
+// within a fixed window
+type clientLimiter struct {
+
+	// limit is how many events each client can have within the window
+	limit int
+
+	// window is how long events are counted for, starting from the client's first event
+	window time.Duration
+
+	// clients holds the window of each client, keyed by their address
+	clients map[string]*clientWindow
+
+	// clientsMutex guards clients
+	clientsMutex sync.Mutex
+}
+
+// clientWindow holds the events counted for a client
+type clientWindow struct {
+
+	// start is when the window started
+	start time.Time
+
+	// count is how many events have been counted within the window
+	count int
+}
+
+// newClientLimiter returns a limiter allowing each client the limit of events within the
+// window, or nil when the limit is 0
+func newClientLimiter(limit int, window time.Duration) *clientLimiter {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+
+	return &clientLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*clientWindow),
+	}
+}
+
+// add counts an event for the client, returning how many events it has had within its window
+func (l *clientLimiter) add(client string) int {
+	l.clientsMutex.Lock()
+	defer l.clientsMutex.Unlock()
+
+	now := time.Now()
+
+	if len(l.clients) >= clientLimiterPruneSize {
+		for address, clientWindow := range l.clients {
+			if now.Sub(clientWindow.start) >= l.window {
+				delete(l.clients, address)
+			}
+		}
+	}
+
+	window, ok := l.clients[client]
+	if !ok || now.Sub(window.start) >= l.window {
+		window = &clientWindow{start: now}
+		l.clients[client] = window
+	}
+
+	window.count++
+
+	return window.count
+}
+
+// restart restarts the client's window from now, keeping its count
+func (l *clientLimiter) restart(client string) {
+	l.clientsMutex.Lock()
+	defer l.clientsMutex.Unlock()
+
+	if window, ok := l.clients[client]; ok {
+		window.start = time.Now()
+	}
+}
+
+// retryAfter returns how long the client has to wait before its window ends, or 0 when
+// the client hasn't reached the limit within its window
+func (l *clientLimiter) retryAfter(client string) time.Duration {
+	l.clientsMutex.Lock()
+	defer l.clientsMutex.Unlock()
+
+	window, ok := l.clients[client]
+	if !ok || window.count < l.limit {
+		return 0
+	}
+
+	remaining := l.window - time.Since(window.start)
+	if remaining <= 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+// RateLimit only lets each client make the configured number of requests within the
+// rate limit's window, alerting the first time a client goes over it
+func (h *Handler) RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if h.rateLimiter == nil || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		client := h.clientAddress(r)
+
+		requests := h.rateLimiter.add(client)
+		if requests > h.rateLimiter.limit {
+			if requests == h.rateLimiter.limit+1 {
+				h.recordAuditEvent(r.Context(), audit.EventClientRateLimited, map[string]any{"path": r.URL.Path})
+				h.alertLimitTriggered(fmt.Sprintf("The portal's rate limit of %d requests per %s was exceeded by %s", h.rateLimiter.limit, h.rateLimiter.window, client))
+			}
+
+			h.respondWithRetryAfter(w, r, ErrKeyPortalRateLimited, h.rateLimiter.retryAfter(client))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLockedOut returns true, responding with the lockout error, when the client has made too many
+// failed access attempts
+func (h *Handler) isLockedOut(w http.ResponseWriter, r *http.Request, client string) bool {
+	if h.authLockout == nil {
+		return false
+	}
+
+	retryAfter := h.authLockout.retryAfter(client)
+	if retryAfter == 0 {
+		return false
+	}
+
+	h.respondWithRetryAfter(w, r, ErrKeyPortalLockedOut, retryAfter)
+	return true
+}
+
+// recordFailedAccessAttempt records a failed access attempt for the client making the request,
+// locking it out for the lockout's window once it reaches the allowed number of attempts
+func (h *Handler) recordFailedAccessAttempt(r *http.Request, reason string) {
+	h.recordAuditEvent(r.Context(), audit.EventAccessDenied, map[string]any{"reason": reason})
+
+	if h.authLockout == nil {
+		return
+	}
+
+	client := h.clientAddress(r)
+	if attempts := h.authLockout.add(client); attempts == h.authLockout.limit {
+		h.authLockout.restart(client)
+		h.recordAuditEvent(r.Context(), audit.EventClientLockedOut, map[string]any{"attempts": attempts})
+		h.alertLimitTriggered(fmt.Sprintf("%s has been locked out of the portal for %s after %d failed access attempts", client, h.authLockout.window, attempts))
+	}
+}
+
+// alertLimitTriggered logs that a limit was triggered and passes it on in the background
+func (h *Handler) alertLimitTriggered(message string) {
+	h.actionPkg.Warningf(message)
+
+	if h.onLimitTriggered != nil {
+		go h.onLimitTriggered(message)
+	}
+}
+
+// clientAddress returns the address of the client making the request
+func (h *Handler) clientAddress(r *http.Request) string {
+	if h.trustForwardedFor {
+		if forwardedFor := r.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+			addresses := strings.Split(forwardedFor[len(forwardedFor)-1], ",")
+			if address := strings.TrimSpace(addresses[len(addresses)-1]); address != "" {
+				return address
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// respondWithRetryAfter returns the error of the given key, letting the client know how long to wait before retrying
+func (h *Handler) respondWithRetryAfter(w http.ResponseWriter, r *http.Request, errKey string, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second).Seconds())))
+	h.respondWithPortalError(w, r, errKey)
+}
@@ -0,0 +1,218 @@
+package portal_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/stretchr/testify/assert"
+)
+
+// newClientRequest returns a request made from the client's address, with the X-Forwarded-For
+// header set when forwardedFor isn't empty
+func newClientRequest(method, target, remoteAddr, forwardedFor string) *http.Request {
+	request := httptest.NewRequest(method, target, nil)
+	request.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	return request
+}
+
+func TestHandler_RateLimit(t *testing.T) {
+
+	limitTriggered := make(chan string, 10)
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		RateLimitRequests: 2,
+		RateLimitWindow:   time.Minute,
+		OnLimitTriggered: func(message string) {
+			limitTriggered <- message
+		},
+	})
+	rateLimited := handler.RateLimit(okHandler)
+
+	serve := func(request *http.Request) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		rateLimited.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, serve(newClientRequest(http.MethodPost, "/api/v1/portal/submit", "203.0.113.7:5000", "")).Code)
+	}
+
+	// preflight requests aren't counted
+	assert.Equal(t, http.StatusOK, serve(newClientRequest(http.MethodOptions, "/api/v1/portal/submit", "203.0.113.7:5000", "")).Code)
+
+	for i := 0; i < 2; i++ {
+		recorder := serve(newClientRequest(http.MethodPost, "/api/v1/portal/submit", "203.0.113.7:5001", ""))
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+		retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+		assert.NoError(t, err)
+		assert.InDelta(t, 60, retryAfter, 1)
+	}
+
+	// other clients have their own limit
+	assert.Equal(t, http.StatusOK, serve(newClientRequest(http.MethodPost, "/api/v1/portal/submit", "198.51.100.9:5000", "")).Code)
+
+	// only the first request over the limit is alerted on
+	select {
+	case message := <-limitTriggered:
+		assert.Contains(t, message, "exceeded by 203.0.113.7")
+	case <-time.After(time.Second):
+		t.Fatal("the rate limit wasn't alerted on")
+	}
+	select {
+	case message := <-limitTriggered:
+		t.Fatalf("the rate limit was alerted on more than once: %s", message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHandler_RateLimitWindow(t *testing.T) {
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		RateLimitRequests: 1,
+		RateLimitWindow:   50 * time.Millisecond,
+	})
+	rateLimited := handler.RateLimit(okHandler)
+
+	serve := func() int {
+		recorder := httptest.NewRecorder()
+		rateLimited.ServeHTTP(recorder, newClientRequest(http.MethodPost, "/api/v1/portal/submit", "203.0.113.7:5000", ""))
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, http.StatusTooManyRequests, serve())
+
+	time.Sleep(60 * time.Millisecond)
+
+	assert.Equal(t, http.StatusOK, serve())
+}
+
+func TestHandler_RateLimitForwardedFor(t *testing.T) {
+
+	tests := []struct {
+		name              string
+		trustForwardedFor bool
+		requests          []*http.Request
+		expectedStatuses  []int
+	}{
+		{
+			name: "forwarded addresses aren't trusted by default",
+			requests: []*http.Request{
+				newClientRequest(http.MethodPost, "/api/v1/portal/submit", "127.0.0.1:5000", "203.0.113.7"),
+				newClientRequest(http.MethodPost, "/api/v1/portal/submit", "127.0.0.1:5001", "198.51.100.9"),
+			},
+			expectedStatuses: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:              "last forwarded address is used when trusted",
+			trustForwardedFor: true,
+			requests: []*http.Request{
+				newClientRequest(http.MethodPost, "/api/v1/portal/submit", "127.0.0.1:5000", "203.0.113.7"),
+				newClientRequest(http.MethodPost, "/api/v1/portal/submit", "127.0.0.1:5001", "198.51.100.9"),
+				newClientRequest(http.MethodPost, "/api/v1/portal/submit", "127.0.0.1:5002", "10.0.0.1, 203.0.113.7"),
+			},
+			expectedStatuses: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:              "remote address is used when trusted but not forwarded",
+			trustForwardedFor: true,
+			requests: []*http.Request{
+				newClientRequest(http.MethodPost, "/api/v1/portal/submit", "127.0.0.1:5000", ""),
+				newClientRequest(http.MethodPost, "/api/v1/portal/submit", "127.0.0.1:5001", ""),
+			},
+			expectedStatuses: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			handler := newTestHandler(&portal.NewHandlerRequest{
+				RateLimitRequests: 1,
+				RateLimitWindow:   time.Minute,
+			})
+			handler.SetTrustForwardedFor(tt.trustForwardedFor)
+			rateLimited := handler.RateLimit(okHandler)
+
+			for i, request := range tt.requests {
+				recorder := httptest.NewRecorder()
+				rateLimited.ServeHTTP(recorder, request)
+				assert.Equal(t, tt.expectedStatuses[i], recorder.Code, "request %d", i)
+			}
+		})
+	}
+}
+
+func TestHandler_AuthLockout(t *testing.T) {
+
+	limitTriggered := make(chan string, 10)
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		AccessToken:         testAccessToken,
+		AuthLockoutAttempts: 2,
+		AuthLockoutWindow:   time.Minute,
+		OnLimitTriggered: func(message string) {
+			limitTriggered <- message
+		},
+	})
+	protected := handler.RequireAccessToken(okHandler)
+
+	serve := func(remoteAddr, token string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		protected.ServeHTTP(recorder, newClientRequest(http.MethodPost, "/api/v1/portal/submit?token="+token, remoteAddr, ""))
+		return recorder
+	}
+
+	// valid attempts aren't counted
+	assert.Equal(t, http.StatusOK, serve("203.0.113.7:5000", testAccessToken).Code)
+
+	assert.Equal(t, http.StatusUnauthorized, serve("203.0.113.7:5000", "guessed-token").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("203.0.113.7:5001", "guessed-token").Code)
+
+	// once locked out, even the valid token isn't accepted
+	recorder := serve("203.0.113.7:5002", testAccessToken)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+
+	// other clients aren't locked out
+	assert.Equal(t, http.StatusOK, serve("198.51.100.9:5000", testAccessToken).Code)
+
+	select {
+	case message := <-limitTriggered:
+		assert.Contains(t, message, "203.0.113.7 has been locked out of the portal")
+	case <-time.After(time.Second):
+		t.Fatal("the lockout wasn't alerted on")
+	}
+}
+
+func TestHandler_AuthLockoutWindow(t *testing.T) {
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		AccessToken:         testAccessToken,
+		AuthLockoutAttempts: 1,
+		AuthLockoutWindow:   50 * time.Millisecond,
+	})
+	protected := handler.RequireAccessToken(okHandler)
+
+	serve := func(token string) int {
+		recorder := httptest.NewRecorder()
+		protected.ServeHTTP(recorder, newClientRequest(http.MethodPost, "/api/v1/portal/submit?token="+token, "203.0.113.7:5000", ""))
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve("guessed-token"))
+	assert.Equal(t, http.StatusTooManyRequests, serve(testAccessToken))
+
+	time.Sleep(60 * time.Millisecond)
+
+	assert.Equal(t, http.StatusOK, serve(testAccessToken))
+}
@@ -0,0 +1,51 @@
+package portal
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+)
+
+// SubmitReply submits the values answered in a reply to the portal's comment the same way
+// as when they are submitted through the portal
+func (h *Handler) SubmitReply(values url.Values, submittedBy, commentUrl string) {
+	// the portal is closing, so its links can't be used to submit again
+	if !h.RevokeAccessLinks() {
+		h.actionPkg.Debugf("Ignoring the reply from %s as the portal is already closing", submittedBy)
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), submittedByContextKey{}, submittedBy)
+
+	h.outputSubmission(ctx, values, submittedBy, map[string]any{"comment": commentUrl})
+
+	h.actionPkg.Infof("Your inputs have successfully been received!")
+
+	h.removeUploadSessionsIdleSince(time.Now())
+	if h.onSubmitted != nil {
+		h.onSubmitted(submittedBy)
+	}
+}
+
+// CancelReply cancels the portal for a reply to the portal's comment rejecting it, the same
+// way as when it is cancelled through the portal
+func (h *Handler) CancelReply(cancelledBy, commentUrl string) {
+	// the portal is closing, so its links can't be used anymore
+	if !h.RevokeAccessLinks() {
+		h.actionPkg.Debugf("Ignoring the reply from %s as the portal is already closing", cancelledBy)
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), submittedByContextKey{}, cancelledBy)
+
+	h.recordAuditEvent(ctx, audit.EventPortalCancelled, map[string]any{"comment": commentUrl})
+
+	h.actionPkg.Infof("Cancel request received from %s", cancelledBy)
+
+	h.removeUploadSessionsIdleSince(time.Now())
+	if h.onCancelled != nil {
+		h.onCancelled(cancelledBy)
+	}
+}
@@ -0,0 +1,58 @@
+package portal_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/portal"
+)
+
+func TestHandler_RepliesClaimThePortalOnce(t *testing.T) {
+	var submissions, cancellations atomic.Int32
+
+	handler := newTestHandler(&portal.NewHandlerRequest{
+		IsRunningLocal: true,
+		AccessToken:    testAccessToken,
+		Fields: &fields.Fields{Fields: []fields.Field{
+			{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+		}},
+		OnSubmitted: func(submittedBy string) { submissions.Add(1) },
+		OnCancelled: func(cancelledBy string) { cancellations.Add(1) },
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			handler.SubmitReply(url.Values{"name": {"Leon"}}, "octocat", "https://github.com/o/r/pull/1#c1")
+		}()
+		go func() {
+			defer wg.Done()
+			handler.CancelReply("octocat", "https://github.com/o/r/pull/1#c2")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), submissions.Load()+cancellations.Load())
+
+	// the portal's links are revoked by whichever reply claimed it
+	assert.False(t, handler.RevokeAccessLinks())
+
+	// a submission through the portal that was let in before the reply claimed it isn't output
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, testPortalUrl+"/submit", strings.NewReader("name=Other"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.SubmitPortal(recorder, request)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, int32(1), submissions.Load()+cancellations.Load())
+}
@@ -0,0 +1,18 @@
+package portal
+
+// CreateUploadSessionRequest represents the request for starting an upload session
+type CreateUploadSessionRequest struct {
+
+	// Files represents the file(s) that will be uploaded in the session
+	Files []CreateUploadSessionFile `json:"files"`
+}
+
+// CreateUploadSessionFile represents a file that will be uploaded in an upload session
+type CreateUploadSessionFile struct {
+
+	// Name represents the name of the file
+	Name string `json:"name"`
+
+	// Size represents the size of the file in bytes
+	Size int64 `json:"size"`
+}
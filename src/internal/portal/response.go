@@ -1,5 +1,7 @@
 package portal
 
+import "github.com/boasihq/interactive-inputs/internal/fields"
+
 // UploadToPortalResponse represents the response for uploading files to the portal
 type UploadToPortalResponse struct {
 
@@ -11,6 +13,48 @@ type UploadToPortalResponse struct {
 
 	// FailedFiles represents the list of files that failed to upload
 	FailedFiles []string `json:"failed_files,omitempty"`
+
+	// Files represents the files uploaded successfully, as recorded in the input field's file manifest
+	Files []fields.FileManifestEntry `json:"files,omitempty"`
+
+	// QuarantinedFiles represents the files the upload scanner flagged, which were quarantined
+	QuarantinedFiles []fields.FileManifestEntry `json:"quarantined_files,omitempty"`
+}
+
+// UploadSessionResponse represents the response for starting, resuming or sending
+// a chunk to an upload session
+type UploadSessionResponse struct {
+
+	// ID represents the id of the upload session
+	ID string `json:"id"`
+
+	// Label represents the label of the input field the file(s) are uploaded for
+	Label string `json:"label"`
+
+	// ChunkSize represents the largest chunk (in bytes) that can be sent at a time
+	ChunkSize int64 `json:"chunk_size"`
+
+	// Files represents the file(s) of the upload session and how much of them has been received
+	Files []UploadSessionFile `json:"files"`
+}
+
+// UploadSessionFile represents a file of an upload session
+type UploadSessionFile struct {
+
+	// Index represents the index of the file in the upload session
+	Index int `json:"index"`
+
+	// OriginalName represents the name the file is uploaded with
+	OriginalName string `json:"original_name"`
+
+	// StoredName represents the (sanitised) name the file will be stored as
+	StoredName string `json:"stored_name"`
+
+	// Size represents the size of the file in bytes
+	Size int64 `json:"size"`
+
+	// Offset represents the number of bytes of the file received, which is where the next chunk is expected
+	Offset int64 `json:"offset"`
 }
 
 // ResetUploadResponse represents the response for resetting the upload
@@ -30,3 +74,9 @@ type ResetUploadResponse struct {
 	// TotalFilesDeleted represents the total number of files that were deleted
 	TotalFilesDeleted int `json:"total_files_deleted"`
 }
+
+// HealthResponse represents the response for checking the portal's health or readiness
+type HealthResponse struct {
+	// Status represents the status of the portal
+	Status string `json:"status"`
+}
@@ -15,6 +15,18 @@ type portalEventHandler interface {
 	CancelPortal(w http.ResponseWriter, r *http.Request)
 	UploadToPortal(w http.ResponseWriter, r *http.Request)
 	ResetUpload(w http.ResponseWriter, r *http.Request)
+	CreateUploadSession(w http.ResponseWriter, r *http.Request)
+	GetUploadSession(w http.ResponseWriter, r *http.Request)
+	UploadChunk(w http.ResponseWriter, r *http.Request)
+	CompleteUploadSession(w http.ResponseWriter, r *http.Request)
+	AbortUploadSession(w http.ResponseWriter, r *http.Request)
+	RequireAccessToken(next http.Handler) http.Handler
+	RequireCsrfToken(next http.Handler) http.Handler
+	RateLimit(next http.Handler) http.Handler
+	SecureHeaders(next http.Handler) http.Handler
+	IdentifyClient(next http.Handler) http.Handler
+	Healthz(w http.ResponseWriter, r *http.Request)
+	Readyz(w http.ResponseWriter, r *http.Request)
 }
 
 // uiHandler expected methods for valid ui handler
@@ -43,6 +55,9 @@ type AttachRoutesRequest struct {
 
 	// ActionPkg represents the githubactions package
 	ActionPkg actionPkg
+
+	// MetricsHandler serves the portal's metrics, which aren't exposed when nil
+	MetricsHandler http.Handler
 }
 
 // AttachRoutes attaches portal handlers to corresponding
@@ -56,15 +71,42 @@ func AttachRoutes(request *AttachRoutesRequest) {
 		os.Exit(1)
 	}
 
+	// Every response is sent with the portal's security headers, and every request is
+	// attributed to the client making it
+	request.Router.Use(request.PortalEventHandler.SecureHeaders, request.PortalEventHandler.IdentifyClient)
+
 	// Create path for handling static assets
 	request.Router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.FS(staticSubFS))))
 
-	request.Router.HandleFunc("/", request.UiHandler.Home).Methods("GET")
-	request.Router.HandleFunc("/submit", request.PortalEventHandler.SubmitPortal).Methods("POST")
-	request.Router.HandleFunc("/cancel", request.PortalEventHandler.CancelPortal).Methods("POST")
+	// The health checks and metrics can be used without the access token
+	request.Router.HandleFunc("/healthz", request.PortalEventHandler.Healthz).Methods("GET")
+	request.Router.HandleFunc("/readyz", request.PortalEventHandler.Readyz).Methods("GET")
+	if request.MetricsHandler != nil {
+		request.Router.Handle("/metrics", request.MetricsHandler).Methods("GET")
+	}
+
+	// Everything other than the static assets can only be used with the portal's access token,
+	// and requests that change the portal's state must come from the portal itself
+	portalRouter := request.Router.NewRoute().Subrouter()
+	portalRouter.Use(request.PortalEventHandler.RequireAccessToken, request.PortalEventHandler.RequireCsrfToken)
+
+	// The submit, upload and reset routes are rate limited for each client. The upload chunks and
+	// session status aren't, as a single upload can be made up of many of them
+	rateLimited := func(handlerFunc http.HandlerFunc) http.Handler {
+		return request.PortalEventHandler.RateLimit(handlerFunc)
+	}
 
-	apiRouter := request.Router.PathPrefix("/api/v1").Subrouter()
-	apiRouter.HandleFunc("/upload", request.PortalEventHandler.UploadToPortal).Methods("POST", "OPTIONS")
-	apiRouter.HandleFunc(fmt.Sprintf("/reset/{%s}", InputFieldLabelUriVariableId), request.PortalEventHandler.ResetUpload).Methods("DELETE", "OPTIONS")
+	portalRouter.HandleFunc("/", request.UiHandler.Home).Methods("GET")
+	portalRouter.Handle("/submit", rateLimited(request.PortalEventHandler.SubmitPortal)).Methods("POST")
+	portalRouter.HandleFunc("/cancel", request.PortalEventHandler.CancelPortal).Methods("POST")
+
+	apiRouter := portalRouter.PathPrefix("/api/v1").Subrouter()
+	apiRouter.Handle("/upload", rateLimited(request.PortalEventHandler.UploadToPortal)).Methods("POST", "OPTIONS")
+	apiRouter.Handle("/upload/sessions", rateLimited(request.PortalEventHandler.CreateUploadSession)).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc(fmt.Sprintf("/upload/sessions/{%s}", UploadSessionIdUriVariableId), request.PortalEventHandler.GetUploadSession).Methods("GET", "OPTIONS")
+	apiRouter.Handle(fmt.Sprintf("/upload/sessions/{%s}", UploadSessionIdUriVariableId), rateLimited(request.PortalEventHandler.AbortUploadSession)).Methods("DELETE", "OPTIONS")
+	apiRouter.HandleFunc(fmt.Sprintf("/upload/sessions/{%s}/files/{%s}", UploadSessionIdUriVariableId, UploadFileIndexUriVariableId), request.PortalEventHandler.UploadChunk).Methods("PATCH", "OPTIONS")
+	apiRouter.Handle(fmt.Sprintf("/upload/sessions/{%s}/complete", UploadSessionIdUriVariableId), rateLimited(request.PortalEventHandler.CompleteUploadSession)).Methods("POST", "OPTIONS")
+	apiRouter.Handle(fmt.Sprintf("/reset/{%s}", InputFieldLabelUriVariableId), rateLimited(request.PortalEventHandler.ResetUpload)).Methods("DELETE", "OPTIONS")
 
 }
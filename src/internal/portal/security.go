@@ -0,0 +1,140 @@
+package portal
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+)
+
+// cspNonceSize is the number of random bytes the nonce of the portal's inline scripts is made up of
+const cspNonceSize = 16
+
+// csrfTokenSize is the number of random bytes the portal's CSRF token is made up of
+const csrfTokenSize = 32
+
+// cspNonceContextKey is the key of the nonce of the request's inline scripts in the request's context
+type cspNonceContextKey struct{}
+
+// contentSecurityPolicyTmpl is the portal's Content-Security-Policy, Alpine and Tailwind
+// need 'unsafe-eval' and inline styles
+const contentSecurityPolicyTmpl = "default-src 'self'; " +
+	"script-src 'self' 'nonce-%s' 'unsafe-eval'; " +
+	"style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data: blob:; " +
+	"font-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"object-src 'none'; " +
+	"base-uri 'self'; " +
+	"form-action 'self'; " +
+	"frame-ancestors 'none'"
+
+// SecureHeaders adds the portal's security headers to the response, including a Content-Security-Policy
+// whose nonce is added to the request's context for the portal's inline scripts
+func (h *Handler) SecureHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		nonce, err := newRandomToken(cspNonceSize)
+		if err != nil {
+			h.actionPkg.Errorf("Unable to generate the Content-Security-Policy nonce: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Security-Policy", fmt.Sprintf(contentSecurityPolicyTmpl, nonce))
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "same-origin")
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), cspNonceContextKey{}, nonce)))
+	})
+}
+
+// CspNonce returns the nonce the request's inline scripts must carry, or an empty string
+// when the request didn't pass through SecureHeaders
+func CspNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceContextKey{}).(string)
+	return nonce
+}
+
+// RequireCsrfToken makes sure requests that change the portal's state come from the portal itself
+func (h *Handler) RequireCsrfToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		csrfCookie, err := r.Cookie(CsrfCookieName)
+		if err != nil || csrfCookie.Value == "" {
+			csrfToken, err := newRandomToken(csrfTokenSize)
+			if err != nil {
+				h.actionPkg.Errorf("Unable to generate the CSRF token: %v", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+
+			// the portal's scripts read the token from the cookie, so it can't be HttpOnly
+			csrfCookie = &http.Cookie{
+				Name:     CsrfCookieName,
+				Value:    csrfToken,
+				Path:     "/",
+				Secure:   isSecureRequest(r),
+				SameSite: http.SameSiteStrictMode,
+			}
+			http.SetCookie(w, csrfCookie)
+		}
+
+		if isSafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !isSameOriginRequest(r) {
+			h.actionPkg.Warningf("A %s request was made to %s from another origin", r.Method, r.URL.Path)
+			h.recordAuditEvent(r.Context(), audit.EventRequestForgeryDetected, map[string]any{"path": r.URL.Path, "reason": "another origin"})
+			h.respondWithPortalError(w, r, ErrKeyPortalRequestForgeryDetected)
+			return
+		}
+
+		providedToken := r.Header.Get(CsrfTokenHeader)
+		if providedToken == "" || subtle.ConstantTimeCompare([]byte(providedToken), []byte(csrfCookie.Value)) != 1 {
+			h.actionPkg.Warningf("A %s request was made to %s without a valid CSRF token", r.Method, r.URL.Path)
+			h.recordAuditEvent(r.Context(), audit.EventRequestForgeryDetected, map[string]any{"path": r.URL.Path, "reason": "invalid CSRF token"})
+			h.respondWithPortalError(w, r, ErrKeyPortalRequestForgeryDetected)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isSafeMethod returns true when requests with the method don't change the portal's state
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// isSameOriginRequest returns true when the request's Origin, or its Referer when no Origin is
+// sent, is the portal's host
+func isSameOriginRequest(r *http.Request) bool {
+	source := r.Header.Get("Origin")
+	if source == "" {
+		source = r.Header.Get("Referer")
+	}
+
+	if source == "" {
+		return true
+	}
+
+	sourceUrl, err := url.Parse(source)
+	if err != nil || sourceUrl.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(sourceUrl.Host, r.Host) {
+		return true
+	}
+
+	forwardedHost := r.Header.Get("X-Forwarded-Host")
+	return forwardedHost != "" && strings.EqualFold(sourceUrl.Host, forwardedHost)
+}
@@ -0,0 +1,200 @@
+package portal_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+// okHandler responds with 200 OK, standing in for the portal's routes
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+// newTestHandler returns a portal handler whose output is discarded
+func newTestHandler(r *portal.NewHandlerRequest) *portal.Handler {
+	r.ActionPkg = githubactions.New(githubactions.WithWriter(io.Discard))
+	return portal.NewHandler(r)
+}
+
+func TestHandler_SecureHeaders(t *testing.T) {
+
+	var nonces []string
+
+	handler := newTestHandler(&portal.NewHandlerRequest{}).SecureHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonces = append(nonces, portal.CspNonce(r))
+	}))
+
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		nonce := nonces[i]
+		assert.NotEmpty(t, nonce)
+		assert.Contains(t, recorder.Header().Get("Content-Security-Policy"), "script-src 'self' 'nonce-"+nonce+"'")
+		assert.Contains(t, recorder.Header().Get("Content-Security-Policy"), "frame-ancestors 'none'")
+		assert.Equal(t, "DENY", recorder.Header().Get("X-Frame-Options"))
+		assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "same-origin", recorder.Header().Get("Referrer-Policy"))
+	}
+
+	// each response gets its own nonce
+	assert.NotEqual(t, nonces[0], nonces[1])
+
+	// requests that didn't pass through the middleware have no nonce
+	assert.Empty(t, portal.CspNonce(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
+func TestHandler_RequireCsrfToken(t *testing.T) {
+
+	const csrfToken = "csrf-token-from-the-cookie"
+
+	tests := []struct {
+		name              string
+		method            string
+		csrfCookie        string
+		csrfHeader        string
+		headers           map[string]string
+		expectedStatus    int
+		expectedNewCookie bool
+	}{
+		{
+			name:              "success - cookie is set on the first request",
+			method:            http.MethodGet,
+			expectedStatus:    http.StatusOK,
+			expectedNewCookie: true,
+		},
+		{
+			name:           "success - safe requests don't need the token",
+			method:         http.MethodGet,
+			csrfCookie:     csrfToken,
+			headers:        map[string]string{"Origin": "https://attacker.example.com"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "success - same origin request with the token",
+			method:         http.MethodPost,
+			csrfCookie:     csrfToken,
+			csrfHeader:     csrfToken,
+			headers:        map[string]string{"Origin": "http://portal.example.com"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "success - same origin referer with the token",
+			method:         http.MethodDelete,
+			csrfCookie:     csrfToken,
+			csrfHeader:     csrfToken,
+			headers:        map[string]string{"Referer": "http://portal.example.com/?label=report"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:       "success - origin of the tunnel's forwarded host",
+			method:     http.MethodPost,
+			csrfCookie: csrfToken,
+			csrfHeader: csrfToken,
+			headers: map[string]string{
+				"Origin":           "https://quiet-fox.ngrok.app",
+				"X-Forwarded-Host": "quiet-fox.ngrok.app",
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "success - no origin or referer with the token",
+			method:         http.MethodPost,
+			csrfCookie:     csrfToken,
+			csrfHeader:     csrfToken,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "failed - missing token",
+			method:         http.MethodPost,
+			csrfCookie:     csrfToken,
+			headers:        map[string]string{"Origin": "http://portal.example.com"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "failed - mismatched token",
+			method:         http.MethodPost,
+			csrfCookie:     csrfToken,
+			csrfHeader:     "guessed-token",
+			headers:        map[string]string{"Origin": "http://portal.example.com"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:              "failed - token without the cookie",
+			method:            http.MethodPost,
+			csrfHeader:        csrfToken,
+			expectedStatus:    http.StatusForbidden,
+			expectedNewCookie: true,
+		},
+		{
+			name:           "failed - cross-origin request",
+			method:         http.MethodPost,
+			csrfCookie:     csrfToken,
+			csrfHeader:     csrfToken,
+			headers:        map[string]string{"Origin": "https://attacker.example.com"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "failed - cross-origin referer",
+			method:         http.MethodPut,
+			csrfCookie:     csrfToken,
+			csrfHeader:     csrfToken,
+			headers:        map[string]string{"Referer": "https://attacker.example.com/portal.example.com"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "failed - opaque origin",
+			method:         http.MethodPost,
+			csrfCookie:     csrfToken,
+			csrfHeader:     csrfToken,
+			headers:        map[string]string{"Origin": "null"},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			request := httptest.NewRequest(tt.method, "http://portal.example.com/api/v1/portal/submit", strings.NewReader(""))
+			if tt.csrfCookie != "" {
+				request.AddCookie(&http.Cookie{Name: portal.CsrfCookieName, Value: tt.csrfCookie})
+			}
+			if tt.csrfHeader != "" {
+				request.Header.Set(portal.CsrfTokenHeader, tt.csrfHeader)
+			}
+			for key, value := range tt.headers {
+				request.Header.Set(key, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			newTestHandler(&portal.NewHandlerRequest{}).RequireCsrfToken(okHandler).ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+
+			var newCookie *http.Cookie
+			for _, cookie := range recorder.Result().Cookies() {
+				if cookie.Name == portal.CsrfCookieName {
+					newCookie = cookie
+				}
+			}
+
+			if !tt.expectedNewCookie {
+				assert.Nil(t, newCookie)
+				return
+			}
+
+			if assert.NotNil(t, newCookie) {
+				assert.NotEmpty(t, newCookie.Value)
+				assert.NotEqual(t, tt.csrfHeader, newCookie.Value)
+				assert.Equal(t, http.SameSiteStrictMode, newCookie.SameSite)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package portal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/archive"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+)
+
+const (
+	// multipartOverheadAllowance is the number of bytes allowed on top of an input field's upload
+	// limit to account for the multipart boundaries and headers of the upload request
+	multipartOverheadAllowance int64 = 1 << 20
+
+	// defaultMaxUploadSize is the largest upload (in bytes) accepted when the input field has no upload limits
+	defaultMaxUploadSize int64 = 1 << 30
+
+	// defaultMaxUploadFiles is the largest number of files accepted in an upload session when the
+	// input field has no limit
+	defaultMaxUploadFiles = 100
+
+	// contentTypeSniffLength is the number of bytes used to sniff the content type of uploaded files
+	contentTypeSniffLength = 512
+
+	// quarantineDirName is the name of the directory, alongside the cache directories, that flagged
+	// files are quarantined in
+	quarantineDirName = ".quarantine"
+
+	// extractedDirSuffix is appended to the path of a staged archive to get the directory it is extracted into
+	extractedDirSuffix = ".extracted"
+
+	// uploadChunkSize is the largest chunk (in bytes) of a file that can be sent to an upload session
+	uploadChunkSize int64 = 8 << 20
+
+	// maxUploadSessionRequestSize is the largest request (in bytes) that can be sent to start an upload session
+	maxUploadSessionRequestSize int64 = 1 << 20
+
+	// maxOpenUploadSessions is the largest number of upload sessions that can be in progress at once
+	maxOpenUploadSessions = 10
+
+	// uploadSessionIdleTimeout is how long an upload session can go without receiving
+	// a chunk before it is removed, along with its partially uploaded file(s)
+	uploadSessionIdleTimeout = 15 * time.Minute
+
+	// uploadSessionCleanUpInterval is how often idle upload sessions are looked for
+	uploadSessionCleanUpInterval = time.Minute
+)
+
+// errFileSizeLimitExceeded is returned when a streamed file is larger than the allowed size
+var errFileSizeLimitExceeded = errors.New("file size limit exceeded")
+
+// uploadLimits holds the limits of the upload(s) for a file/multifile field,
+// a limit of 0 means there is no limit
+type uploadLimits struct {
+
+	// maxFiles is the maximum number of files in a single upload
+	maxFiles int
+
+	// maxFileSize is the largest size (in bytes) of a single file
+	maxFileSize int64
+
+	// maxUploadSize is the largest combined size (in bytes) of the files
+	maxUploadSize int64
+}
+
+// stagedUploadFile is a file that has been received and is waiting to be moved
+// into its input field's cache directory
+type stagedUploadFile struct {
+
+	// path is where the file has been staged
+	path string
+
+	// originalFileName is the name the file was uploaded with
+	originalFileName string
+
+	// fileName is the (sanitised) name the file will be stored as
+	fileName string
+
+	// size is the size of the file in bytes
+	size int64
+
+	// contentType is the content type of the file
+	contentType string
+
+	// sha256 is the hex encoded SHA-256 checksum of the file
+	sha256 string
+
+	// extractedDirName is the name of the directory the file is extracted into, if it is an archive
+	extractedDirName string
+
+	// extraction the outcome of extracting the file, if it is an archive
+	extraction *archive.Result
+
+	// scan the outcome of scanning the file, if an upload scanner is configured
+	scan *fields.FileScanResult
+}
+
+// manifestEntry returns the manifest entry of the staged file
+func (s *stagedUploadFile) manifestEntry() fields.FileManifestEntry {
+	return fields.FileManifestEntry{
+		OriginalName: s.originalFileName,
+		StoredName:   s.fileName,
+		Size:         s.size,
+		ContentType:  s.contentType,
+		SHA256:       s.sha256,
+		Scan:         s.scan,
+	}
+}
+
+// getUploadLimits returns the upload limits of the given file/multifile field. Limits that
+// can't be determined are treated as not set, as they are validated when the fields are parsed.
+func getUploadLimits(field *fields.Field) uploadLimits {
+	var limits uploadLimits
+
+	limits.maxFiles = field.Properties.GetMaxFiles()
+	limits.maxFileSize, _ = field.Properties.GetMaxFileSize()
+	limits.maxUploadSize, _ = field.Properties.GetMaxUploadSize()
+
+	return limits
+}
+
+// getPartOriginalFileName returns the file name of the multipart part exactly as it was
+// provided by the client, or an empty string if the part isn't a file
+func getPartOriginalFileName(part *multipart.Part) string {
+	_, params, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+	if err != nil {
+		return ""
+	}
+	return params["filename"]
+}
+
+// streamToFile writes the content read from the reader to a new file at the given path, returning
+// the number of bytes written and the hex encoded SHA-256 checksum of the content
+func streamToFile(reader io.Reader, filePath string, maxSize int64) (int64, string, error) {
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if maxSize > 0 {
+		// read one byte past the limit to detect larger files
+		reader = io.LimitReader(reader, maxSize+1)
+	}
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hash), reader)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil && maxSize > 0 && written > maxSize {
+		err = errFileSizeLimitExceeded
+	}
+
+	if err != nil {
+		os.Remove(filePath)
+		return written, "", err
+	}
+
+	return written, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeToFileAt writes the content read from the reader to the file at the given path, starting at the
+// given offset, and returns the number of bytes written
+func writeToFileAt(reader io.Reader, filePath string, offset, maxSize int64) (int64, error) {
+
+	file, err := os.OpenFile(filePath, os.O_WRONLY, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return 0, err
+	}
+
+	// read one byte past the limit to detect larger content
+	written, err := io.Copy(file, io.LimitReader(reader, maxSize+1))
+	if err == nil && written > maxSize {
+		err = errFileSizeLimitExceeded
+	}
+
+	if err != nil {
+		file.Truncate(offset)
+		file.Close()
+		return 0, err
+	}
+
+	return written, file.Close()
+}
+
+// newUploadSessionId returns a random, hard to guess id for an upload session
+func newUploadSessionId() (string, error) {
+	id := make([]byte, 16)
+
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
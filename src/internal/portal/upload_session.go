@@ -0,0 +1,479 @@
+package portal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+	"github.com/gorilla/mux"
+	"github.com/ooaklee/reply"
+)
+
+// uploadSession is a chunked upload in progress for a file/multifile field
+type uploadSession struct {
+
+	// id is the id of the upload session
+	id string
+
+	// inputFieldLabel is the label of the input field the file(s) are uploaded for
+	inputFieldLabel string
+
+	// stagingDir is where the file(s) are staged while they are received
+	stagingDir string
+
+	// files the file(s) of the upload session
+	files []*uploadSessionFile
+
+	// lastActivity is when the upload session last received a request
+	lastActivity time.Time
+
+	// closed is true once the upload session has completed, been aborted or expired
+	closed bool
+
+	// mutex guards the upload session while a chunk is written
+	mutex sync.Mutex
+}
+
+// uploadSessionFile is a file of an upload session
+type uploadSessionFile struct {
+
+	// path is where the file is staged
+	path string
+
+	// originalFileName is the name the file is uploaded with
+	originalFileName string
+
+	// fileName is the (sanitised) name the file will be stored as
+	fileName string
+
+	// size is the size of the file in bytes
+	size int64
+
+	// offset is the number of bytes of the file received
+	offset int64
+
+	// typeChecked is true once the file has been checked against the input field's accepted file types
+	typeChecked bool
+}
+
+// toResponse returns the response describing the upload session
+func (s *uploadSession) toResponse() *UploadSessionResponse {
+	response := UploadSessionResponse{
+		ID:        s.id,
+		Label:     s.inputFieldLabel,
+		ChunkSize: uploadChunkSize,
+		Files:     []UploadSessionFile{},
+	}
+
+	for index, file := range s.files {
+		response.Files = append(response.Files, UploadSessionFile{
+			Index:        index,
+			OriginalName: file.originalFileName,
+			StoredName:   file.fileName,
+			Size:         file.size,
+			Offset:       file.offset,
+		})
+	}
+
+	return &response
+}
+
+// CreateUploadSession returns response for request to start a chunked upload of file(s)
+// to the portal
+func (h *Handler) CreateUploadSession(w http.ResponseWriter, r *http.Request) {
+	var request CreateUploadSessionRequest
+	var totalUploadSize int64 = 0
+	var fileNamesInUse map[string]bool = map[string]bool{fields.FileManifestName: true}
+
+	// Get the input field the file(s) are uploaded for
+	inputField := h.getUploadInputField(w, r)
+	if inputField == nil {
+		return
+	}
+	inputFieldLabel := inputField.Label
+
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUploadSessionRequestSize)).Decode(&request)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to read upload session request: %v", err)
+
+		h.respondWithError(w, errors.New(ErrKeyMalformedUploadSessionRequest))
+		return
+	}
+
+	if len(request.Files) == 0 {
+		h.actionPkg.Errorf("No files detected in upload session request")
+
+		h.respondWithError(w, errors.New(ErrNoFilesProvidedWithUploadRequest))
+		return
+	}
+
+	limits := getUploadLimits(inputField)
+	if limits.maxUploadSize == 0 {
+		limits.maxUploadSize = defaultMaxUploadSize
+	}
+	if limits.maxFiles == 0 {
+		limits.maxFiles = defaultMaxUploadFiles
+	}
+	h.actionPkg.Debugf("  • Upload limits for %s: %+v", inputFieldLabel, limits)
+
+	if len(request.Files) > limits.maxFiles {
+		h.actionPkg.Errorf("Upload for %s exceeded the limit of %d file(s)", inputFieldLabel, limits.maxFiles)
+		h.respondWithUploadLimitExceeded(w, ErrKeyTooManyFilesProvided, strconv.Itoa(limits.maxFiles))
+		return
+	}
+
+	for _, file := range request.Files {
+		if file.Name == "" || file.Size < 0 {
+			h.actionPkg.Errorf("Upload session request for %s has a file without a name or with a negative size", inputFieldLabel)
+
+			h.respondWithError(w, errors.New(ErrKeyMalformedUploadSessionRequest))
+			return
+		}
+
+		if limits.maxFileSize > 0 && file.Size > limits.maxFileSize {
+			h.actionPkg.Errorf("File %s exceeded the limit of %s", file.Name, toolbox.BytesToHumanReadable(limits.maxFileSize))
+			h.respondWithUploadLimitExceeded(w, ErrKeyFileTooLarge, toolbox.BytesToHumanReadable(limits.maxFileSize))
+			return
+		}
+
+		totalUploadSize += file.Size
+	}
+
+	if totalUploadSize > limits.maxUploadSize {
+		h.actionPkg.Errorf("Upload for %s exceeded the limit of %s", inputFieldLabel, toolbox.BytesToHumanReadable(limits.maxUploadSize))
+		h.respondWithUploadLimitExceeded(w, ErrKeyUploadTooLarge, toolbox.BytesToHumanReadable(limits.maxUploadSize))
+		return
+	}
+
+	sessionId, err := newUploadSessionId()
+	if err != nil {
+		h.actionPkg.Errorf("Unable to generate upload session id: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Stage the file(s) next to the input field's cache directory
+	stagingDir, err := os.MkdirTemp(filepath.Dir(h.getInputFieldCacheDir(inputFieldLabel)), fmt.Sprintf(".staging-%s-", inputFieldLabel))
+	if err != nil {
+		h.actionPkg.Errorf("Unable to create staging directory for upload session: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	session := &uploadSession{
+		id:              sessionId,
+		inputFieldLabel: inputFieldLabel,
+		stagingDir:      stagingDir,
+		lastActivity:    time.Now(),
+	}
+
+	for index, file := range request.Files {
+
+		// Each file is staged in its own directory under the name it will be stored as
+		fileName := toolbox.UniqueFileName(toolbox.SanitiseFileName(file.Name), fileNamesInUse)
+		fileDir := filepath.Join(stagingDir, strconv.Itoa(index))
+
+		err = os.Mkdir(fileDir, 0755)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(fileDir, fileName), nil, 0644)
+		}
+		if err != nil {
+			h.actionPkg.Errorf("Unable to stage file %s for upload session: %v", file.Name, err)
+			os.RemoveAll(stagingDir)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		session.files = append(session.files, &uploadSessionFile{
+			path:             filepath.Join(fileDir, fileName),
+			originalFileName: file.Name,
+			fileName:         fileName,
+			size:             file.Size,
+		})
+	}
+
+	h.uploadSessionsMutex.Lock()
+	if len(h.uploadSessions) >= maxOpenUploadSessions {
+		h.uploadSessionsMutex.Unlock()
+		h.actionPkg.Errorf("Upload session for %s refused, %d are already in progress", inputFieldLabel, maxOpenUploadSessions)
+		os.RemoveAll(stagingDir)
+
+		h.respondWithError(w, errors.New(ErrKeyTooManyUploadSessions))
+		return
+	}
+	h.uploadSessions[session.id] = session
+	h.uploadSessionsMutex.Unlock()
+
+	h.actionPkg.Infof("Upload session started for %d file(s) of %s (%s)", len(session.files), inputFieldLabel, toolbox.BytesToHumanReadable(totalUploadSize))
+
+	getBaseResponseHandler().NewHTTPDataResponse(w, http.StatusCreated, session.toResponse())
+}
+
+// GetUploadSession returns response for request to get the progress of an upload session
+func (h *Handler) GetUploadSession(w http.ResponseWriter, r *http.Request) {
+
+	session := h.getUploadSession(w, r)
+	if session == nil {
+		return
+	}
+	defer session.mutex.Unlock()
+
+	getBaseResponseHandler().NewHTTPDataResponse(w, http.StatusOK, session.toResponse())
+}
+
+// UploadChunk returns response for request to send the next chunk of a file of an upload session
+func (h *Handler) UploadChunk(w http.ResponseWriter, r *http.Request) {
+
+	session := h.getUploadSession(w, r)
+	if session == nil {
+		return
+	}
+	defer session.mutex.Unlock()
+
+	fileIndex, err := strconv.Atoi(mux.Vars(r)[UploadFileIndexUriVariableId])
+	if err != nil || fileIndex < 0 || fileIndex >= len(session.files) {
+		h.actionPkg.Errorf("No file found for index %s of upload session", mux.Vars(r)[UploadFileIndexUriVariableId])
+
+		h.respondWithError(w, errors.New(ErrKeyUploadFileNotFound))
+		return
+	}
+	file := session.files[fileIndex]
+
+	offset, err := strconv.ParseInt(r.Header.Get(UploadOffsetHeader), 10, 64)
+	if err != nil {
+		h.actionPkg.Errorf("Invalid %s header provided for chunk of %s", UploadOffsetHeader, file.originalFileName)
+
+		h.respondWithError(w, errors.New(ErrKeyMalformedUploadChunk))
+		return
+	}
+
+	// Let the client know where to resume from if the chunk isn't the one expected
+	if offset != file.offset {
+		h.actionPkg.Debugf("Chunk of %s sent for offset %d, expected %d", file.originalFileName, offset, file.offset)
+		w.Header().Set(UploadOffsetHeader, strconv.FormatInt(file.offset, 10))
+
+		h.respondWithError(w, errors.New(ErrKeyUploadOffsetMismatch),
+			reply.WithMeta(map[string]interface{}{"offset": file.offset}))
+		return
+	}
+
+	written, err := writeToFileAt(http.MaxBytesReader(w, r.Body, uploadChunkSize), file.path, offset, file.size-offset)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to write chunk of %s at offset %d: %v", file.originalFileName, offset, err)
+
+		h.respondWithError(w, errors.New(ErrKeyMalformedUploadChunk))
+		return
+	}
+
+	file.offset += written
+	session.lastActivity = time.Now()
+
+	h.actionPkg.Debugf("  • Received %d of %d bytes of %s", file.offset, file.size, file.originalFileName)
+
+	if !file.typeChecked && file.offset >= min(contentTypeSniffLength, file.size) && !h.checkUploadSessionFileType(w, session, file) {
+		return
+	}
+
+	w.Header().Set(UploadOffsetHeader, strconv.FormatInt(file.offset, 10))
+
+	getBaseResponseHandler().NewHTTPDataResponse(w, http.StatusOK, session.toResponse())
+}
+
+// CompleteUploadSession returns response for request to complete an upload session once all of
+// its files have been received
+func (h *Handler) CompleteUploadSession(w http.ResponseWriter, r *http.Request) {
+	var incompleteFiles []string = []string{}
+	var stagedFiles []stagedUploadFile = []stagedUploadFile{}
+	var failedFileUploads []string = []string{}
+
+	session := h.getUploadSession(w, r)
+	if session == nil {
+		return
+	}
+	defer session.mutex.Unlock()
+
+	for _, file := range session.files {
+		if file.offset != file.size {
+			incompleteFiles = append(incompleteFiles, file.originalFileName)
+		}
+	}
+
+	if len(incompleteFiles) > 0 {
+		h.actionPkg.Errorf("Upload session completed before receiving: %s", strings.Join(incompleteFiles, ", "))
+
+		h.respondWithError(w, errors.New(ErrKeyUploadIncomplete),
+			reply.WithMeta(map[string]interface{}{"files": incompleteFiles}))
+		return
+	}
+
+	for _, file := range session.files {
+
+		// Files too small to have been checked when their chunks were received, i.e. empty files
+		if !file.typeChecked && !h.checkUploadSessionFileType(w, session, file) {
+			return
+		}
+
+		manifestEntry, err := fields.NewFileManifestEntry(file.originalFileName, file.path)
+		if err != nil {
+			h.actionPkg.Errorf("Unable to checksum staged file %s: %v", file.originalFileName, err)
+			failedFileUploads = append(failedFileUploads, file.originalFileName)
+			continue
+		}
+
+		stagedFiles = append(stagedFiles, stagedUploadFile{
+			path:             file.path,
+			originalFileName: file.originalFileName,
+			fileName:         file.fileName,
+			size:             manifestEntry.Size,
+			contentType:      manifestEntry.ContentType,
+			sha256:           manifestEntry.SHA256,
+		})
+	}
+
+	h.commitStagedUploads(r.Context(), w, session.inputFieldLabel, stagedFiles, len(session.files), failedFileUploads)
+	h.closeUploadSession(session)
+}
+
+// AbortUploadSession returns response for request to abort an upload session,
+// which removes its partially uploaded file(s)
+func (h *Handler) AbortUploadSession(w http.ResponseWriter, r *http.Request) {
+
+	session := h.getUploadSession(w, r)
+	if session == nil {
+		return
+	}
+	defer session.mutex.Unlock()
+
+	h.closeUploadSession(session)
+	h.actionPkg.Infof("Upload session aborted for %s", session.inputFieldLabel)
+
+	getBaseResponseHandler().NewHTTPBlankResponse(w, http.StatusNoContent)
+}
+
+// CleanUpStaleUploadSessions periodically removes the upload sessions that haven't received a
+// request within the idle timeout, until the context is done
+func (h *Handler) CleanUpStaleUploadSessions(ctx context.Context) {
+
+	ticker := time.NewTicker(uploadSessionCleanUpInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.removeUploadSessionsIdleSince(time.Now())
+			return
+
+		case <-ticker.C:
+			removedSessions := h.removeUploadSessionsIdleSince(time.Now().Add(-uploadSessionIdleTimeout))
+			if removedSessions > 0 {
+				h.actionPkg.Infof("Removed %d idle upload session(s)", removedSessions)
+			}
+		}
+	}
+}
+
+// getUploadSession returns the open upload session targeted by the request, locked for the
+// caller to unlock. If there is no such session, the error is responded with and nil is returned.
+func (h *Handler) getUploadSession(w http.ResponseWriter, r *http.Request) *uploadSession {
+
+	h.uploadSessionsMutex.Lock()
+	session := h.uploadSessions[mux.Vars(r)[UploadSessionIdUriVariableId]]
+	h.uploadSessionsMutex.Unlock()
+
+	if session != nil {
+		session.mutex.Lock()
+
+		// the session may have been closed while waiting for the lock
+		if !session.closed {
+			return session
+		}
+		session.mutex.Unlock()
+	}
+
+	h.actionPkg.Errorf("Upload session not found")
+
+	h.respondWithError(w, errors.New(ErrKeyUploadSessionNotFound))
+	return nil
+}
+
+// checkUploadSessionFileType checks the staged file against the accepted file types of the upload
+// session's input field, closing the upload session if it isn't accepted
+func (h *Handler) checkUploadSessionFileType(w http.ResponseWriter, session *uploadSession, file *uploadSessionFile) bool {
+
+	inputField := h.getField(session.inputFieldLabel)
+
+	contentType, err := toolbox.DetectFileContentType(file.path)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to read staged file %s: %v", file.originalFileName, err)
+		h.closeUploadSession(session)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return false
+	}
+
+	h.actionPkg.Debugf("  • Content Type of %s: %+v", file.originalFileName, contentType)
+
+	if !inputField.Properties.IsAcceptedFileType(file.originalFileName, contentType) {
+		h.actionPkg.Errorf("File %s (%s) is not one of the accepted file types: %s", file.originalFileName, contentType, strings.Join(inputField.Properties.AcceptedFileTypes, ", "))
+		h.closeUploadSession(session)
+
+		h.respondWithError(w, errors.New(ErrKeyFileTypeNotAccepted),
+			reply.WithMeta(map[string]interface{}{
+				"file":     file.originalFileName,
+				"accepted": inputField.Properties.AcceptedFileTypes,
+			}))
+		return false
+	}
+
+	file.typeChecked = true
+	return true
+}
+
+// closeUploadSession removes the upload session along with its staged file(s).
+// The caller is expected to hold the upload session's lock.
+func (h *Handler) closeUploadSession(session *uploadSession) {
+
+	session.closed = true
+
+	h.uploadSessionsMutex.Lock()
+	delete(h.uploadSessions, session.id)
+	h.uploadSessionsMutex.Unlock()
+
+	err := os.RemoveAll(session.stagingDir)
+	if err != nil {
+		h.actionPkg.Warningf("Unable to remove staging directory %s: %v", session.stagingDir, err)
+	}
+}
+
+// removeUploadSessionsIdleSince removes the upload sessions that haven't received a request since
+// the given time, along with their partially uploaded file(s). It returns the number removed.
+func (h *Handler) removeUploadSessionsIdleSince(idleSince time.Time) int {
+	var sessions []*uploadSession
+	var removedSessions int = 0
+
+	h.uploadSessionsMutex.Lock()
+	for _, session := range h.uploadSessions {
+		sessions = append(sessions, session)
+	}
+	h.uploadSessionsMutex.Unlock()
+
+	for _, session := range sessions {
+		session.mutex.Lock()
+		if !session.closed && !session.lastActivity.After(idleSince) {
+			h.closeUploadSession(session)
+			removedSessions++
+		}
+		session.mutex.Unlock()
+	}
+
+	return removedSessions
+}
@@ -0,0 +1,371 @@
+package portal_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/portal"
+)
+
+// newUploadSessionRequest returns a request for the upload session route with the given URI variables
+func newUploadSessionRequest(method, target string, body []byte, vars map[string]string) *http.Request {
+	request := httptest.NewRequest(method, target, bytes.NewReader(body))
+	return mux.SetURLVars(request, vars)
+}
+
+// createUploadSession starts an upload session of the files for the artifact field
+func createUploadSession(t *testing.T, handler *portal.Handler, files ...portal.CreateUploadSessionFile) (*httptest.ResponseRecorder, portal.UploadSessionResponse) {
+	t.Helper()
+
+	body, err := json.Marshal(portal.CreateUploadSessionRequest{Files: files})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.CreateUploadSession(recorder, newUploadSessionRequest(http.MethodPost, "/api/v1/upload/sessions?label=artifact", body, nil))
+
+	var response struct {
+		Data portal.UploadSessionResponse `json:"data"`
+	}
+	if recorder.Code == http.StatusCreated {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	}
+
+	return recorder, response.Data
+}
+
+// sendUploadChunk sends the chunk of the file at the index of the upload session for the offset
+func sendUploadChunk(handler *portal.Handler, sessionId string, index int, offset string, chunk string) *httptest.ResponseRecorder {
+	request := newUploadSessionRequest(http.MethodPatch, "/api/v1/upload/sessions/"+sessionId+"/files/"+strconv.Itoa(index), []byte(chunk), map[string]string{
+		portal.UploadSessionIdUriVariableId: sessionId,
+		portal.UploadFileIndexUriVariableId: strconv.Itoa(index),
+	})
+	if offset != "" {
+		request.Header.Set(portal.UploadOffsetHeader, offset)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.UploadChunk(recorder, request)
+	return recorder
+}
+
+// getUploadSessionStatus returns the status code of a request for the upload session's progress
+func getUploadSessionStatus(handler *portal.Handler, sessionId string) int {
+	recorder := httptest.NewRecorder()
+	handler.GetUploadSession(recorder, newUploadSessionRequest(http.MethodGet, "/api/v1/upload/sessions/"+sessionId, nil, map[string]string{
+		portal.UploadSessionIdUriVariableId: sessionId,
+	}))
+	return recorder.Code
+}
+
+func TestHandler_CreateUploadSession(t *testing.T) {
+	var tooManyFiles []portal.CreateUploadSessionFile
+	for i := 0; i <= 100; i++ {
+		tooManyFiles = append(tooManyFiles, portal.CreateUploadSessionFile{Name: fmt.Sprintf("%d.txt", i), Size: 1})
+	}
+
+	tests := []struct {
+		name           string
+		properties     fields.FieldProperties
+		files          []portal.CreateUploadSessionFile
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "files within the limits",
+			properties:     fields.FieldProperties{Type: "multifile"},
+			files:          []portal.CreateUploadSessionFile{{Name: "../a.txt", Size: 5}, {Name: "a.txt", Size: 0}},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"stored_name":"a (1).txt"`,
+		},
+		{
+			name:           "no files",
+			properties:     fields.FieldProperties{Type: "multifile"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "No files detected",
+		},
+		{
+			name:           "a file without a name",
+			properties:     fields.FieldProperties{Type: "multifile"},
+			files:          []portal.CreateUploadSessionFile{{Size: 5}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Upload session request malformatted",
+		},
+		{
+			name:           "a file with a negative size",
+			properties:     fields.FieldProperties{Type: "multifile"},
+			files:          []portal.CreateUploadSessionFile{{Name: "a.txt", Size: -1}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Upload session request malformatted",
+		},
+		{
+			name:           "more files than the field accepts",
+			properties:     fields.FieldProperties{Type: "multifile", MaxFiles: 1},
+			files:          []portal.CreateUploadSessionFile{{Name: "a.txt", Size: 1}, {Name: "b.txt", Size: 1}},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "More files were uploaded than the input field accepts",
+		},
+		{
+			name:           "more files than accepted by default",
+			properties:     fields.FieldProperties{Type: "multifile"},
+			files:          tooManyFiles,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `"limit":"100"`,
+		},
+		{
+			name:           "a file larger than the max file size",
+			properties:     fields.FieldProperties{Type: "multifile", MaxFileSize: "1KB"},
+			files:          []portal.CreateUploadSessionFile{{Name: "a.txt", Size: 2048}},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "An uploaded file exceeds the maximum file size of the input field",
+		},
+		{
+			name:           "files larger than the max total size together",
+			properties:     fields.FieldProperties{Type: "multifile", MaxTotalSize: "1KB"},
+			files:          []portal.CreateUploadSessionFile{{Name: "a.txt", Size: 800}, {Name: "b.txt", Size: 800}},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "The uploaded file(s) exceed the maximum total size of the input field",
+		},
+		{
+			name:           "files larger than accepted by default",
+			properties:     fields.FieldProperties{Type: "multifile"},
+			files:          []portal.CreateUploadSessionFile{{Name: "a.txt", Size: 2 << 30}},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `"limit":"1GB"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, cacheDir := newUploadHandler(t, fields.Field{Label: "artifact", Properties: tt.properties}, nil)
+
+			recorder, _ := createUploadSession(t, handler, tt.files...)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+
+			_, staged := cacheDirFileNames(t, cacheDir)
+			assert.Equal(t, tt.expectedStatus == http.StatusCreated, staged)
+		})
+	}
+}
+
+func TestHandler_CreateUploadSessionRejectsMalformedRequests(t *testing.T) {
+	handler, _ := newUploadHandler(t, fields.Field{Label: "artifact", Properties: fields.FieldProperties{Type: "file"}}, nil)
+
+	recorder := httptest.NewRecorder()
+	handler.CreateUploadSession(recorder, newUploadSessionRequest(http.MethodPost, "/api/v1/upload/sessions?label=artifact", []byte("{"), nil))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Upload session request malformatted")
+}
+
+func TestHandler_CreateUploadSessionLimitsOpenSessions(t *testing.T) {
+	handler, cacheDir := newUploadHandler(t, fields.Field{Label: "artifact", Properties: fields.FieldProperties{Type: "file"}}, nil)
+
+	for i := 0; i < 10; i++ {
+		recorder, _ := createUploadSession(t, handler, portal.CreateUploadSessionFile{Name: "a.txt", Size: 5})
+		require.Equal(t, http.StatusCreated, recorder.Code)
+	}
+
+	recorder, _ := createUploadSession(t, handler, portal.CreateUploadSessionFile{Name: "a.txt", Size: 5})
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Too many uploads are in progress")
+
+	staged, err := filepath.Glob(filepath.Join(filepath.Dir(cacheDir), ".staging-*"))
+	require.NoError(t, err)
+	assert.Len(t, staged, 10)
+}
+
+func TestHandler_UploadChunk(t *testing.T) {
+	handler, _ := newUploadHandler(t, fields.Field{Label: "artifact", Properties: fields.FieldProperties{Type: "file"}}, nil)
+
+	_, session := createUploadSession(t, handler, portal.CreateUploadSessionFile{Name: "a.txt", Size: 11})
+
+	// the first chunk is received
+	recorder := sendUploadChunk(handler, session.ID, 0, "0", "hello")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "5", recorder.Header().Get(portal.UploadOffsetHeader))
+	assert.Contains(t, recorder.Body.String(), `"offset":5`)
+
+	// a chunk sent again for an offset that has already been received is refused with where to resume from
+	recorder = sendUploadChunk(handler, session.ID, 0, "0", "hello")
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.Equal(t, "5", recorder.Header().Get(portal.UploadOffsetHeader))
+	assert.Contains(t, recorder.Body.String(), `"offset":5`)
+
+	tests := []struct {
+		name           string
+		sessionId      string
+		index          int
+		offset         string
+		chunk          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "unknown session",
+			sessionId:      "unknown",
+			offset:         "5",
+			chunk:          " world",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Upload session not found",
+		},
+		{
+			name:           "unknown file",
+			sessionId:      session.ID,
+			index:          1,
+			offset:         "5",
+			chunk:          " world",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Upload session has no file at the given index",
+		},
+		{
+			name:           "missing offset",
+			sessionId:      session.ID,
+			chunk:          " world",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Upload chunk malformatted",
+		},
+		{
+			name:           "chunk larger than the rest of the file",
+			sessionId:      session.ID,
+			offset:         "5",
+			chunk:          " world and more",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Upload chunk malformatted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sendUploadChunk(handler, tt.sessionId, tt.index, tt.offset, tt.chunk)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+		})
+	}
+
+	// the upload resumes from the offset received
+	assert.Equal(t, http.StatusOK, getUploadSessionStatus(handler, session.ID))
+
+	recorder = sendUploadChunk(handler, session.ID, 0, "5", " world")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "11", recorder.Header().Get(portal.UploadOffsetHeader))
+}
+
+func TestHandler_UploadChunkRejectsFileTypes(t *testing.T) {
+	handler, cacheDir := newUploadHandler(t, fields.Field{Label: "artifact", Properties: fields.FieldProperties{Type: "file", AcceptedFileTypes: []string{"image/*"}}}, nil)
+
+	_, session := createUploadSession(t, handler, portal.CreateUploadSessionFile{Name: "a.txt", Size: 1024})
+
+	// the file's type is checked as soon as enough of it has been received to sniff it
+	recorder := sendUploadChunk(handler, session.ID, 0, "0", strings.Repeat("a", 512))
+	assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+
+	assert.Equal(t, http.StatusNotFound, getUploadSessionStatus(handler, session.ID))
+
+	_, staged := cacheDirFileNames(t, cacheDir)
+	assert.False(t, staged, "the staged files are removed")
+}
+
+func TestHandler_CompleteUploadSession(t *testing.T) {
+	handler, cacheDir := newUploadHandler(t, fields.Field{Label: "artifact", Properties: fields.FieldProperties{Type: "multifile"}}, nil)
+
+	_, session := createUploadSession(t, handler, portal.CreateUploadSessionFile{Name: "a.txt", Size: 5}, portal.CreateUploadSessionFile{Name: "empty.txt", Size: 0})
+
+	completeUploadSession := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.CompleteUploadSession(recorder, newUploadSessionRequest(http.MethodPost, "/api/v1/upload/sessions/"+session.ID+"/complete", nil, map[string]string{
+			portal.UploadSessionIdUriVariableId: session.ID,
+		}))
+		return recorder
+	}
+
+	// the previous upload is kept until all of the files have been received
+	recorder := completeUploadSession()
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"files":["a.txt"]`)
+
+	fileNames, _ := cacheDirFileNames(t, cacheDir)
+	assert.Equal(t, []string{"previous.txt"}, fileNames)
+
+	require.Equal(t, http.StatusOK, sendUploadChunk(handler, session.ID, 0, "0", "hello").Code)
+
+	recorder = completeUploadSession()
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"status":"success"`)
+
+	fileNames, staged := cacheDirFileNames(t, cacheDir)
+	assert.ElementsMatch(t, []string{fields.FileManifestName, "a.txt", "empty.txt"}, fileNames)
+	assert.False(t, staged, "the staged files are removed")
+
+	content, err := os.ReadFile(filepath.Join(cacheDir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	// the session can't be completed again
+	assert.Equal(t, http.StatusNotFound, completeUploadSession().Code)
+}
+
+func TestHandler_AbortUploadSession(t *testing.T) {
+	handler, cacheDir := newUploadHandler(t, fields.Field{Label: "artifact", Properties: fields.FieldProperties{Type: "file"}}, nil)
+
+	_, session := createUploadSession(t, handler, portal.CreateUploadSessionFile{Name: "a.txt", Size: 11})
+	require.Equal(t, http.StatusOK, sendUploadChunk(handler, session.ID, 0, "0", "hello").Code)
+
+	recorder := httptest.NewRecorder()
+	handler.AbortUploadSession(recorder, newUploadSessionRequest(http.MethodDelete, "/api/v1/upload/sessions/"+session.ID, nil, map[string]string{
+		portal.UploadSessionIdUriVariableId: session.ID,
+	}))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+	assert.Equal(t, http.StatusNotFound, getUploadSessionStatus(handler, session.ID))
+
+	fileNames, staged := cacheDirFileNames(t, cacheDir)
+	assert.Equal(t, []string{"previous.txt"}, fileNames)
+	assert.False(t, staged, "the staged files are removed")
+}
+
+func TestHandler_CleanUpStaleUploadSessions(t *testing.T) {
+	handler, cacheDir := newUploadHandler(t, fields.Field{Label: "artifact", Properties: fields.FieldProperties{Type: "file"}}, nil)
+
+	_, session := createUploadSession(t, handler, portal.CreateUploadSessionFile{Name: "a.txt", Size: 11})
+
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	cleanedUp := make(chan struct{})
+	go func() {
+		handler.CleanUpStaleUploadSessions(ctx)
+		close(cleanedUp)
+	}()
+
+	// the sessions that haven't been idle for long are kept
+	assert.Equal(t, http.StatusOK, getUploadSessionStatus(handler, session.ID))
+
+	// all of the sessions are removed once the portal stops
+	ctxCancel()
+	select {
+	case <-cleanedUp:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the upload sessions weren't cleaned up")
+	}
+
+	assert.Equal(t, http.StatusNotFound, getUploadSessionStatus(handler, session.ID))
+
+	_, staged := cacheDirFileNames(t, cacheDir)
+	assert.False(t, staged, "the staged files are removed")
+}
@@ -0,0 +1,501 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/archive"
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+)
+
+const (
+	// multiLineTerminator is the line used to finish entering a multi-line value
+	multiLineTerminator = "."
+
+	// maxLineSize is the largest line (in bytes) that can be entered in the terminal
+	maxLineSize = 1024 * 1024
+)
+
+// actionPkg manages business logic around action request
+type actionPkg interface {
+	Infof(msg string, args ...any)
+	Debugf(msg string, args ...any)
+	Errorf(msg string, args ...any)
+}
+
+// Prompter walks through the fields in the terminal, prompting the user for their values
+type Prompter struct {
+
+	// actionPkg represents the githubactions package
+	actionPkg actionPkg
+
+	// title is the title displayed before the first field
+	title string
+
+	// fields the fields the user is prompted for
+	fields *fields.Fields
+
+	// input is where the user's answers are read from
+	input io.Reader
+
+	// output is where the prompts are written to
+	output io.Writer
+
+	// lines holds the lines read from the input
+	lines chan string
+
+	// inputFieldLabelToCacheDirMapping mapping of input field label to its cache directory
+	inputFieldLabelToCacheDirMapping map[string]string
+
+	// uploadScanner scans the provided files before they are copied, if configured
+	uploadScanner scanner.Scanner
+}
+
+// NewPrompterRequest holds everything needed to create a prompter
+type NewPrompterRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg actionPkg
+
+	// Title is the title displayed before the first field
+	Title string
+
+	// Fields the fields the user is prompted for
+	Fields *fields.Fields
+
+	// Input is where the user's answers are read from, i.e. os.Stdin
+	Input io.Reader
+
+	// Output is where the prompts are written to, i.e. os.Stderr
+	Output io.Writer
+
+	// InputFieldLabelToCacheDirMapping mapping of input field label to its cache directory
+	InputFieldLabelToCacheDirMapping map[string]string
+
+	// UploadScanner scans the provided files before they are copied, if configured
+	UploadScanner scanner.Scanner
+}
+
+// NewPrompter returns a prompter
+func NewPrompter(r *NewPrompterRequest) *Prompter {
+	return &Prompter{
+		actionPkg:                        r.ActionPkg,
+		title:                            r.Title,
+		fields:                           r.Fields,
+		input:                            r.Input,
+		output:                           r.Output,
+		inputFieldLabelToCacheDirMapping: r.InputFieldLabelToCacheDirMapping,
+		uploadScanner:                    r.UploadScanner,
+	}
+}
+
+// Run prompts the user for the value of each field in turn until it is valid, returning
+// the values keyed by field label
+func (p *Prompter) Run(ctx context.Context) (url.Values, error) {
+	var values url.Values = make(url.Values)
+
+	if p.fields == nil {
+		return values, nil
+	}
+
+	p.startReadingLines()
+
+	if p.title != "" {
+		fmt.Fprintf(p.output, "\n%s\n%s\n", p.title, strings.Repeat("=", len(p.title)))
+	}
+
+	for i := range p.fields.Fields {
+		field := &p.fields.Fields[i]
+
+		p.printFieldHeader(field)
+
+		// read-only fields can't be changed on the portal either
+		if field.Properties.ReadOnly {
+			fmt.Fprintf(p.output, "  %s (read-only)\n", field.Properties.DefaultValue)
+			values[field.Label] = []string{field.Properties.DefaultValue}
+			continue
+		}
+
+		for {
+			providedValues, err := p.promptField(ctx, field)
+			if err != nil {
+				return nil, err
+			}
+
+			normalisedValues, err := field.NormaliseValues(providedValues)
+			if fieldValidationError, ok := err.(*fields.FieldValidationError); ok {
+				fmt.Fprintf(p.output, "  ✗ %s %s, please try again\n", field.Label, fieldValidationError.Message)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if field.IsFile() {
+				normalisedValues, err = p.copyFilesToCacheDir(ctx, field, normalisedValues)
+				if err != nil {
+					fmt.Fprintf(p.output, "  ✗ %v, please try again\n", err)
+					continue
+				}
+			}
+
+			values[field.Label] = normalisedValues
+			break
+		}
+	}
+
+	fmt.Fprintln(p.output)
+	p.actionPkg.Debugf("All %d field(s) answered in the terminal", len(p.fields.Fields))
+
+	return values, nil
+}
+
+// promptField prompts the user for the value(s) of the given field based on its type
+func (p *Prompter) promptField(ctx context.Context, field *fields.Field) ([]string, error) {
+
+	switch {
+	case field.IsGroup():
+		return p.promptGroup(ctx, field)
+
+	case field.IsStructured():
+		value, err := p.askMultiLine(ctx, field.Properties.DefaultValue)
+		return []string{value}, err
+	}
+
+	switch field.Properties.Type {
+	case "multiselect":
+		return p.promptMultiSelect(ctx, field)
+
+	case "file", "multifile":
+		question := "Path to the file"
+		if field.Properties.Type == "multifile" {
+			question = "Paths to the files (comma separated)"
+		}
+		answer, err := p.ask(ctx, question, "")
+		if err != nil {
+			return nil, err
+		}
+		return strings.Split(answer, ","), nil
+	}
+
+	value, err := p.promptScalar(ctx, &field.Properties)
+	return []string{value}, err
+}
+
+// promptScalar prompts the user for the value of a single value field (text, textarea, number,
+// boolean or select), which is also used for the sub-fields of group (table) fields
+func (p *Prompter) promptScalar(ctx context.Context, fieldProperties *fields.FieldProperties) (string, error) {
+
+	switch fieldProperties.Type {
+	case "textarea":
+		return p.askMultiLine(ctx, fieldProperties.DefaultValue)
+
+	case "boolean":
+		answer, err := p.ask(ctx, "Yes or no [y/n]", fieldProperties.DefaultValue)
+		if err != nil {
+			return "", err
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes", "true":
+			return "true", nil
+		case "n", "no", "false":
+			return "false", nil
+		}
+		return answer, nil
+
+	case "select":
+		choices, err := fieldProperties.GetChoices()
+		if err != nil {
+			return "", err
+		}
+		p.printChoices(choices, func(choice string) bool { return choice == fieldProperties.DefaultValue })
+
+		answer, err := p.ask(ctx, "Choose an option (number or value)", fieldProperties.DefaultValue)
+		if err != nil {
+			return "", err
+		}
+		return resolveChoice(answer, choices), nil
+	}
+
+	return p.ask(ctx, "Value", fieldProperties.DefaultValue)
+}
+
+// promptMultiSelect prompts the user to choose any number of the choices of a multiselect field
+func (p *Prompter) promptMultiSelect(ctx context.Context, field *fields.Field) ([]string, error) {
+
+	choices, err := field.Properties.GetChoices()
+	if err != nil {
+		return nil, err
+	}
+	p.printChoices(choices, field.IsDefaultChoice)
+
+	answer, err := p.ask(ctx, "Choose option(s) (comma separated numbers or values)", field.Properties.DefaultValue)
+	if err != nil {
+		return nil, err
+	}
+
+	var selectedChoices []string
+	for _, item := range strings.Split(answer, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			selectedChoices = append(selectedChoices, resolveChoice(item, choices))
+		}
+	}
+
+	return selectedChoices, nil
+}
+
+// promptGroup prompts the user for the rows of a group (table) field, one sub-field at a time.
+// The rows are returned as a JSON array of objects, as submitted by the portal.
+func (p *Prompter) promptGroup(ctx context.Context, field *fields.Field) ([]string, error) {
+	var rows []map[string]string
+
+	for {
+		minRowsReached := len(rows) >= field.Properties.MinRows && (len(rows) > 0 || !field.Properties.Required)
+		maxRowsReached := field.Properties.MaxRows > 0 && len(rows) >= field.Properties.MaxRows
+
+		if maxRowsReached {
+			break
+		}
+
+		if minRowsReached {
+			question := "Add a row? [y/n]"
+			if len(rows) > 0 {
+				question = "Add another row? [y/n]"
+			}
+			answer, err := p.ask(ctx, question, "n")
+			if err != nil {
+				return nil, err
+			}
+			if !strings.HasPrefix(strings.ToLower(answer), "y") {
+				break
+			}
+		}
+
+		fmt.Fprintf(p.output, "  Row %d\n", len(rows)+1)
+
+		row := make(map[string]string, len(field.Properties.SubFields))
+		for i := range field.Properties.SubFields {
+			subField := &field.Properties.SubFields[i]
+
+			fmt.Fprintf(p.output, "  · %s%s\n", fieldDisplayName(subField), requiredMarker(subField))
+
+			value, err := p.promptScalar(ctx, &subField.Properties)
+			if err != nil {
+				return nil, err
+			}
+			row[subField.Label] = value
+		}
+
+		rows = append(rows, row)
+	}
+
+	rowsBytes, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{string(rowsBytes)}, nil
+}
+
+// copyFilesToCacheDir scans and copies the files at the given paths into the cache directory of the
+// file field, returning the names of the copied files
+func (p *Prompter) copyFilesToCacheDir(ctx context.Context, field *fields.Field, filePaths []string) ([]string, error) {
+	var fileNames []string = []string{}
+	var fileNamesInUse map[string]bool = map[string]bool{fields.FileManifestName: true}
+	var manifest fields.FileManifest = fields.FileManifest{Field: field.Label}
+
+	cacheDir := p.inputFieldLabelToCacheDirMapping[field.Label]
+	if cacheDir == "" {
+		return nil, fmt.Errorf("no cache directory found for %s", field.Label)
+	}
+
+	archiveExtractor, err := field.NewArchiveExtractor()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, filePath := range filePaths {
+		contentType, err := toolbox.DetectFileContentType(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read file: %v", err)
+		}
+		if !field.Properties.IsAcceptedFileType(filePath, contentType) {
+			return nil, fmt.Errorf("%s (%s) is not one of the accepted file types: %s", filePath, contentType, strings.Join(field.Properties.AcceptedFileTypes, ", "))
+		}
+
+		scan, err := scanner.ScanProvidedFile(ctx, p.uploadScanner, filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		fileName, err := toolbox.CopyFileToDir(filePath, cacheDir, fileNamesInUse)
+		if err != nil {
+			return nil, fmt.Errorf("unable to copy file: %v", err)
+		}
+
+		manifestEntry, err := fields.NewFileManifestEntry(filePath, filepath.Join(cacheDir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("unable to checksum file: %v", err)
+		}
+		manifestEntry.Scan = scan
+
+		if archiveExtractor.Extracts(fileName) {
+			dirName := toolbox.UniqueFileName(archive.ExtractedDirName(fileName), fileNamesInUse)
+			extraction, err := archiveExtractor.Extract(fileName, filepath.Join(cacheDir, fileName), filepath.Join(cacheDir, dirName))
+			if err != nil {
+				return nil, fmt.Errorf("unable to extract %s: %v", filePath, err)
+			}
+			manifestEntry.RecordExtraction(dirName, extraction)
+			p.actionPkg.Debugf("Extracted %d file(s) from %s into %s", len(extraction.Files), filePath, dirName)
+		}
+
+		p.actionPkg.Debugf("Copied %s into %s", filePath, cacheDir)
+		fileNames = append(fileNames, fileName)
+		manifest.Files = append(manifest.Files, manifestEntry)
+	}
+
+	if len(manifest.Files) > 0 {
+		_, err := manifest.WriteToDir(cacheDir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to write file manifest: %v", err)
+		}
+	}
+
+	return fileNames, nil
+}
+
+// ask writes the question and returns the user's answer, or the default value
+// when no answer is provided
+func (p *Prompter) ask(ctx context.Context, question, defaultValue string) (string, error) {
+
+	if defaultValue != "" {
+		fmt.Fprintf(p.output, "  %s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(p.output, "  %s: ", question)
+	}
+
+	answer, err := p.readLine(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return defaultValue, nil
+	}
+
+	return answer, nil
+}
+
+// askMultiLine reads lines until the multi-line terminator is entered, returning the
+// default value when no lines are provided
+func (p *Prompter) askMultiLine(ctx context.Context, defaultValue string) (string, error) {
+	var lines []string
+
+	fmt.Fprintf(p.output, "  Enter the value, finishing with a line containing only '%s'", multiLineTerminator)
+	if defaultValue != "" {
+		fmt.Fprintf(p.output, " (leave empty to use the default)")
+	}
+	fmt.Fprintln(p.output, ":")
+
+	for {
+		line, err := p.readLine(ctx)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(line) == multiLineTerminator {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return defaultValue, nil
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// printFieldHeader writes the name and description of the field
+func (p *Prompter) printFieldHeader(field *fields.Field) {
+	fmt.Fprintf(p.output, "\n%s%s (%s)\n", fieldDisplayName(field), requiredMarker(field), field.Properties.Type)
+	if field.Properties.Description != "" {
+		fmt.Fprintf(p.output, "  %s\n", field.Properties.Description)
+	}
+}
+
+// printChoices writes the numbered list of choices, marking the ones selected by default
+func (p *Prompter) printChoices(choices []string, isDefaultChoice func(choice string) bool) {
+	for i, choice := range choices {
+		if isDefaultChoice(choice) {
+			fmt.Fprintf(p.output, "  %d) %s (default)\n", i+1, choice)
+			continue
+		}
+		fmt.Fprintf(p.output, "  %d) %s\n", i+1, choice)
+	}
+}
+
+// startReadingLines reads the input line by line in the background
+func (p *Prompter) startReadingLines() {
+	p.lines = make(chan string)
+
+	go func() {
+		defer close(p.lines)
+
+		scanner := bufio.NewScanner(p.input)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+		for scanner.Scan() {
+			p.lines <- scanner.Text()
+		}
+		if err := scanner.Err(); err != nil {
+			p.actionPkg.Errorf("Unable to read from the terminal: %v", err)
+		}
+	}()
+}
+
+// readLine returns the next line entered in the terminal
+func (p *Prompter) readLine(ctx context.Context) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case line, ok := <-p.lines:
+		if !ok {
+			return "", errors.ErrPromptInputClosed
+		}
+		return strings.TrimRight(line, "\r"), nil
+	}
+}
+
+// resolveChoice returns the choice matching the given answer, which can either be the
+// number shown next to the choice or the choice itself
+func resolveChoice(answer string, choices []string) string {
+	if index, err := strconv.Atoi(answer); err == nil && index >= 1 && index <= len(choices) {
+		return choices[index-1]
+	}
+	return answer
+}
+
+// fieldDisplayName returns the name of the field shown to the user
+func fieldDisplayName(field *fields.Field) string {
+	if field.Properties.Display != "" {
+		return field.Properties.Display
+	}
+	return field.Label
+}
+
+// requiredMarker returns the marker shown next to required fields
+func requiredMarker(field *fields.Field) string {
+	if field.Properties.Required {
+		return " *"
+	}
+	return ""
+}
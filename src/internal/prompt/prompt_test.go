@@ -0,0 +1,202 @@
+package prompt_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/prompt"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrompter_Run(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		fields         []fields.Field
+		input          string
+		expectedValues url.Values
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name:           "text uses the default value when no answer is provided",
+			fields:         []fields.Field{{Label: "name", Properties: fields.FieldProperties{Type: "text", DefaultValue: "Leon"}}},
+			input:          "\n",
+			expectedValues: url.Values{"name": {"Leon"}},
+		},
+		{
+			name:           "text answer is trimmed",
+			fields:         []fields.Field{{Label: "name", Properties: fields.FieldProperties{Type: "text"}}},
+			input:          "  Silcott  \n",
+			expectedValues: url.Values{"name": {"Silcott"}},
+		},
+		{
+			name:           "number re-prompts until the answer is valid",
+			fields:         []fields.Field{{Label: "replicas", Properties: fields.FieldProperties{Type: "number", NumberMax: 5}}},
+			input:          "three\n9\n3\n",
+			expectedValues: url.Values{"replicas": {"3"}},
+			expectedOutput: "✗ replicas must be at most 5, please try again",
+		},
+		{
+			name:           "boolean accepts yes or no",
+			fields:         []fields.Field{{Label: "canary", Properties: fields.FieldProperties{Type: "boolean"}}},
+			input:          "Y\n",
+			expectedValues: url.Values{"canary": {"true"}},
+		},
+		{
+			name:           "select accepts the number of the choice",
+			fields:         []fields.Field{{Label: "env", Properties: fields.FieldProperties{Type: "select", Choices: []string{"dev", "staging"}}}},
+			input:          "2\n",
+			expectedValues: url.Values{"env": {"staging"}},
+			expectedOutput: "2) staging",
+		},
+		{
+			name:           "multiselect accepts numbers and values",
+			fields:         []fields.Field{{Label: "regions", Properties: fields.FieldProperties{Type: "multiselect", Choices: []string{"eu", "us", "ap"}}}},
+			input:          "1, ap\n",
+			expectedValues: url.Values{"regions": {"eu", "ap"}},
+		},
+		{
+			name:           "textarea reads lines until the terminator",
+			fields:         []fields.Field{{Label: "notes", Properties: fields.FieldProperties{Type: "textarea"}}},
+			input:          "first line\nsecond line\n.\n",
+			expectedValues: url.Values{"notes": {"first line\nsecond line"}},
+		},
+		{
+			name:           "json is normalised",
+			fields:         []fields.Field{{Label: "override", Properties: fields.FieldProperties{Type: "json"}}},
+			input:          "{\n  \"flag\": \"dark-mode\"\n}\n.\n",
+			expectedValues: url.Values{"override": {`{"flag":"dark-mode"}`}},
+		},
+		{
+			name: "group is prompted row by row",
+			fields: []fields.Field{{Label: "services", Properties: fields.FieldProperties{Type: "group", MinRows: 1, SubFields: []fields.Field{
+				{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+				{Label: "replicas", Properties: fields.FieldProperties{Type: "number"}},
+			}}}},
+			input:          "api\n2\ny\njobs\n\nn\n",
+			expectedValues: url.Values{"services": {`[{"name":"api","replicas":2},{"name":"jobs","replicas":null}]`}},
+			expectedOutput: "Row 2",
+		},
+		{
+			name:           "read-only fields aren't prompted for",
+			fields:         []fields.Field{{Label: "env", Properties: fields.FieldProperties{Type: "text", DefaultValue: "production", ReadOnly: true}}},
+			expectedValues: url.Values{"env": {"production"}},
+			expectedOutput: "production (read-only)",
+		},
+		{
+			name:          "failed - input closed before the field was answered",
+			fields:        []fields.Field{{Label: "name", Properties: fields.FieldProperties{Type: "text", Required: true}}},
+			input:         "\n",
+			expectedError: errors.ErrPromptInputClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var output bytes.Buffer
+
+			prompter := prompt.NewPrompter(&prompt.NewPrompterRequest{
+				ActionPkg: githubactions.New(githubactions.WithWriter(io.Discard)),
+				Title:     "Deploy",
+				Fields:    &fields.Fields{Fields: tt.fields},
+				Input:     strings.NewReader(tt.input),
+				Output:    &output,
+			})
+
+			values, err := prompter.Run(context.Background())
+
+			assert.Contains(t, output.String(), tt.expectedOutput)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedValues, values)
+		})
+	}
+}
+
+func TestPrompter_RunCancelled(t *testing.T) {
+
+	inputReader, inputWriter := io.Pipe()
+	defer inputWriter.Close()
+
+	prompter := prompt.NewPrompter(&prompt.NewPrompterRequest{
+		ActionPkg: githubactions.New(githubactions.WithWriter(io.Discard)),
+		Fields:    &fields.Fields{Fields: []fields.Field{{Label: "name", Properties: fields.FieldProperties{Type: "text"}}}},
+		Input:     inputReader,
+		Output:    io.Discard,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := prompter.Run(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// fakeScanner flags the files whose content holds "EICAR"
+type fakeScanner struct{}
+
+func (fakeScanner) Scan(ctx context.Context, fileName, filePath string) (*scanner.Result, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(string(content), "EICAR") {
+		return &scanner.Result{Flagged: true, Detail: "Eicar-Signature"}, nil
+	}
+	return &scanner.Result{Flagged: false}, nil
+}
+
+func (fakeScanner) Verify() error { return nil }
+
+func (fakeScanner) Name() string { return "fake" }
+
+func TestPrompter_RunScansFiles(t *testing.T) {
+
+	sourceDir := t.TempDir()
+	cacheDir := t.TempDir()
+
+	infectedPath := filepath.Join(sourceDir, "infected.txt")
+	cleanPath := filepath.Join(sourceDir, "clean.txt")
+	assert.NoError(t, os.WriteFile(infectedPath, []byte("X5O!P%@AP EICAR"), 0644))
+	assert.NoError(t, os.WriteFile(cleanPath, []byte("hello"), 0644))
+
+	var output bytes.Buffer
+
+	prompter := prompt.NewPrompter(&prompt.NewPrompterRequest{
+		ActionPkg:                        githubactions.New(githubactions.WithWriter(io.Discard)),
+		Fields:                           &fields.Fields{Fields: []fields.Field{{Label: "report", Properties: fields.FieldProperties{Type: "file"}}}},
+		Input:                            strings.NewReader(infectedPath + "\n" + cleanPath + "\n"),
+		Output:                           &output,
+		InputFieldLabelToCacheDirMapping: map[string]string{"report": cacheDir},
+		UploadScanner:                    fakeScanner{},
+	})
+
+	values, err := prompter.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"report": {"clean.txt"}}, values)
+	assert.Contains(t, output.String(), "infected.txt was flagged by the fake scan: Eicar-Signature, please try again")
+	assert.NoFileExists(t, filepath.Join(cacheDir, "infected.txt"))
+
+	manifest, err := os.ReadFile(filepath.Join(cacheDir, fields.FileManifestName))
+	assert.NoError(t, err)
+	assert.Contains(t, string(manifest), `"scanner": "fake"`)
+}
@@ -0,0 +1,261 @@
+package replies
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+	"github.com/sethvargo/go-githubactions"
+)
+
+// authorisedAssociations are how commenters must be associated with the repository to answer
+// the portal when it isn't shared with recipients
+var authorisedAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+// NewPollerRequest holds everything needed to create a poller for replies to the portal's comment
+type NewPollerRequest struct {
+
+	// Client is the client used to make requests to the GitHub API
+	Client *githubapi.Client
+
+	// Repository is the repository the comment was made in (i.e. owner/repo)
+	Repository string
+
+	// IssueNumber is the number of the pull request or issue the comment was made on
+	IssueNumber int
+
+	// Since is when the comment was made, replies made before it are ignored (default: now)
+	Since time.Time
+
+	// Prefix is the prefix of the commands in the replies, i.e. "/" for "/answer"
+	Prefix string
+
+	// Interval is how often the replies are checked for
+	Interval time.Duration
+
+	// Fields are the fields the replies answer
+	Fields *fields.Fields
+
+	// Recipients are the GitHub usernames that can answer, when empty the repository's
+	// owners, members and collaborators can
+	Recipients []string
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+
+	// OnSubmitted is called with the values of the first reply that answers the fields
+	OnSubmitted func(values url.Values, submittedBy, commentUrl string)
+
+	// OnRejected is called when the first valid reply rejects the portal
+	OnRejected func(rejectedBy, commentUrl string)
+}
+
+// Poller checks for replies to the portal's comment, submitting or rejecting the portal
+// with the first valid reply from an authorised user
+type Poller struct {
+
+	// client is the client used to make requests to the GitHub API
+	client *githubapi.Client
+
+	// repository is the repository the comment was made in
+	repository string
+
+	// issueNumber is the number of the pull request or issue the comment was made on
+	issueNumber int
+
+	// since is when the comment was made
+	since time.Time
+
+	// prefix is the prefix of the commands in the replies
+	prefix string
+
+	// interval is how often the replies are checked for
+	interval time.Duration
+
+	// fields are the fields the replies answer
+	fields *fields.Fields
+
+	// recipients are the GitHub usernames that can answer
+	recipients []string
+
+	// action represents the githubactions package
+	action *githubactions.Action
+
+	// onSubmitted is called with the values of the first reply that answers the fields
+	onSubmitted func(values url.Values, submittedBy, commentUrl string)
+
+	// onRejected is called when the first valid reply rejects the portal
+	onRejected func(rejectedBy, commentUrl string)
+
+	// checkedReplies holds when each checked reply was last updated, keyed by its ID
+	checkedReplies map[int64]time.Time
+}
+
+// NewPoller returns a poller for replies to the portal's comment
+func NewPoller(r *NewPollerRequest) *Poller {
+	since := r.Since
+	if since.IsZero() {
+		since = time.Now()
+	}
+
+	pollerFields := r.Fields
+	if pollerFields == nil {
+		pollerFields = &fields.Fields{}
+	}
+
+	return &Poller{
+		client:         r.Client,
+		repository:     r.Repository,
+		issueNumber:    r.IssueNumber,
+		since:          since,
+		prefix:         r.Prefix,
+		interval:       r.Interval,
+		fields:         pollerFields,
+		recipients:     r.Recipients,
+		action:         r.ActionPkg,
+		onSubmitted:    r.OnSubmitted,
+		onRejected:     r.OnRejected,
+		checkedReplies: make(map[int64]time.Time),
+	}
+}
+
+// Poll checks for replies every interval until one submits or rejects the portal, or the
+// context is done
+func (p *Poller) Poll(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.action.Debugf("Checking for replies to the portal's comment on #%d every %s", p.issueNumber, p.interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if p.checkReplies(ctx) {
+			return
+		}
+	}
+}
+
+// checkReplies checks the replies made or edited since the last check, returning true once
+// one has submitted or rejected the portal
+func (p *Poller) checkReplies(ctx context.Context) bool {
+	comments, err := p.client.ListIssueComments(ctx, p.repository, p.issueNumber, p.since)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.action.Warningf("Unable to check for replies to the portal's comment on #%d: %v", p.issueNumber, err)
+		}
+		return false
+	}
+
+	for _, comment := range comments {
+		if comment.CreatedAt.Before(p.since) || comment.User.Type == "Bot" {
+			continue
+		}
+
+		if checkedAt, ok := p.checkedReplies[comment.Id]; ok && !comment.UpdatedAt.After(checkedAt) {
+			continue
+		}
+		p.checkedReplies[comment.Id] = comment.UpdatedAt
+
+		command, err := p.fields.ParseReplyCommand(comment.Body, p.prefix)
+		if command == nil && err == nil {
+			continue
+		}
+
+		if !p.isAuthorised(comment) {
+			p.action.Warningf("Ignoring the reply from %s as they aren't authorised to answer the portal", comment.User.Login)
+			continue
+		}
+
+		if err != nil {
+			p.replyWithProblems(ctx, comment, []string{err.Error()})
+			continue
+		}
+
+		if command.Action == fields.ReplyActionReject {
+			p.action.Infof("The portal was rejected by %s in a reply: %s", comment.User.Login, comment.HtmlUrl)
+			p.onRejected(comment.User.Login, comment.HtmlUrl)
+			return true
+		}
+
+		values, problems := p.resolveAnswers(command)
+		if len(problems) > 0 {
+			p.replyWithProblems(ctx, comment, problems)
+			continue
+		}
+
+		p.action.Infof("The inputs were answered by %s in a reply: %s", comment.User.Login, comment.HtmlUrl)
+		p.onSubmitted(values, comment.User.Login, comment.HtmlUrl)
+		return true
+	}
+
+	return false
+}
+
+// resolveAnswers validates the reply's answers against the fields, returning the values to
+// submit or the problems with the answers. Files can't be provided in a reply.
+func (p *Poller) resolveAnswers(command *fields.ReplyCommand) (url.Values, []string) {
+	var problems []string
+
+	for _, field := range p.fields.Fields {
+		if _, ok := command.Answers[field.Label]; ok && field.IsFile() {
+			problems = append(problems, fmt.Sprintf("`%s`: files can only be provided through the portal", field.Label))
+			delete(command.Answers, field.Label)
+		}
+	}
+
+	values, answerErrors := p.fields.ResolveAnswers(command.Answers)
+
+	labels := make([]string, 0, len(answerErrors))
+	for label := range answerErrors {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		problems = append(problems, fmt.Sprintf("`%s`: %s", label, answerErrors[label]))
+	}
+
+	return values, problems
+}
+
+// isAuthorised returns true when the commenter is one of the recipients or, when there are
+// none, is an owner, member or collaborator of the repository
+func (p *Poller) isAuthorised(comment githubapi.IssueComment) bool {
+	if len(p.recipients) == 0 {
+		return authorisedAssociations[comment.AuthorAssociation]
+	}
+
+	for _, recipient := range p.recipients {
+		if strings.EqualFold(recipient, comment.User.Login) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replyWithProblems lets the commenter know why their reply couldn't be used
+func (p *Poller) replyWithProblems(ctx context.Context, comment githubapi.IssueComment, problems []string) {
+	p.action.Warningf("The reply from %s couldn't be used: %s", comment.User.Login, strings.Join(problems, "; "))
+
+	body := fmt.Sprintf("@%s your [reply](%s) couldn't be used, edit it or reply again:\n\n- %s\n", comment.User.Login, comment.HtmlUrl, strings.Join(problems, "\n- "))
+
+	_, err := p.client.CreateIssueComment(ctx, p.repository, p.issueNumber, body)
+	if err != nil {
+		p.action.Warningf("Unable to reply to %s: %v", comment.User.Login, err)
+	}
+}
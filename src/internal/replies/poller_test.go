@@ -0,0 +1,226 @@
+package replies_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+	"github.com/boasihq/interactive-inputs/internal/replies"
+)
+
+// commentedAt is when the portal's comment was made
+var commentedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeCommentsApi responds to each check for replies on #5 of owner/repo with the next of its
+// checks, repeating the last one, and records the replies the poller makes
+type fakeCommentsApi struct {
+	checks [][]githubapi.IssueComment
+
+	mutex   sync.Mutex
+	calls   int
+	replies []string
+}
+
+func (f *fakeCommentsApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if r.URL.Path != "/repos/owner/repo/issues/5/comments" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var body struct {
+			Body string `json:"body"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.replies = append(f.replies, body.Body)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 100 + len(f.replies)})
+		return
+	}
+
+	comments := []githubapi.IssueComment{}
+	if len(f.checks) > 0 {
+		comments = f.checks[min(f.calls, len(f.checks)-1)]
+	}
+	f.calls++
+
+	_ = json.NewEncoder(w).Encode(comments)
+}
+
+func (f *fakeCommentsApi) madeReplies() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]string(nil), f.replies...)
+}
+
+func newTestComment(id int64, login, association, body string, updatedAfter time.Duration) githubapi.IssueComment {
+	return githubapi.IssueComment{
+		Id:                id,
+		HtmlUrl:           "https://github.com/owner/repo/pull/5#issuecomment-" + strconv.FormatInt(id, 10),
+		Body:              body,
+		User:              githubapi.User{Login: login, Type: "User"},
+		AuthorAssociation: association,
+		CreatedAt:         commentedAt.Add(time.Minute),
+		UpdatedAt:         commentedAt.Add(time.Minute + updatedAfter),
+	}
+}
+
+func TestPoller_Poll(t *testing.T) {
+	type outcome struct {
+		submittedValues url.Values
+		submittedBy     string
+		rejectedBy      string
+	}
+
+	tests := []struct {
+		name            string
+		checks          [][]githubapi.IssueComment
+		recipients      []string
+		expectedOutcome outcome
+		expectedReplies int
+	}{
+		{
+			name: "a collaborator's answer submits the portal",
+			checks: [][]githubapi.IssueComment{{
+				newTestComment(1, "octocat", "COLLABORATOR", "Looks good\n/answer name=Leon", 0),
+			}},
+			expectedOutcome: outcome{submittedValues: url.Values{"name": {"Leon"}}, submittedBy: "octocat"},
+		},
+		{
+			name: "a collaborator's rejection rejects the portal",
+			checks: [][]githubapi.IssueComment{{
+				newTestComment(1, "octocat", "MEMBER", "/reject", 0),
+			}},
+			expectedOutcome: outcome{rejectedBy: "octocat"},
+		},
+		{
+			name: "replies from bots, unauthorised users and before the comment are ignored",
+			checks: [][]githubapi.IssueComment{{
+				{Id: 1, Body: "/answer name=Early", User: githubapi.User{Login: "octocat", Type: "User"}, AuthorAssociation: "OWNER", CreatedAt: commentedAt.Add(-time.Minute), UpdatedAt: commentedAt.Add(time.Minute)},
+				{Id: 2, Body: "/answer name=Bot", User: githubapi.User{Login: "github-actions[bot]", Type: "Bot"}, AuthorAssociation: "OWNER", CreatedAt: commentedAt.Add(time.Minute), UpdatedAt: commentedAt.Add(time.Minute)},
+				newTestComment(3, "stranger", "NONE", "/answer name=Stranger", 0),
+				newTestComment(4, "octocat", "OWNER", "/answer name=Leon", 0),
+			}},
+			expectedOutcome: outcome{submittedValues: url.Values{"name": {"Leon"}}, submittedBy: "octocat"},
+		},
+		{
+			name: "only recipients can answer when the portal is shared with them",
+			checks: [][]githubapi.IssueComment{{
+				newTestComment(1, "octocat", "OWNER", "/answer name=Owner", 0),
+				newTestComment(2, "Alice", "NONE", "/answer name=Alice", 0),
+			}},
+			recipients:      []string{"alice"},
+			expectedOutcome: outcome{submittedValues: url.Values{"name": {"Alice"}}, submittedBy: "Alice"},
+		},
+		{
+			name: "invalid answers are replied to and don't submit the portal",
+			checks: [][]githubapi.IssueComment{{
+				newTestComment(1, "octocat", "OWNER", "/answer unknown=value", 0),
+			}},
+			expectedReplies: 1,
+		},
+		{
+			name: "an edited reply is checked again",
+			checks: [][]githubapi.IssueComment{
+				{newTestComment(1, "octocat", "OWNER", "/answer unknown=value", 0)},
+				{newTestComment(1, "octocat", "OWNER", "/answer unknown=value", 0)},
+				{newTestComment(1, "octocat", "OWNER", "/answer name=Leon", time.Minute)},
+			},
+			expectedOutcome: outcome{submittedValues: url.Values{"name": {"Leon"}}, submittedBy: "octocat"},
+			expectedReplies: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeCommentsApi{checks: tt.checks}
+
+			server := httptest.NewServer(api)
+			defer server.Close()
+
+			var actualOutcome outcome
+
+			poller := replies.NewPoller(&replies.NewPollerRequest{
+				Client:      githubapi.NewClient(&githubapi.NewClientRequest{BaseUrl: server.URL, Token: "token"}),
+				Repository:  "owner/repo",
+				IssueNumber: 5,
+				Since:       commentedAt,
+				Prefix:      "/",
+				Interval:    10 * time.Millisecond,
+				Fields: &fields.Fields{Fields: []fields.Field{
+					{Label: "name", Properties: fields.FieldProperties{Type: "text"}},
+				}},
+				Recipients: tt.recipients,
+				ActionPkg:  githubactions.New(githubactions.WithWriter(io.Discard)),
+				OnSubmitted: func(values url.Values, submittedBy, commentUrl string) {
+					actualOutcome.submittedValues = values
+					actualOutcome.submittedBy = submittedBy
+				},
+				OnRejected: func(rejectedBy, commentUrl string) {
+					actualOutcome.rejectedBy = rejectedBy
+				},
+			})
+
+			// polling stops once a reply submits or rejects the portal, otherwise when the context is done
+			ctx, ctxCancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer ctxCancel()
+
+			poller.Poll(ctx)
+
+			assert.Equal(t, tt.expectedOutcome, actualOutcome)
+			assert.Len(t, api.madeReplies(), tt.expectedReplies)
+		})
+	}
+}
+
+func TestPoller_PollRepliesWithProblems(t *testing.T) {
+	api := &fakeCommentsApi{checks: [][]githubapi.IssueComment{{
+		newTestComment(1, "octocat", "OWNER", "/answer unknown=value\n/answer artifact=file.zip", 0),
+	}}}
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	poller := replies.NewPoller(&replies.NewPollerRequest{
+		Client:      githubapi.NewClient(&githubapi.NewClientRequest{BaseUrl: server.URL, Token: "token"}),
+		Repository:  "owner/repo",
+		IssueNumber: 5,
+		Since:       commentedAt,
+		Prefix:      "/",
+		Interval:    10 * time.Millisecond,
+		Fields: &fields.Fields{Fields: []fields.Field{
+			{Label: "artifact", Properties: fields.FieldProperties{Type: "file"}},
+		}},
+		ActionPkg:   githubactions.New(githubactions.WithWriter(io.Discard)),
+		OnSubmitted: func(values url.Values, submittedBy, commentUrl string) {},
+		OnRejected:  func(rejectedBy, commentUrl string) {},
+	})
+
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer ctxCancel()
+
+	poller.Poll(ctx)
+
+	madeReplies := api.madeReplies()
+	if assert.Len(t, madeReplies, 1) {
+		assert.Contains(t, madeReplies[0], "@octocat your [reply]")
+		assert.Contains(t, madeReplies[0], "`artifact`: files can only be provided through the portal")
+		assert.Contains(t, madeReplies[0], "`unknown`: no field is defined with this label")
+	}
+}
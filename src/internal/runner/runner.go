@@ -2,24 +2,49 @@ package runner
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/boasihq/interactive-inputs/internal/archive"
+	"github.com/boasihq/interactive-inputs/internal/audit"
 	"github.com/boasihq/interactive-inputs/internal/config"
 	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/exposer"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/githubapi"
+	"github.com/boasihq/interactive-inputs/internal/metrics"
 	"github.com/boasihq/interactive-inputs/internal/notifier"
 	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/boasihq/interactive-inputs/internal/prompt"
+	"github.com/boasihq/interactive-inputs/internal/replies"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
 	webui "github.com/boasihq/interactive-inputs/internal/web"
 	"github.com/gorilla/mux"
+	githubactions "github.com/sethvargo/go-githubactions"
 	"go.uber.org/zap"
-	"golang.ngrok.com/ngrok"
-	nconfig "golang.ngrok.com/ngrok/config"
 )
 
+// cancelRunGracePeriod is how long the runner is given to stop the job once the run's
+// cancellation has been requested
+const cancelRunGracePeriod = 60 * time.Second
+
+// cancelRunTimeout is how long requesting the run's cancellation can take
+const cancelRunTimeout = 30 * time.Second
+
+// jobUrlLookupTimeout is how long looking up the job the action is running in can take
+const jobUrlLookupTimeout = 10 * time.Second
+
 func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config.Config, embeddedContent fs.FS, embeddedContentFilePathPrefix string) error {
 
 	defer ctxCancel()
@@ -35,13 +60,97 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		return errors.ErrGitHubWorkspaceEnvVarIsMissing
 	}
 
-	// TODO: Get the source job's url that's calling the
-	// action so that we can link to it. and send users
-	// back to it
+	// Create cache directory mapping for all the file and
+	// multifile input fields defined in the config. We'll
+	// use this hold all the files uploaded by the user
+	// during the action run
+	if cfg.Fields != nil {
+		var err error
+		var baseCacheDir string = fmt.Sprintf("%s/.__interactive-inputs-cache", os.Getenv("GITHUB_WORKSPACE"))
+
+		// check fields for file and multifile input fields
+		for _, v := range cfg.Fields.Fields {
+
+			// skip non-file and multifile input fields
+			if v.Properties.Type != "file" && v.Properties.Type != "multifile" {
+				continue
+			}
+
+			// create base cache directory for holding uploaded files
+			// if it doesn't exist
+			if !isInteractiveInputsCacheDirAvailable {
+				// create temp directory to hold uploaded files
+				err = os.MkdirAll(baseCacheDir, os.ModePerm)
+				if err != nil {
+					cfg.Action.Errorf("Unable to base cache directory: %v", zap.Error(err))
+					return err
+				}
+
+				cfg.Action.Debugf("Base cache directory created: %s", interactiveInputsCacheDir)
+				isInteractiveInputsCacheDirAvailable = true
+			}
+
+			// create sub-directory for holding uploaded files for
+			// for the current input field
+			cfg.Action.Debugf("Creating cache sub-directory for %s uploads", v.Label)
+			inputFieldCacheDir, err := os.MkdirTemp(baseCacheDir, fmt.Sprintf("%s-%d", v.Label, time.Now().UnixNano()))
+			if err != nil {
+				cfg.Action.Errorf("Unable to create temp directory: %v", zap.Error(err))
+				return err
+			}
+
+			// add mapping of input field label to cache sub-directory
+			inputFieldLabelToCacheDirMapping[v.Label] = inputFieldCacheDir
+		}
+	}
+
+	/// Upload scanner
+	// it's set up before the modes are handled, as files provided in prompt or answers mode are scanned too
+	var uploadScanner scanner.Scanner
+
+	if cfg.UploadScannerCommand != "" {
+		uploadScanner = scanner.NewCommandScanner(&scanner.NewCommandScannerRequest{
+			Command: cfg.UploadScannerCommand,
+		})
+	}
+
+	if cfg.UploadScannerUrl != "" {
+		uploadScanner = scanner.NewHttpScanner(&scanner.NewHttpScannerRequest{
+			Url: cfg.UploadScannerUrl,
+		})
+	}
+
+	if uploadScanner != nil {
+		verifiedUploadScannerErr := uploadScanner.Verify()
+		if verifiedUploadScannerErr != nil {
+			cfg.Action.Errorf("Upload Scanner Verification Failed: %v", verifiedUploadScannerErr)
+			return verifiedUploadScannerErr
+		}
+		cfg.Action.Debugf("Upload Scanner Verification Succeeded, uploaded files will be scanned with %s", uploadScanner.Name())
+	}
+
+	// collect the inputs in the terminal instead of hosting the portal,
+	// no notifications are sent as nobody needs to be pointed to the portal
+	if cfg.Mode == config.ModePrompt {
+		return promptForInputs(ctx, cfg, isRunningLocal, inputFieldLabelToCacheDirMapping, uploadScanner)
+	}
+
+	// use the provided answers and finish straight away, so workflows
+	// can be tested without a human
+	if cfg.Mode == config.ModeAnswers {
+		return submitProvidedAnswers(ctx, cfg, isRunningLocal, inputFieldLabelToCacheDirMapping, uploadScanner)
+	}
+
+	// link to the job the action is running in, rather than its whole run
+	jobUrl := resolveJobUrl(ctx, cfg, isRunningLocal)
+
+	/// Metrics
+	portalMetrics := metrics.NewCollector(&metrics.NewCollectorRequest{})
 
 	/// Notifiers
 	slackNotifier := notifier.NewSlackNotifier(&notifier.NewSlackNotifierRequest{})
 	discordNotifier := notifier.NewDiscordNotifier(&notifier.NewDiscordNotifierRequest{})
+	githubNotifier := notifier.NewGithubNotifier(&notifier.NewGithubNotifierRequest{})
 
 	if cfg.NotifierSlackEnabled {
 		slackNotifier = notifier.NewSlackNotifier(&notifier.NewSlackNotifierRequest{
@@ -51,6 +160,8 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 			BotName:   cfg.NotifierSlackBotName,
 			ActionPkg: cfg.Action,
 			ThreadTs:  cfg.NotifierSlackThreadTs,
+			Metrics:   portalMetrics,
+			JobUrl:    jobUrl,
 		})
 
 		verifiedSlackNotifierErr := slackNotifier.Verify()
@@ -69,6 +180,8 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 			UsernameOverride: cfg.NotifierDiscordUsernameOverride,
 			ActionPkg:        cfg.Action,
 			ThreadId:         cfg.NotifierDiscordThreadId,
+			Metrics:          portalMetrics,
+			JobUrl:           jobUrl,
 		})
 
 		verifiedDiscordNotifierErr := discordNotifier.Verify()
@@ -79,47 +192,48 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		cfg.Action.Debugf("Discord Notifier Verification Succeeded")
 	}
 
-	// Create cache directory mapping for all the file and
-	// multifile input fields defined in the config. We'll
-	// use this hold all the files uploaded by the user
-	// during the action run
-	if cfg.Fields != nil {
-		var err error
-		var baseCacheDir string = fmt.Sprintf("%s/.__interactive-inputs-cache", os.Getenv("GITHUB_WORKSPACE"))
-
-		// check fields for file and multifile input fields
-		for _, v := range cfg.Fields.Fields {
+	if cfg.NotifierGithubEnabled {
+		actionContext, err := cfg.Action.Context()
+		if err != nil {
+			cfg.Action.Errorf("Unable to get action context: %v", err)
+			return err
+		}
 
-			// skip non-file and multifile input fields
-			if v.Properties.Type != "file" && v.Properties.Type != "multifile" {
-				continue
-			}
+		githubNotifier = notifier.NewGithubNotifier(&notifier.NewGithubNotifierRequest{
+			Enabled:      cfg.NotifierGithubEnabled,
+			Client:       newGithubClient(cfg, actionContext),
+			CommitStatus: cfg.NotifierGithubTarget == config.NotifierGithubTargetStatus,
+			ActionPkg:    cfg.Action,
+			Metrics:      portalMetrics,
+			JobUrl:       jobUrl,
+		})
 
-			// create base cache directory for holding uploaded files
-			// if it doesn't exist
-			if !isInteractiveInputsCacheDirAvailable {
-				// create temp directory to hold uploaded files
-				err = os.MkdirAll(baseCacheDir, os.ModePerm)
-				if err != nil {
-					cfg.Action.Errorf("Unable to base cache directory: %v", zap.Error(err))
-					return err
-				}
+		verifiedGithubNotifierErr := githubNotifier.Verify()
+		if verifiedGithubNotifierErr != nil {
+			cfg.Action.Errorf("GitHub Notifier Verification Failed")
+			return verifiedGithubNotifierErr
+		}
+		cfg.Action.Debugf("GitHub Notifier Verification Succeeded")
+	}
 
-				cfg.Action.Debugf("Base cache directory created: %s", interactiveInputsCacheDir)
-				isInteractiveInputsCacheDirAvailable = true
-			}
+	/// Audit log
+	var auditLog *audit.Log
 
-			// create sub-directory for holding uploaded files for
-			// for the current input field
-			cfg.Action.Debugf("Creating cache sub-directory for %s uploads", v.Label)
-			inputFieldCacheDir, err := os.MkdirTemp(baseCacheDir, fmt.Sprintf("%s-%d", v.Label, time.Now().UnixNano()))
-			if err != nil {
-				cfg.Action.Errorf("Unable to create temp directory: %v", zap.Error(err))
-				return err
-			}
+	if cfg.AuditLogPath != "" || cfg.AuditLogWebhookUrl != "" {
+		createdAuditLog, err := audit.NewLog(&audit.NewLogRequest{
+			FilePath:   cfg.AuditLogPath,
+			WebhookUrl: cfg.AuditLogWebhookUrl,
+			ActionPkg:  cfg.Action,
+		})
+		if err != nil {
+			cfg.Action.Errorf("Unable to create the audit log: %v", err)
+			return errors.ErrInvalidAuditLogProvided
+		}
+		auditLog = createdAuditLog
+		defer auditLog.Close()
 
-			// add mapping of input field label to cache sub-directory
-			inputFieldLabelToCacheDirMapping[v.Label] = inputFieldCacheDir
+		if cfg.AuditLogPath != "" && !isRunningLocal {
+			cfg.Action.SetOutput(audit.LogOutput, cfg.AuditLogPath)
 		}
 	}
 
@@ -128,13 +242,87 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		EmbeddedContent:               embeddedContent,
 		EmbeddedContentFilePathPrefix: embeddedContentFilePathPrefix,
 		Config:                        cfg,
+		AuditLog:                      auditLog,
+		Metrics:                       portalMetrics,
 	})
 
-	portalEventHandler := portal.NewHandler(cfg.Action, isRunningLocal, embeddedContent, embeddedContentFilePathPrefix, cfg.GithubToken, inputFieldLabelToCacheDirMapping)
+	// the portal can only be used with the access token, which is added to the link
+	// that's shared and masked so it can't be lifted from the logs
+	var accessToken string
+	if cfg.RequireAccessToken {
+		accessToken = cfg.PortalAccessToken
+		if accessToken == "" {
+			generatedAccessToken, err := portal.NewAccessToken()
+			if err != nil {
+				cfg.Action.Errorf("Unable to generate the portal's access token: %v", err)
+				return err
+			}
+			accessToken = generatedAccessToken
+		}
+		cfg.Action.AddMask(accessToken)
+	}
+
+	// the recipients' links are signed with a key that's generated for each run
+	var recipientLinkKey string
+	if len(cfg.Recipients) > 0 {
+		generatedRecipientLinkKey, err := portal.NewAccessToken()
+		if err != nil {
+			cfg.Action.Errorf("Unable to generate the key the recipients' links are signed with: %v", err)
+			return err
+		}
+		recipientLinkKey = generatedRecipientLinkKey
+	}
+
+	// whoever submitted the inputs is sent on submitted, and whoever cancelled the portal
+	// on cancelled, either of which shuts the portal down
+	submitted := make(chan string, 1)
+	var submittedOnce sync.Once
+	cancelled := make(chan string, 1)
+	var cancelledOnce sync.Once
+
+	portalEventHandler := portal.NewHandler(&portal.NewHandlerRequest{
+		ActionPkg:                        cfg.Action,
+		IsRunningLocal:                   isRunningLocal,
+		EmbeddedContent:                  embeddedContent,
+		EmbeddedContentFilePathPrefix:    embeddedContentFilePathPrefix,
+		GithubToken:                      cfg.GithubToken,
+		InputFieldLabelToCacheDirMapping: inputFieldLabelToCacheDirMapping,
+		Fields:                           cfg.Fields,
+		UploadScanner:                    uploadScanner,
+		QuarantineFlaggedUploads:         cfg.UploadScannerAction == config.UploadScannerActionQuarantine,
+		AccessToken:                      accessToken,
+		Recipients:                       cfg.Recipients,
+		RecipientLinkKey:                 recipientLinkKey,
+		RateLimitRequests:                cfg.RateLimitRequests,
+		RateLimitWindow:                  cfg.RateLimitWindow,
+		AuthLockoutAttempts:              cfg.AuthLockoutAttempts,
+		AuthLockoutWindow:                cfg.AuthLockoutWindow,
+		AuditLog:                         auditLog,
+		Metrics:                          portalMetrics,
+		JobUrl:                           jobUrl,
+		OnLimitTriggered: func(message string) {
+			notifyLimitTriggered(cfg, slackNotifier, discordNotifier, githubNotifier, message)
+		},
+		OnSubmitted: func(submittedBy string) {
+			submittedOnce.Do(func() { submitted <- submittedBy })
+		},
+		OnCancelled: func(cancelledBy string) {
+			cancelledOnce.Do(func() { cancelled <- cancelledBy })
+		},
+	})
+
+	// remove chunked uploads that have been abandoned part way through
+	go portalEventHandler.CleanUpStaleUploadSessions(ctx)
 
 	/// Routes
 	r := mux.NewRouter()
 
+	// the metrics are only exposed when asked for, as they can be reached without the access token
+	var metricsHandler http.Handler
+	if cfg.MetricsEnabled {
+		metricsHandler = portalMetrics
+	}
+
 	portal.AttachRoutes(&portal.AttachRoutesRequest{
 		Router:                        r,
 		PortalEventHandler:            portalEventHandler,
@@ -142,6 +330,7 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		EmbeddedContent:               embeddedContent,
 		EmbeddedContentFilePathPrefix: embeddedContentFilePathPrefix,
 		ActionPkg:                     cfg.Action,
+		MetricsHandler:                metricsHandler,
 	})
 
 	/// Server
@@ -149,208 +338,754 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 	serverInitMessageTmpl := "Your Interactive Inputs portal is reachable at: %s"
 	notifierSlackEnterInputMessageTmpl := "<%s|*Enter required input*>"
 	notifierDiscordEnterInputMessageTmpl := "[**Enter required input**](%s)"
+	notifierGithubEnterInputMessageTmpl := "[**Enter required input**](%s)"
+	notifierRecipientsMessageTmpl := "Input is required from: %s"
+	notifierGithubWithoutLinkMessage := "Input is required, the portal's link isn't posted on public repositories"
+	notifierGithubCommentRepliesMessageTmpl := "%s\n\n%s with a `%sanswer <label>=<value>` line for each field to answer, `%sapprove` to submit the default values, or `%sreject` to reject."
 	universalNotifierFailedToSelfHost := "A failure has occurred while starting/running your self-hosted portal: %v"
 
-	// Determine whether to use ngrok, network IP, or localhost
-	useNetworkIP := cfg.UseNetworkIP
-	var networkIP string
-	var err error
+	/// Exposer
+	portalExposer, listener, portalUrl, err := exposePortal(ctx, cfg, isRunningLocal)
+	if err != nil {
+		return err
+	}
+	defer portalExposer.Close()
 
-	if useNetworkIP {
-		if cfg.NetworkIP != "" {
-			networkIP = cfg.NetworkIP
-		} else {
-			networkIP, err = getNetworkIP()
-			if err != nil {
-				cfg.Action.Errorf("Failed to detect network IP: %v", err)
-				return err
+	// only trust X-Forwarded-For when the tunnel provider, or a proxy the user vouches for, sets it
+	portalEventHandler.SetTrustForwardedFor(portalExposer.SetsForwardedFor() || cfg.TrustForwardedFor)
+
+	// the GitHub notifier isn't given the portal's link on public repositories, where anyone
+	// could use its access token
+	githubNotifierSharesLink := githubNotifier.Enabled()
+	if githubLinkNotifier, ok := githubNotifier.(*notifier.GithubNotifier); ok && githubLinkNotifier.PublicRepository() && accessToken != "" {
+		githubNotifierSharesLink = false
+		if len(cfg.Recipients) == 0 {
+			cfg.Action.Warningf("The repository is public, so the GitHub notifier won't post the portal's link as anyone could use its access token")
+		}
+	}
+
+	// recipients are warned about individually when their link can't be sent to them
+	if accessToken != "" && cfg.PortalAccessToken == "" && len(cfg.Recipients) == 0 && !isRunningLocal && !slackNotifier.Enabled() && !discordNotifier.Enabled() && !githubNotifierSharesLink {
+		cfg.Action.Warningf("The portal's access token is masked in the logs and no notifier can share it, provide 'portal-access-token' or enable a notifier that can share a usable link")
+	}
+
+	portalLink := portal.AccessLink(portalUrl, accessToken)
+	notifierSlackEnterInputMessage := fmt.Sprintf(notifierSlackEnterInputMessageTmpl, portalLink)
+	notifierDiscordEnterInputMessage := fmt.Sprintf(notifierDiscordEnterInputMessageTmpl, portalLink)
+	notifierGithubEnterInputMessage := fmt.Sprintf(notifierGithubEnterInputMessageTmpl, portalLink)
+	commentRepliesLead := "Or reply"
+
+	// each recipient is only sent their own link, so whatever is submitted can be attributed to
+	// them, while the channels are told whose input is required without the links
+	if len(cfg.Recipients) > 0 {
+		err := notifyRecipients(cfg, portalEventHandler, portalUrl, serverInitMessageTmpl, slackNotifier, discordNotifier, githubNotifier)
+		if err != nil {
+			return err
+		}
+
+		var githubMentions []string
+		for _, recipient := range cfg.Recipients {
+			githubMentions = append(githubMentions, githubMention(recipient))
+		}
+
+		notifierSlackEnterInputMessage = fmt.Sprintf(notifierRecipientsMessageTmpl, strings.Join(cfg.Recipients, ", "))
+		notifierDiscordEnterInputMessage = fmt.Sprintf(notifierRecipientsMessageTmpl, strings.Join(cfg.Recipients, ", "))
+		notifierGithubEnterInputMessage = fmt.Sprintf(notifierRecipientsMessageTmpl, strings.Join(githubMentions, ", "))
+		commentRepliesLead = "Reply"
+	} else {
+		cfg.Action.Noticef(serverInitMessageTmpl, portalLink)
+
+		if githubNotifier.Enabled() && !githubNotifierSharesLink {
+			notifierGithubEnterInputMessage = notifierGithubWithoutLinkMessage
+			commentRepliesLead = "Reply"
+		}
+	}
+
+	if cfg.CommentRepliesEnabled {
+		notifierGithubEnterInputMessage = fmt.Sprintf(notifierGithubCommentRepliesMessageTmpl, notifierGithubEnterInputMessage, commentRepliesLead, cfg.CommentReplyPrefix, cfg.CommentReplyPrefix, cfg.CommentReplyPrefix)
+	}
+
+	if slackNotifier.Enabled() {
+		_, err := slackNotifier.Notify(cfg.Title, notifierSlackEnterInputMessage)
+		if err != nil {
+			cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
+			return err
+		}
+	}
+
+	if discordNotifier.Enabled() {
+		_, err := discordNotifier.Notify(cfg.Title, notifierDiscordEnterInputMessage)
+		if err != nil {
+			cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
+			return err
+		}
+	}
+
+	if githubNotifier.Enabled() {
+		_, err := githubNotifier.Notify(cfg.Title, notifierGithubEnterInputMessage)
+		if err != nil {
+			cfg.Action.Errorf("GitHub Notifier Notification Failed: %v", err)
+			return err
+		}
+	}
+
+	server := &http.Server{Handler: r}
+
+	// the portal can also be answered by replying to the GitHub notifier's comment
+	if cfg.CommentRepliesEnabled {
+		pollCommentReplies(ctx, cfg, githubNotifier, portalEventHandler)
+	}
+
+	go func() {
+		// server logic
+		// the notifiers have been verified, so the portal is ready to be used once it's being served
+		if err := server.Serve(portalEventHandler.ReadyListener(listener)); err != nil && err != http.ErrServerClosed {
+			serverErrorMessage := fmt.Sprintf(universalNotifierFailedToSelfHost, err)
+
+			cfg.Action.Errorf(serverErrorMessage)
+			if slackNotifier.Enabled() {
+				_, err := slackNotifier.Notify(cfg.Title, serverErrorMessage)
+				if err != nil {
+					cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
+				}
 			}
+
+			if discordNotifier.Enabled() {
+				_, err := discordNotifier.Notify(cfg.Title, serverErrorMessage)
+				if err != nil {
+					cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
+				}
+			}
+
+			serverDone <- err
 		}
-		cfg.Action.Debugf("Using network IP: %s", networkIP)
+	}()
+
+	select {
+	case err := <-serverDone:
+		completeNotifier(cfg, githubNotifier, false, "The portal failed before the inputs were submitted")
+
+		return handlePrettierTimeoutErrorMessage(err, cfg.Timeout)
+	case submittedBy := <-submitted:
+		submittedMessage := "The inputs have been submitted"
+		if submittedBy != "" {
+			notifySubmittedBy(cfg, isRunningLocal, slackNotifier, discordNotifier, submittedBy)
+			submittedMessage = fmt.Sprintf("The inputs have been submitted by %s", submittedBy)
+		}
+
+		// The inputs have been submitted
+		shutdownPortal(cfg, server)
+		completeNotifier(cfg, githubNotifier, true, submittedMessage)
+
+		return nil
+	case <-cancelled:
+		// The portal has been cancelled
+		shutdownPortal(cfg, server)
+		completeNotifier(cfg, githubNotifier, false, "The portal was cancelled")
+
+		return HandleCancelledPortal(ctx, cfg, isRunningLocal, sleepContext)
+	case <-ctx.Done():
+		// Timeout occurred
+		ctxCancel() // Ensure all resources are cleaned up
+
+		server.Close()
+		completeNotifier(cfg, githubNotifier, false, fmt.Sprintf("The portal timed out after %d seconds", cfg.Timeout))
+
+		return handlePrettierTimeoutErrorMessage(ctx.Err(), cfg.Timeout)
+	}
+
+}
+
+// exposePortal tries each of the configured expose methods in turn, returning the exposer,
+// listener and URL of the first that succeeds
+func exposePortal(ctx context.Context, cfg *config.Config, isRunningLocal bool) (exposer.Exposer, net.Listener, string, error) {
+
+	exposeMethods := cfg.Expose
+	if len(exposeMethods) == 0 {
+		exposeMethods = []string{config.ExposeMethodNgrok}
 	}
 
 	// TODO: Add a flag to enable/disable the ngrok tunnel respsective
 	// of whether the action is running locally or not
-	if !isRunningLocal && !useNetworkIP {
-		ln, err := ngrok.Listen(ctx,
-			nconfig.HTTPEndpoint(),
-			ngrok.WithAuthtoken(cfg.NgrokAuthtoken),
-		)
+	if isRunningLocal {
+		var localExposeMethods []string
+		for _, exposeMethod := range exposeMethods {
+			if exposeMethod == config.ExposeMethodNgrok {
+				cfg.Action.Debugf("Running locally, the portal won't be exposed with %s", config.ExposeMethodNgrok)
+				continue
+			}
+			if exposeMethod != config.ExposeMethodLocalhost {
+				localExposeMethods = append(localExposeMethods, exposeMethod)
+			}
+		}
+		exposeMethods = append(localExposeMethods, config.ExposeMethodLocalhost)
+	}
+
+	// load the provided certificate up front rather than falling back
+	var tlsCertificate *tls.Certificate
+	if cfg.TlsEnabled && cfg.TlsCert != "" {
+		var err error
+		tlsCertificate, err = exposer.LoadCertificate(cfg.TlsCert, cfg.TlsKey)
 		if err != nil {
-			return err
+			cfg.Action.Errorf("Unable to load the provided TLS certificate: %v", err)
+			return nil, nil, "", errors.ErrInvalidTlsConfigProvided
 		}
+	}
 
-		serverInitMessage := fmt.Sprintf(serverInitMessageTmpl, ln.URL())
+	for i, exposeMethod := range exposeMethods {
+		cfg.Action.Debugf("Exposing the portal with %s (%d/%d)", exposeMethod, i+1, len(exposeMethods))
 
-		cfg.Action.Noticef(serverInitMessage)
+		portalExposer, err := newPortalExposer(cfg, exposeMethod, tlsCertificate)
+		if err == nil {
+			var listener net.Listener
+			var portalUrl string
 
-		if slackNotifier.Enabled() {
-			_, err := slackNotifier.Notify(cfg.Title, fmt.Sprintf(notifierSlackEnterInputMessageTmpl, ln.URL()))
-			if err != nil {
-				cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
-				return err
+			listener, portalUrl, err = portalExposer.Expose(ctx)
+			if err == nil {
+				return portalExposer, listener, portalUrl, nil
 			}
+			portalExposer.Close()
 		}
 
-		if discordNotifier.Enabled() {
-			_, err := discordNotifier.Notify(cfg.Title, fmt.Sprintf(notifierDiscordEnterInputMessageTmpl, ln.URL()))
+		if i < len(exposeMethods)-1 {
+			cfg.Action.Warningf("Unable to expose the portal with %s, falling back to %s: %v", exposeMethod, exposeMethods[i+1], err)
+			continue
+		}
+		cfg.Action.Errorf("Unable to expose the portal with %s: %v", exposeMethod, err)
+	}
+
+	cfg.Action.Errorf("The portal couldn't be exposed with any of: %s", strings.Join(exposeMethods, ", "))
+	return nil, nil, "", errors.ErrUnableToExposePortal
+}
+
+// newPortalExposer returns the exposer for the given expose method
+func newPortalExposer(cfg *config.Config, exposeMethod string, tlsCertificate *tls.Certificate) (exposer.Exposer, error) {
+
+	switch exposeMethod {
+	case config.ExposeMethodNgrok:
+		return exposer.NewNgrokExposer(&exposer.NewNgrokExposerRequest{
+			Authtoken: cfg.NgrokAuthtoken,
+		}), nil
+
+	case config.ExposeMethodSsh:
+		return exposer.NewSshExposer(&exposer.NewSshExposerRequest{
+			Destination: cfg.TunnelSshDestination,
+			RemotePort:  cfg.TunnelSshRemotePort,
+			PublicUrl:   cfg.TunnelSshPublicUrl,
+			StartPort:   cfg.StartPort,
+			ActionPkg:   cfg.Action,
+		}), nil
+
+	case config.ExposeMethodCommand:
+		return exposer.NewCommandExposer(&exposer.NewCommandExposerRequest{
+			Command:    cfg.TunnelCommand,
+			UrlPattern: cfg.TunnelUrlPattern,
+			StartPort:  cfg.StartPort,
+			ActionPkg:  cfg.Action,
+		})
+
+	case config.ExposeMethodNetworkIP:
+		networkIP := cfg.NetworkIP
+		if networkIP == "" {
+			detectedNetworkIP, err := exposer.DetectNetworkIP(&exposer.DetectNetworkIPRequest{
+				Interface: cfg.NetworkInterface,
+				Cidr:      cfg.NetworkCidr,
+				Scope:     cfg.NetworkIPScope,
+				Family:    cfg.NetworkIPFamily,
+			})
 			if err != nil {
-				cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
-				return err
+				return nil, err
 			}
+			networkIP = detectedNetworkIP
 		}
+		cfg.Action.Debugf("Using network IP: %s", networkIP)
 
-		go func() {
-			// server logic
-			if err := http.Serve(ln, r); err != nil {
-				serverErrorMessage := fmt.Sprintf(universalNotifierFailedToSelfHost, err)
+		// a hostname keeps the portal's URL stable, the portal is served on the same port either way
+		host := networkIP
+		if cfg.NetworkHostname != "" {
+			host = cfg.NetworkHostname
+			cfg.Action.Debugf("Using network hostname: %s", host)
+		}
 
-				cfg.Action.Errorf(serverErrorMessage)
-				if slackNotifier.Enabled() {
-					_, err := slackNotifier.Notify(cfg.Title, serverErrorMessage)
-					if err != nil {
-						cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
-					}
-				}
+		tlsOptions, err := newPortalTlsOptions(cfg, tlsCertificate, host, networkIP)
+		if err != nil {
+			return nil, err
+		}
 
-				if discordNotifier.Enabled() {
-					_, err := discordNotifier.Notify(cfg.Title, serverErrorMessage)
-					if err != nil {
-						cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
-					}
-				}
+		return exposer.NewBindExposer(&exposer.NewBindExposerRequest{
+			Host:      host,
+			StartPort: cfg.StartPort,
+			Tls:       tlsOptions,
+		}), nil
 
-				serverDone <- err
-			}
-			serverDone <- ln.CloseWithContext(ctx)
-		}()
+	case config.ExposeMethodLocalhost:
+		tlsOptions, err := newPortalTlsOptions(cfg, tlsCertificate, "localhost", "127.0.0.1", "::1")
+		if err != nil {
+			return nil, err
+		}
+
+		return exposer.NewBindExposer(&exposer.NewBindExposerRequest{
+			StartPort: cfg.StartPort,
+			Tls:       tlsOptions,
+		}), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", errors.ErrInvalidExposeMethodProvided, exposeMethod)
+}
+
+// newPortalTlsOptions returns how the portal is served over TLS, or nil when TLS isn't enabled
+func newPortalTlsOptions(cfg *config.Config, tlsCertificate *tls.Certificate, hosts ...string) (*exposer.TlsOptions, error) {
+
+	if !cfg.TlsEnabled {
+		return nil, nil
+	}
+
+	if tlsCertificate == nil {
+		var err error
+		tlsCertificate, err = exposer.NewSelfSignedCertificate(hosts)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate a self-signed certificate: %v", err)
+		}
+
+		cfg.Action.Infof("Serving the portal with a self-signed certificate, its SHA-256 fingerprint is: %s", exposer.CertificateFingerprint(tlsCertificate))
+	}
+
+	return &exposer.TlsOptions{
+		Certificate:  tlsCertificate,
+		RedirectHttp: cfg.TlsRedirectHttp,
+	}, nil
+}
+
+// promptForInputs collects the inputs by prompting in the terminal and outputs
+// them the same way the portal does once its form is submitted
+func promptForInputs(ctx context.Context, cfg *config.Config, isRunningLocal bool, inputFieldLabelToCacheDirMapping map[string]string, uploadScanner scanner.Scanner) error {
+
+	prompter := prompt.NewPrompter(&prompt.NewPrompterRequest{
+		ActionPkg:                        cfg.Action,
+		Title:                            cfg.Title,
+		Fields:                           cfg.Fields,
+		Input:                            os.Stdin,
+		Output:                           os.Stderr,
+		InputFieldLabelToCacheDirMapping: inputFieldLabelToCacheDirMapping,
+		UploadScanner:                    uploadScanner,
+	})
+
+	cfg.Action.Infof("Running in prompt mode, answer the prompts below to provide your inputs")
+
+	values, err := prompter.Run(ctx)
+	if err != nil {
+		return handlePrettierTimeoutErrorMessage(err, cfg.Timeout)
+	}
+
+	portal.OutputSubmittedValues(&portal.OutputSubmittedValuesRequest{
+		ActionPkg:                        cfg.Action,
+		IsRunningLocal:                   isRunningLocal,
+		InputFieldLabelToCacheDirMapping: inputFieldLabelToCacheDirMapping,
+		Fields:                           cfg.Fields,
+		Values:                           values,
+	})
 
+	cfg.Action.Infof("Your inputs have successfully been received!")
+
+	return nil
+}
+
+// submitProvidedAnswers validates the provided answers against the fields and outputs them the
+// same way the portal does once its form is submitted
+func submitProvidedAnswers(ctx context.Context, cfg *config.Config, isRunningLocal bool, inputFieldLabelToCacheDirMapping map[string]string, uploadScanner scanner.Scanner) error {
+	var answers map[string]json.RawMessage
+	var err error
+
+	if cfg.Answers != "" {
+		cfg.Action.Infof("Using the answers provided with IAIP_ANSWERS")
+		answers, err = fields.ParseAnswers([]byte(cfg.Answers))
 	} else {
-		// Find an available port starting from the configured start port
-		if cfg.StartPort == 0 {
-			cfg.StartPort = 8080
+		cfg.Action.Infof("Using the answers provided in %s", cfg.AnswersFile)
+		answers, err = fields.LoadAnswersFromFile(cfg.AnswersFile)
+	}
+	if err != nil {
+		cfg.Action.Errorf("Unable to load the provided answers: %v", err)
+		return errors.ErrInvalidAnswersProvided
+	}
+
+	if cfg.Fields == nil {
+		cfg.Fields = &fields.Fields{}
+	}
+
+	values, answerErrors := cfg.Fields.ResolveAnswers(answers)
+	if len(answerErrors) > 0 {
+		labels := make([]string, 0, len(answerErrors))
+		for label := range answerErrors {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		for _, label := range labels {
+			cfg.Action.Errorf("Invalid answer provided for '%s': %s", label, answerErrors[label])
 		}
-		availablePort, err := findAvailablePort(cfg.StartPort)
+		return errors.ErrInvalidAnswersProvided
+	}
+
+	for _, field := range cfg.Fields.Fields {
+		var fileNames []string = []string{}
+		var fileNamesInUse map[string]bool = map[string]bool{fields.FileManifestName: true}
+		var manifest fields.FileManifest = fields.FileManifest{Field: field.Label}
+
+		cacheDir := inputFieldLabelToCacheDirMapping[field.Label]
+		if !field.IsFile() || cacheDir == "" {
+			continue
+		}
+
+		label := field.Label
+		archiveExtractor, err := field.NewArchiveExtractor()
 		if err != nil {
-			cfg.Action.Errorf("Failed to find available port: %v", err)
+			cfg.Action.Errorf("Unable to determine the extraction limits for '%s': %v", label, err)
 			return err
 		}
-		
-		if availablePort != cfg.StartPort {
-			cfg.Action.Debugf("Port %d was occupied, using port %d instead", cfg.StartPort, availablePort)
-		}
-		
-		localPort := fmt.Sprintf(":%d", availablePort)
-		server := &http.Server{Addr: localPort, Handler: r}
-		
-		var completeLocalUrl string
-		if useNetworkIP {
-			completeLocalUrl = fmt.Sprintf("http://%s:%d", networkIP, availablePort)
-		} else {
-			completeLocalUrl = fmt.Sprintf("http://localhost:%d", availablePort)
-		}
-		
-		cfg.Action.Debugf("Using port: %d", availablePort)
-		serverInitMessage := fmt.Sprintf(serverInitMessageTmpl, completeLocalUrl)
 
-		cfg.Action.Noticef(serverInitMessage)
-		if slackNotifier.Enabled() {
-			_, err := slackNotifier.Notify(cfg.Title, fmt.Sprintf(notifierSlackEnterInputMessageTmpl, completeLocalUrl))
+		for _, providedFilePath := range values[label] {
+			filePath := providedFilePath
+			if !filepath.IsAbs(filePath) {
+				filePath = filepath.Join(os.Getenv("GITHUB_WORKSPACE"), filePath)
+			}
+
+			contentType, err := toolbox.DetectFileContentType(filePath)
+			if err != nil {
+				cfg.Action.Errorf("Unable to read the file provided for '%s': %v", label, err)
+				return errors.ErrInvalidAnswersProvided
+			}
+			if !field.Properties.IsAcceptedFileType(filePath, contentType) {
+				cfg.Action.Errorf("The file provided for '%s' (%s) is not one of the accepted file types: %s", label, contentType, strings.Join(field.Properties.AcceptedFileTypes, ", "))
+				return errors.ErrInvalidAnswersProvided
+			}
+
+			scan, err := scanner.ScanProvidedFile(ctx, uploadScanner, filePath)
 			if err != nil {
-				cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
+				cfg.Action.Errorf("The file provided for '%s' didn't pass the upload scan: %v", label, err)
 				return err
 			}
+
+			fileName, err := toolbox.CopyFileToDir(filePath, cacheDir, fileNamesInUse)
+			if err != nil {
+				cfg.Action.Errorf("Unable to copy the file provided for '%s': %v", label, err)
+				return errors.ErrInvalidAnswersProvided
+			}
+
+			manifestEntry, err := fields.NewFileManifestEntry(providedFilePath, filepath.Join(cacheDir, fileName))
+			if err != nil {
+				cfg.Action.Errorf("Unable to checksum the file provided for '%s': %v", label, err)
+				return err
+			}
+			manifestEntry.Scan = scan
+
+			if archiveExtractor.Extracts(fileName) {
+				dirName := toolbox.UniqueFileName(archive.ExtractedDirName(fileName), fileNamesInUse)
+				extraction, err := archiveExtractor.Extract(fileName, filepath.Join(cacheDir, fileName), filepath.Join(cacheDir, dirName))
+				if err != nil {
+					cfg.Action.Errorf("Unable to extract the archive provided for '%s': %v", label, err)
+					return errors.ErrInvalidAnswersProvided
+				}
+				manifestEntry.RecordExtraction(dirName, extraction)
+				cfg.Action.Debugf("Extracted %d file(s) from %s into %s", len(extraction.Files), filePath, dirName)
+			}
+
+			cfg.Action.Debugf("Copied %s into %s", filePath, cacheDir)
+			fileNames = append(fileNames, fileName)
+			manifest.Files = append(manifest.Files, manifestEntry)
 		}
 
-		if discordNotifier.Enabled() {
-			_, err := discordNotifier.Notify(cfg.Title, fmt.Sprintf(notifierDiscordEnterInputMessageTmpl, completeLocalUrl))
+		if len(manifest.Files) > 0 {
+			_, err := manifest.WriteToDir(cacheDir)
 			if err != nil {
-				cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
+				cfg.Action.Errorf("Unable to write the file manifest for '%s': %v", label, err)
 				return err
 			}
 		}
 
-		go func() {
-			// server logic
-			if err := server.ListenAndServe(); err != nil {
-				serverErrorMessage := fmt.Sprintf(universalNotifierFailedToSelfHost, err)
+		values[label] = fileNames
+	}
 
-				cfg.Action.Errorf(serverErrorMessage)
-				if slackNotifier.Enabled() {
-					_, err := slackNotifier.Notify(cfg.Title, serverErrorMessage)
-					if err != nil {
-						cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
-					}
-				}
+	portal.OutputSubmittedValues(&portal.OutputSubmittedValuesRequest{
+		ActionPkg:                        cfg.Action,
+		IsRunningLocal:                   isRunningLocal,
+		InputFieldLabelToCacheDirMapping: inputFieldLabelToCacheDirMapping,
+		Fields:                           cfg.Fields,
+		Values:                           values,
+	})
 
-				if discordNotifier.Enabled() {
-					_, err := discordNotifier.Notify(cfg.Title, serverErrorMessage)
-					if err != nil {
-						cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
-					}
-				}
+	cfg.Action.Infof("Your inputs have successfully been received!")
+
+	return nil
+}
 
-				serverDone <- err
+// notifyMessage sends the message to the enabled notifiers, logging any that fail
+func notifyMessage(cfg *config.Config, slackNotifier, discordNotifier notifier.Notifier, message string) {
+	if slackNotifier.Enabled() {
+		_, err := slackNotifier.Notify(cfg.Title, message)
+		if err != nil {
+			cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
+		}
+	}
+
+	if discordNotifier.Enabled() {
+		_, err := discordNotifier.Notify(cfg.Title, message)
+		if err != nil {
+			cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
+		}
+	}
+}
+
+// notifyLimitTriggered sends the rate limit or lockout that was triggered to the notifiers, except
+// the GitHub notifier on public repositories, where the client's address would be public
+func notifyLimitTriggered(cfg *config.Config, slackNotifier, discordNotifier, githubNotifier notifier.Notifier, message string) {
+	notifyMessage(cfg, slackNotifier, discordNotifier, message)
+
+	if !githubNotifier.Enabled() {
+		return
+	}
+
+	if githubLinkNotifier, ok := githubNotifier.(*notifier.GithubNotifier); ok && githubLinkNotifier.PublicRepository() {
+		return
+	}
+
+	_, err := githubNotifier.Notify(cfg.Title, message)
+	if err != nil {
+		cfg.Action.Errorf("GitHub Notifier Notification Failed: %v", err)
+	}
+}
+
+// notifySubmittedBy lets the notifiers know who submitted the inputs, and adds them to the job's summary
+func notifySubmittedBy(cfg *config.Config, isRunningLocal bool, slackNotifier, discordNotifier notifier.Notifier, submittedBy string) {
+	submittedByMessage := fmt.Sprintf("The inputs have been submitted by %s", submittedBy)
+
+	notifyMessage(cfg, slackNotifier, discordNotifier, submittedByMessage)
+
+	if !isRunningLocal {
+		// Can't use when running locally
+		summaryTitle := cfg.Title
+		if summaryTitle == "" {
+			summaryTitle = "Interactive Inputs"
+		}
+		cfg.Action.AddStepSummary(fmt.Sprintf("### %s\n\n%s.\n", summaryTitle, submittedByMessage))
+	}
+}
+
+// notifyRecipients sends each recipient their own link with the first enabled notifier that can
+// reach them privately, warning about the recipients none of them can reach
+func notifyRecipients(cfg *config.Config, portalEventHandler *portal.Handler, portalUrl, serverInitMessageTmpl string, notifiers ...notifier.Notifier) error {
+	for _, recipient := range cfg.Recipients {
+		cfg.Action.AddMask(portalEventHandler.RecipientSignature(recipient))
+		recipientLink := portalEventHandler.RecipientLink(portalUrl, recipient)
+		cfg.Action.Noticef("%s: "+serverInitMessageTmpl, recipient, recipientLink)
+
+		var notified bool
+		for _, enabledNotifier := range notifiers {
+			recipientNotifier, ok := enabledNotifier.(notifier.RecipientNotifier)
+			if !ok || !enabledNotifier.Enabled() {
+				continue
+			}
+
+			sent, err := recipientNotifier.NotifyRecipient(recipient, cfg.Title, recipientLink)
+			if err != nil {
+				cfg.Action.Errorf("Unable to send %s their link to the portal: %v", recipient, err)
+				return err
+			}
+
+			if sent {
+				notified = true
+				break
 			}
-			serverDone <- server.Shutdown(ctx)
-		}()
+		}
+
+		if notified {
+			continue
+		}
+
+		if cfg.CommentRepliesEnabled {
+			cfg.Action.Warningf("No enabled notifier can send %s their link privately, so they can only answer by replying to the GitHub notifier's comment", recipient)
+		} else {
+			cfg.Action.Warningf("No enabled notifier can send %s their link privately, so they won't be able to answer. The Slack notifier can send it to recipients listed by their Slack account's email", recipient)
+		}
 	}
 
-	select {
-	case err := <-serverDone:
-		return handlePrettierTimeoutErrorMessage(err, cfg.Timeout)
-	case <-ctx.Done():
-		// Timeout occurred
-		ctxCancel() // Ensure all resources are cleaned up
+	return nil
+}
 
-		return handlePrettierTimeoutErrorMessage(ctx.Err(), cfg.Timeout)
+// githubMention returns how the recipient is mentioned on GitHub, where only usernames can be mentioned
+func githubMention(recipient string) string {
+	if strings.Contains(recipient, "@") {
+		return recipient
+	}
+	return "@" + recipient
+}
+
+// pollCommentReplies checks for replies to the GitHub notifier's comment in the background until
+// the context is done, submitting or cancelling the portal with the first valid reply
+func pollCommentReplies(ctx context.Context, cfg *config.Config, githubNotifier notifier.Notifier, portalEventHandler *portal.Handler) {
+	githubCommentNotifier, ok := githubNotifier.(*notifier.GithubNotifier)
+	if !ok {
+		return
+	}
+
+	issueNumber, commentedAt := githubCommentNotifier.CommentThread()
+	if issueNumber == 0 {
+		cfg.Action.Warningf("The portal's link wasn't commented on a pull request or issue, so it can only be answered through its link")
+		return
+	}
+
+	actionContext, err := cfg.Action.Context()
+	if err != nil {
+		cfg.Action.Errorf("Unable to get action context to check for replies: %v", err)
+		return
+	}
+
+	poller := replies.NewPoller(&replies.NewPollerRequest{
+		Client:      newGithubClient(cfg, actionContext),
+		Repository:  actionContext.Repository,
+		IssueNumber: issueNumber,
+		Since:       commentedAt,
+		Prefix:      cfg.CommentReplyPrefix,
+		Interval:    cfg.CommentReplyPollInterval,
+		Fields:      cfg.Fields,
+		Recipients:  cfg.Recipients,
+		ActionPkg:   cfg.Action,
+		OnSubmitted: portalEventHandler.SubmitReply,
+		OnRejected:  portalEventHandler.CancelReply,
+	})
+
+	go poller.Poll(ctx)
+}
+
+// completeNotifier updates the notifications the notifier has sent with how the portal
+// completed, when the notifier is enabled and supports it
+func completeNotifier(cfg *config.Config, n notifier.Notifier, succeeded bool, description string) {
+	completer, ok := n.(notifier.Completer)
+	if !ok || !n.Enabled() {
+		return
 	}
 
+	err := completer.Complete(succeeded, description)
+	if err != nil {
+		cfg.Action.Errorf("Unable to update the notifications with how the portal completed: %v", err)
+	}
 }
 
-// handlePrettierTimeoutErrorMessage is a helper function that prints a nicer error message
-// when the context deadline is exceeded. Otherwise, it returns the original error.
-func handlePrettierTimeoutErrorMessage(err error, timeout int) error {
-	if err == context.DeadlineExceeded {
-		return fmt.Errorf("the interactive inputs portal timed out after %d seconds", timeout)
+// newGithubClient returns a client for the GitHub API, using the run's API URL unless another is provided
+func newGithubClient(cfg *config.Config, actionContext *githubactions.GitHubContext) *githubapi.Client {
+	githubApiUrl := cfg.GithubApiUrl
+	if githubApiUrl == "" {
+		githubApiUrl = actionContext.APIURL
 	}
-	return err
+
+	return githubapi.NewClient(&githubapi.NewClientRequest{
+		BaseUrl: githubApiUrl,
+		Token:   cfg.GithubToken,
+	})
 }
 
-// getNetworkIP detects the network IP address of the runner
-func getNetworkIP() (string, error) {
-	// Try to connect to a remote address to determine the local IP
-	conn, err := net.Dial("udp", "8.8.8.8:80")
+// resolveJobUrl returns the URL of the job the action is running in, falling back to the URL
+// of its run, or an empty string when neither is known
+func resolveJobUrl(ctx context.Context, cfg *config.Config, isRunningLocal bool) string {
+	actionContext, err := cfg.Action.Context()
+	if err != nil {
+		cfg.Action.Debugf("Unable to get action context to link to the job: %v", err)
+		return ""
+	}
+
+	if isRunningLocal {
+		return githubapi.RunUrl(actionContext)
+	}
+
+	lookupCtx, lookupCtxCancel := context.WithTimeout(ctx, jobUrlLookupTimeout)
+	defer lookupCtxCancel()
+
+	jobUrl, err := newGithubClient(cfg, actionContext).ResolveJobUrl(lookupCtx, actionContext, cfg.Action.Getenv("RUNNER_NAME"))
 	if err != nil {
-		return "", fmt.Errorf("failed to get network IP: %v", err)
+		cfg.Action.Debugf("Unable to find the job the action is running in, linking to run %d instead: %v", actionContext.RunID, err)
 	}
-	defer conn.Close()
 
-	localAddr := conn.LocalAddr().(*net.UDPAddr)
-	return localAddr.IP.String(), nil
+	return jobUrl
 }
 
-// isPortAvailable checks if a port is available for use
-func isPortAvailable(port int) bool {
-	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+// shutdownPortal shuts the portal down, giving in-flight requests a moment to finish
+func shutdownPortal(cfg *config.Config, server *http.Server) {
+	shutdownCtx, shutdownCtxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCtxCancel()
+
+	err := server.Shutdown(shutdownCtx)
 	if err != nil {
-		return false
+		cfg.Action.Debugf("Unable to gracefully shut down the portal: %v", err)
+	}
+}
+
+// sleepContext waits for the duration to pass, returning early when the context is done
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
 	}
-	ln.Close()
-	return true
 }
 
-// findAvailablePort finds an available port starting from the given port
-func findAvailablePort(startPort int) (int, error) {
-	maxAttempts := 100
-	for i := 0; i < maxAttempts; i++ {
-		port := startPort + i
-		if port > 65535 {
-			break
+// HandleCancelledPortal carries out the cancel behaviour once the portal has been cancelled,
+// returning an error when the step should fail. The sleep waits for the run to be cancelled
+func HandleCancelledPortal(ctx context.Context, cfg *config.Config, isRunningLocal bool, sleep func(ctx context.Context, d time.Duration)) error {
+	actionContext, err := cfg.Action.Context()
+	if err != nil {
+		cfg.Action.Errorf("Unable to get action context: %v", err)
+		return err
+	}
+
+	runCancelledErr := fmt.Errorf("job within run %d cancelled", actionContext.RunID)
+
+	switch cfg.CancelBehaviour {
+	case config.CancelBehaviourSucceedWithFlag:
+		cfg.Action.Infof("Job within run %d cancelled, the step will succeed with the '%s' output set", actionContext.RunID, portal.CancelledOutput)
+		if !isRunningLocal {
+			cfg.Action.SetOutput(portal.CancelledOutput, "true")
 		}
-		if isPortAvailable(port) {
-			return port, nil
+
+		return nil
+	case config.CancelBehaviourCancelRun:
+		if isRunningLocal {
+			cfg.Action.Warningf("Running locally, run %d can't be cancelled so the step will fail instead", actionContext.RunID)
+			return runCancelledErr
 		}
+
+		cfg.Action.Infof("Cancelling run %d", actionContext.RunID)
+
+		cancelCtx, cancelCtxCancel := context.WithTimeout(ctx, cancelRunTimeout)
+		defer cancelCtxCancel()
+
+		err = newGithubClient(cfg, actionContext).CancelWorkflowRun(cancelCtx, actionContext.Repository, actionContext.RunID)
+		if err != nil {
+			cfg.Action.Errorf("Unable to cancel run %d, the step will fail instead: %v", actionContext.RunID, err)
+			return runCancelledErr
+		}
+
+		// the run is cancelled asynchronously, so wait for the runner to stop the job
+		// and fail the step if that doesn't happen in time
+		sleep(ctx, cancelRunGracePeriod)
+
+		if ctx.Err() != nil {
+			cfg.Action.Warningf("Run %d wasn't cancelled before the portal timed out, the step will fail instead", actionContext.RunID)
+			return runCancelledErr
+		}
+
+		cfg.Action.Warningf("Run %d wasn't cancelled within %s, the step will fail instead", actionContext.RunID, cancelRunGracePeriod)
+		return runCancelledErr
+	default:
+		return runCancelledErr
+	}
+}
+
+// handlePrettierTimeoutErrorMessage is a helper function that prints a nicer error message
+// when the context deadline is exceeded. Otherwise, it returns the original error.
+func handlePrettierTimeoutErrorMessage(err error, timeout int) error {
+	if err == context.DeadlineExceeded {
+		return fmt.Errorf("the interactive inputs portal timed out after %d seconds", timeout)
 	}
-	return 0, fmt.Errorf("no available port found starting from %d (checked ports %d-%d)", startPort, startPort, startPort+maxAttempts-1)
+	return err
 }
@@ -0,0 +1,140 @@
+package runner_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/boasihq/interactive-inputs/internal/config"
+	"github.com/boasihq/interactive-inputs/internal/runner"
+)
+
+func TestHandleCancelledPortal(t *testing.T) {
+	tests := []struct {
+		name             string
+		cancelBehaviour  string
+		isRunningLocal   bool
+		cancelStatus     int
+		timesOutInSleep  bool
+		expectedError    bool
+		expectedOutput   bool
+		expectedCancels  int32
+		expectedSleeping time.Duration
+	}{
+		{
+			name:            "fail step",
+			cancelBehaviour: config.CancelBehaviourFailStep,
+			expectedError:   true,
+		},
+		{
+			name:            "succeed with flag sets the cancelled output",
+			cancelBehaviour: config.CancelBehaviourSucceedWithFlag,
+			expectedOutput:  true,
+		},
+		{
+			name:            "succeed with flag running locally",
+			cancelBehaviour: config.CancelBehaviourSucceedWithFlag,
+			isRunningLocal:  true,
+		},
+		{
+			name:            "cancel run running locally fails the step instead",
+			cancelBehaviour: config.CancelBehaviourCancelRun,
+			isRunningLocal:  true,
+			expectedError:   true,
+		},
+		{
+			name:            "cancel run fails the step when the run can't be cancelled",
+			cancelBehaviour: config.CancelBehaviourCancelRun,
+			cancelStatus:    http.StatusConflict,
+			expectedError:   true,
+			expectedCancels: 1,
+		},
+		{
+			name:             "cancel run fails the step when the run isn't cancelled within the grace period",
+			cancelBehaviour:  config.CancelBehaviourCancelRun,
+			cancelStatus:     http.StatusAccepted,
+			expectedError:    true,
+			expectedCancels:  1,
+			expectedSleeping: time.Minute,
+		},
+		{
+			name:             "cancel run fails the step when the portal times out during the grace period",
+			cancelBehaviour:  config.CancelBehaviourCancelRun,
+			cancelStatus:     http.StatusAccepted,
+			timesOutInSleep:  true,
+			expectedError:    true,
+			expectedCancels:  1,
+			expectedSleeping: time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cancels atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/repos/owner/repo/actions/runs/42/cancel" {
+					http.NotFound(w, r)
+					return
+				}
+				cancels.Add(1)
+				w.WriteHeader(tt.cancelStatus)
+			}))
+			defer server.Close()
+
+			outputPath := filepath.Join(t.TempDir(), "output")
+			require.NoError(t, os.WriteFile(outputPath, nil, 0600))
+
+			env := map[string]string{
+				"GITHUB_REPOSITORY": "owner/repo",
+				"GITHUB_RUN_ID":     "42",
+				"GITHUB_API_URL":    server.URL,
+				"GITHUB_OUTPUT":     outputPath,
+			}
+
+			cfg := &config.Config{
+				CancelBehaviour: tt.cancelBehaviour,
+				GithubToken:     "token",
+				Action: githubactions.New(
+					githubactions.WithWriter(io.Discard),
+					githubactions.WithGetenv(func(key string) string { return env[key] }),
+				),
+			}
+
+			ctx, ctxCancel := context.WithCancel(context.Background())
+			defer ctxCancel()
+
+			var slept time.Duration
+			sleep := func(ctx context.Context, d time.Duration) {
+				slept = d
+				if tt.timesOutInSleep {
+					ctxCancel()
+				}
+			}
+
+			err := runner.HandleCancelledPortal(ctx, cfg, tt.isRunningLocal, sleep)
+
+			assert.Equal(t, tt.expectedError, err != nil)
+			assert.Equal(t, tt.expectedCancels, cancels.Load())
+			assert.Equal(t, tt.expectedSleeping, slept)
+
+			output, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			if tt.expectedOutput {
+				assert.Contains(t, string(output), "cancelled")
+				assert.Contains(t, string(output), "true")
+			} else {
+				assert.Empty(t, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+// commandFlaggedExitCode is the exit code scanners use when they flag a file, i.e. clamscan
+// exits with 1 when a virus is found and 2 when an error occurs
+const commandFlaggedExitCode = 1
+
+// NewCommandScannerRequest is the request object for creating a new
+// instance of a Command Scanner.
+type NewCommandScannerRequest struct {
+
+	// Command is the command run for each file (i.e. "clamscan --no-summary"), the path of
+	// the file is appended as its last argument. It isn't run through a shell.
+	Command string
+
+	// Timeout is how long a file can take to be scanned (optional)
+	Timeout time.Duration
+}
+
+// NewCommandScanner returns a new instance of a Command Scanner
+func NewCommandScanner(r *NewCommandScannerRequest) Scanner {
+
+	commandParts := strings.Fields(r.Command)
+	if len(commandParts) == 0 {
+		commandParts = []string{""}
+	}
+
+	return &CommandScanner{
+		name:    commandParts[0],
+		args:    commandParts[1:],
+		timeout: getTimeout(r.Timeout),
+	}
+}
+
+// CommandScanner is a struct that implements the Scanner interface by running
+// a local command, which exits with 0 when the file is clean and 1 when it is flagged
+type CommandScanner struct {
+
+	// name is the name (or path) of the command
+	name string
+
+	// args the arguments passed to the command before the path of the file
+	args []string
+
+	// timeout is how long a file can take to be scanned
+	timeout time.Duration
+}
+
+func (s *CommandScanner) Scan(ctx context.Context, fileName, filePath string) (*Result, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, s.name, append(s.args, filePath)...).CombinedOutput()
+
+	// the scanners' output refers to the file by its path on the runner
+	detail := summariseDetail(strings.ReplaceAll(string(output), filePath, fileName))
+
+	if err == nil {
+		return &Result{Flagged: false, Detail: detail}, nil
+	}
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: %s timed out after %s", errors.ErrUploadScanFailed, s.Name(), s.timeout)
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == commandFlaggedExitCode {
+		return &Result{Flagged: true, Detail: detail}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s: %v %s", errors.ErrUploadScanFailed, s.Name(), err, detail)
+}
+
+func (s *CommandScanner) Verify() error {
+
+	_, err := exec.LookPath(s.name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidUploadScannerProvided, err)
+	}
+
+	return nil
+}
+
+func (s *CommandScanner) Name() string {
+	return filepath.Base(s.name)
+}
@@ -0,0 +1,137 @@
+package scanner_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubScannerScript behaves like clamscan, exiting with 0 for clean files, 1 for flagged
+// files and 2 when it errors, based on the content of the scanned file
+const stubScannerScript = `#!/bin/sh
+[ "$1" = "--no-summary" ] || exit 3
+case "$(cat "$2")" in
+*EICAR*) echo "$2: Eicar-Signature FOUND"; exit 1 ;;
+*unreadable*) echo "$2: Can't read file ERROR" >&2; exit 2 ;;
+*slow*) exec sleep 5 ;;
+esac
+echo "$2: OK"
+`
+
+// writeStubScanner writes the stub scanner script to the directory, returning its path
+func writeStubScanner(t *testing.T, dir string) string {
+	scriptPath := filepath.Join(dir, "stubscan")
+	assert.NoError(t, os.WriteFile(scriptPath, []byte(stubScannerScript), 0755))
+	return scriptPath
+}
+
+func TestCommandScanner_Scan(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		content        string
+		timeout        time.Duration
+		expectedResult *scanner.Result
+		expectedError  error
+	}{
+		{
+			name:           "success - clean file",
+			content:        "hello",
+			expectedResult: &scanner.Result{Flagged: false, Detail: "report.txt: OK"},
+		},
+		{
+			name:           "success - infected file is flagged",
+			content:        "X5O!P%@AP EICAR",
+			expectedResult: &scanner.Result{Flagged: true, Detail: "report.txt: Eicar-Signature FOUND"},
+		},
+		{
+			name:          "failed - scanner errors",
+			content:       "unreadable",
+			expectedError: errors.ErrUploadScanFailed,
+		},
+		{
+			name:          "failed - scanner times out",
+			content:       "slow",
+			timeout:       100 * time.Millisecond,
+			expectedError: errors.ErrUploadScanFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			tempDir := t.TempDir()
+			filePath := filepath.Join(tempDir, "upload-1234")
+			assert.NoError(t, os.WriteFile(filePath, []byte(tt.content), 0644))
+
+			commandScanner := scanner.NewCommandScanner(&scanner.NewCommandScannerRequest{
+				Command: writeStubScanner(t, tempDir) + " --no-summary",
+				Timeout: tt.timeout,
+			})
+
+			result, err := commandScanner.Scan(context.Background(), "report.txt", filePath)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestCommandScanner_Verify(t *testing.T) {
+
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name          string
+		command       string
+		expectedName  string
+		expectedError error
+	}{
+		{
+			name:         "success - command exists",
+			command:      writeStubScanner(t, tempDir) + " --no-summary",
+			expectedName: "stubscan",
+		},
+		{
+			name:          "failed - command doesn't exist",
+			command:       filepath.Join(tempDir, "missing") + " --no-summary",
+			expectedName:  "missing",
+			expectedError: errors.ErrInvalidUploadScannerProvided,
+		},
+		{
+			name:          "failed - no command",
+			command:       "  ",
+			expectedName:  ".",
+			expectedError: errors.ErrInvalidUploadScannerProvided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			commandScanner := scanner.NewCommandScanner(&scanner.NewCommandScannerRequest{Command: tt.command})
+
+			err := commandScanner.Verify()
+
+			assert.Equal(t, tt.expectedName, commandScanner.Name())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package scanner
+
+// HttpScanResponse is the response expected from the HTTP endpoint of an HTTP Scanner
+type HttpScanResponse struct {
+
+	// Flagged is true when the endpoint flagged the file
+	Flagged bool `json:"flagged"`
+
+	// Detail is the endpoint's explanation of the outcome (optional)
+	Detail string `json:"detail"`
+}
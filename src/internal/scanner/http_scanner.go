@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+const (
+	// HttpScannerFileNameHeader holds the header the name of the scanned file is sent with
+	HttpScannerFileNameHeader = "X-File-Name"
+
+	// maxHttpScanResponseSize is the largest response read from the HTTP endpoint
+	maxHttpScanResponseSize = 64 << 10
+)
+
+// NewHttpScannerRequest is the request object for creating a new
+// instance of a HTTP Scanner.
+type NewHttpScannerRequest struct {
+
+	// Url is the URL of the endpoint each file is POSTed to
+	Url string
+
+	// Timeout is how long a file can take to be scanned (optional)
+	Timeout time.Duration
+}
+
+// NewHttpScanner returns a new instance of a HTTP Scanner
+func NewHttpScanner(r *NewHttpScannerRequest) Scanner {
+	return &HttpScanner{
+		url:     r.Url,
+		timeout: getTimeout(r.Timeout),
+		client:  &http.Client{},
+	}
+}
+
+// HttpScanner is a struct that implements the Scanner interface by POSTing the content
+// of each file to an HTTP endpoint, which responds with a HttpScanResponse
+type HttpScanner struct {
+
+	// url is the URL of the endpoint each file is POSTed to
+	url string
+
+	// timeout is how long a file can take to be scanned
+	timeout time.Duration
+
+	// client is the client used to make requests to the endpoint
+	client *http.Client
+}
+
+func (s *HttpScanner) Scan(ctx context.Context, fileName, filePath string) (*Result, error) {
+	var scanResponse HttpScanResponse
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrUploadScanFailed, err)
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set(HttpScannerFileNameHeader, fileName)
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrUploadScanFailed, err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, maxHttpScanResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrUploadScanFailed, err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: %s responded with %d %s", errors.ErrUploadScanFailed, s.Name(), response.StatusCode, summariseDetail(string(responseBody)))
+	}
+
+	err = json.Unmarshal(responseBody, &scanResponse)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed response from %s: %v", errors.ErrUploadScanFailed, s.Name(), err)
+	}
+
+	return &Result{Flagged: scanResponse.Flagged, Detail: summariseDetail(scanResponse.Detail)}, nil
+}
+
+func (s *HttpScanner) Verify() error {
+
+	scannerUrl, err := url.Parse(s.url)
+	if err != nil || (scannerUrl.Scheme != "http" && scannerUrl.Scheme != "https") || scannerUrl.Host == "" {
+		return fmt.Errorf("%w: %s is not a valid http(s) URL", errors.ErrInvalidUploadScannerProvided, s.url)
+	}
+
+	return nil
+}
+
+func (s *HttpScanner) Name() string {
+
+	// only the host is used, as the URL may hold credentials
+	scannerUrl, err := url.Parse(s.url)
+	if err != nil || scannerUrl.Host == "" {
+		return "http scanner"
+	}
+	return scannerUrl.Host
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+)
+
+const (
+	// DefaultTimeout is how long a file can take to be scanned before the scan is treated as failed
+	DefaultTimeout = 2 * time.Minute
+
+	// maxDetailLength is the longest detail kept from a scanner's output
+	maxDetailLength = 1024
+)
+
+// Scanner scans uploaded files before they are made available to the workflow,
+// i.e. for malware
+type Scanner interface {
+
+	// Scan scans the file, with the given name and stored at the given path, returning
+	// an error when the file couldn't be scanned
+	Scan(ctx context.Context, fileName, filePath string) (*Result, error)
+
+	// Verify verifies the scanner can be used
+	Verify() error
+
+	// Name returns the name of the scanner, which is recorded with its results
+	Name() string
+}
+
+// Result holds the outcome of scanning a file
+type Result struct {
+
+	// Flagged is true when the scanner flagged the file
+	Flagged bool
+
+	// Detail is the scanner's explanation of the outcome, i.e. the name of the detected malware
+	Detail string
+}
+
+// ScanProvidedFile scans a file provided from the runner in prompt or answers mode, returning the
+// outcome to record in its manifest entry, or nil when no scanner is configured
+func ScanProvidedFile(ctx context.Context, scanner Scanner, filePath string) (*fields.FileScanResult, error) {
+	if scanner == nil {
+		return nil, nil
+	}
+
+	fileName := filepath.Base(filePath)
+
+	scanResult, err := scanner.Scan(ctx, fileName, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if scanResult.Flagged {
+		return nil, fmt.Errorf("%w: %s was flagged by the %s scan: %s", errors.ErrFileFlaggedByScanner, fileName, scanner.Name(), scanResult.Detail)
+	}
+
+	return &fields.FileScanResult{
+		Scanner: scanner.Name(),
+		Flagged: scanResult.Flagged,
+		Detail:  scanResult.Detail,
+	}, nil
+}
+
+// getTimeout returns the timeout, falling back to the default timeout when it isn't provided
+func getTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return DefaultTimeout
+	}
+	return timeout
+}
+
+// summariseDetail trims the scanner's explanation and caps its length
+func summariseDetail(detail string) string {
+	detail = strings.TrimSpace(detail)
+	if len(detail) > maxDetailLength {
+		return detail[:maxDetailLength] + "…"
+	}
+	return detail
+}
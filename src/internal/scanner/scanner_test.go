@@ -0,0 +1,70 @@
+package scanner_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanProvidedFile(t *testing.T) {
+
+	tempDir := t.TempDir()
+	stubScanner := scanner.NewCommandScanner(&scanner.NewCommandScannerRequest{
+		Command: writeStubScanner(t, tempDir) + " --no-summary",
+	})
+
+	tests := []struct {
+		name           string
+		scanner        scanner.Scanner
+		content        string
+		expectedResult *fields.FileScanResult
+		expectedError  error
+	}{
+		{
+			name:    "success - no scanner configured",
+			content: "X5O!P%@AP EICAR",
+		},
+		{
+			name:           "success - clean file",
+			scanner:        stubScanner,
+			content:        "hello",
+			expectedResult: &fields.FileScanResult{Scanner: "stubscan", Detail: "report.txt: OK"},
+		},
+		{
+			name:          "failed - flagged file is rejected",
+			scanner:       stubScanner,
+			content:       "X5O!P%@AP EICAR",
+			expectedError: errors.ErrFileFlaggedByScanner,
+		},
+		{
+			name:          "failed - file couldn't be scanned",
+			scanner:       stubScanner,
+			content:       "unreadable",
+			expectedError: errors.ErrUploadScanFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			filePath := filepath.Join(t.TempDir(), "report.txt")
+			assert.NoError(t, os.WriteFile(filePath, []byte(tt.content), 0644))
+
+			result, err := scanner.ScanProvidedFile(context.Background(), tt.scanner, filePath)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
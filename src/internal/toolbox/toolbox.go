@@ -2,8 +2,14 @@ package toolbox
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // StringConvertToKebabCase returns a string in kebab case format
@@ -95,3 +101,165 @@ func SecondsToMinutes(inSeconds int) string {
 	str := fmt.Sprintf("%d:%d", minutes, seconds)
 	return str
 }
+
+// CopyFileToDir copies the file at the given path into the destination directory under its
+// sanitised base name, numbered if the name is already in use. It returns the name of the copied file.
+func CopyFileToDir(filePath, destinationDir string, fileNamesInUse map[string]bool) (string, error) {
+
+	sourceFile, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer sourceFile.Close()
+
+	sourceFileInfo, err := sourceFile.Stat()
+	if err != nil {
+		return "", err
+	}
+	if sourceFileInfo.IsDir() {
+		return "", fmt.Errorf("%s is a directory", filePath)
+	}
+
+	fileName := UniqueFileName(SanitiseFileName(filepath.Base(filePath)), fileNamesInUse)
+	destinationFile, err := os.Create(filepath.Join(destinationDir, fileName))
+	if err != nil {
+		return "", err
+	}
+	defer destinationFile.Close()
+
+	_, err = io.Copy(destinationFile, sourceFile)
+	if err != nil {
+		return "", err
+	}
+
+	return fileName, destinationFile.Close()
+}
+
+// byteSizeRegex matches a size such as 512, 512B, 10KB, 1.5 GB or 100MiB
+var byteSizeRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(B|[KMGT]I?B)?$`)
+
+// ParseByteSize converts a human-friendly size such as "100MB" into bytes, where 1KB is 1024 bytes
+func ParseByteSize(size string) (int64, error) {
+
+	size = strings.TrimSpace(size)
+	if size == "" {
+		return 0, nil
+	}
+
+	match := byteSizeRegex.FindStringSubmatch(strings.ToUpper(size))
+	if match == nil {
+		return 0, fmt.Errorf("invalid size '%s', expected a number optionally followed by B, KB, MB, GB or TB", size)
+	}
+
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	var multiplier float64 = 1
+	switch strings.TrimSuffix(strings.TrimSuffix(match[2], "B"), "I") {
+	case "K":
+		multiplier = 1 << 10
+	case "M":
+		multiplier = 1 << 20
+	case "G":
+		multiplier = 1 << 30
+	case "T":
+		multiplier = 1 << 40
+	}
+
+	return int64(number * multiplier), nil
+}
+
+// BytesToHumanReadable converts the given number of bytes into a human-friendly size, i.e. 1.5MB
+func BytesToHumanReadable(bytes int64) string {
+	const unit = 1024
+
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+
+	divisor, exponent := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		divisor *= unit
+		exponent++
+	}
+
+	humanReadable := strconv.FormatFloat(float64(bytes)/float64(divisor), 'f', 1, 64)
+	return fmt.Sprintf("%s%cB", strings.TrimSuffix(humanReadable, ".0"), "KMGTPE"[exponent])
+}
+
+// DetectFileContentType returns the content type of the file at the given path, sniffed
+// from the start of its content with http.DetectContentType
+func DetectFileContentType(filePath string) (string, error) {
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	sniffedContent := make([]byte, 512)
+	n, err := io.ReadFull(file, sniffedContent)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	return http.DetectContentType(sniffedContent[:n]), nil
+}
+
+// maxFileNameLength is the longest file name (in bytes) supported by most file systems
+const maxFileNameLength = 255
+
+// reservedFileNameCharactersRegex matches characters that aren't allowed in file names on common file systems
+var reservedFileNameCharactersRegex = regexp.MustCompile(`[<>:"/\\|?*]`)
+
+// SanitiseFileName returns a version of the client-supplied file name that is safe to use
+// as the name of a file within a directory
+func SanitiseFileName(fileName string) string {
+
+	// strip both unix and windows directories
+	fileName = fileName[strings.LastIndexAny(fileName, `/\`)+1:]
+
+	fileName = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, fileName)
+
+	fileName = reservedFileNameCharactersRegex.ReplaceAllString(fileName, "_")
+	fileName = strings.TrimLeft(fileName, ". ")
+	fileName = strings.TrimRight(fileName, " ")
+
+	if fileName == "" {
+		return "file"
+	}
+
+	if len(fileName) > maxFileNameLength {
+		extension := filepath.Ext(fileName)
+		if len(extension) > maxFileNameLength/2 {
+			extension = ""
+		}
+		fileName = strings.ToValidUTF8(fileName[:maxFileNameLength-len(extension)], "") + extension
+	}
+
+	return fileName
+}
+
+// UniqueFileName returns the given file name, or a numbered version of it (i.e. "report (1).pdf")
+// when it is already in use. The returned name is marked as in use.
+func UniqueFileName(fileName string, fileNamesInUse map[string]bool) string {
+
+	uniqueFileName := fileName
+	extension := filepath.Ext(fileName)
+	baseName := strings.TrimSuffix(fileName, extension)
+
+	for i := 1; fileNamesInUse[strings.ToLower(uniqueFileName)]; i++ {
+		uniqueFileName = fmt.Sprintf("%s (%d)%s", baseName, i, extension)
+	}
+
+	fileNamesInUse[strings.ToLower(uniqueFileName)] = true
+
+	return uniqueFileName
+}
@@ -0,0 +1,94 @@
+package toolbox_test
+
+import (
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		size          string
+		expectedBytes int64
+		expectedError bool
+	}{
+		{
+			name:          "success - empty size",
+			size:          "  ",
+			expectedBytes: 0,
+		},
+		{
+			name:          "success - bytes without a unit",
+			size:          "512",
+			expectedBytes: 512,
+		},
+		{
+			name:          "success - bytes",
+			size:          "512B",
+			expectedBytes: 512,
+		},
+		{
+			name:          "success - kilobytes lowercase",
+			size:          "10kb",
+			expectedBytes: 10 << 10,
+		},
+		{
+			name:          "success - fractional gigabytes with a space",
+			size:          "1.5 GB",
+			expectedBytes: 3 << 29,
+		},
+		{
+			name:          "success - terabytes",
+			size:          "2TB",
+			expectedBytes: 2 << 40,
+		},
+		{
+			name:          "failed - unit without B",
+			size:          "5M",
+			expectedError: true,
+		},
+		{
+			name:          "failed - malformed unit",
+			size:          "5I",
+			expectedError: true,
+		},
+		{
+			name:          "success - binary unit",
+			size:          "100MiB",
+			expectedBytes: 100 << 20,
+		},
+		{
+			name:          "failed - binary unit without B",
+			size:          "5KI",
+			expectedError: true,
+		},
+		{
+			name:          "failed - unknown unit",
+			size:          "1PB",
+			expectedError: true,
+		},
+		{
+			name:          "failed - negative size",
+			size:          "-1MB",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			bytes, err := toolbox.ParseByteSize(tt.size)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedBytes, bytes)
+		})
+	}
+}
@@ -1,13 +1,20 @@
 package webui
 
 import (
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 
+	"github.com/boasihq/interactive-inputs/internal/audit"
 	"github.com/boasihq/interactive-inputs/internal/config"
 	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/metrics"
+	"github.com/boasihq/interactive-inputs/internal/portal"
 	"github.com/boasihq/interactive-inputs/internal/toolbox"
 	githubactions "github.com/sethvargo/go-githubactions"
 	"go.uber.org/zap"
@@ -20,6 +27,10 @@ type NewWebAppHandlerRequest struct {
 	EmbeddedContentFilePathPrefix string
 	// Config is the configuration of the action
 	Config *config.Config
+	// AuditLog is where the portal's activity is recorded (optional)
+	AuditLog *audit.Log
+	// Metrics collects the portal's metrics (optional)
+	Metrics *metrics.Collector
 }
 
 // NewWebAppHandler creates a new instance of an ui handler
@@ -29,6 +40,8 @@ func NewWebAppHandler(r *NewWebAppHandlerRequest) *Handler {
 		embeddedContentFilePathPrefix: r.EmbeddedContentFilePathPrefix,
 		action:                        r.Config.Action,
 		config:                        r.Config,
+		auditLog:                      r.AuditLog,
+		metrics:                       r.Metrics,
 	}
 }
 
@@ -38,6 +51,8 @@ type Handler struct {
 	embeddedContentFilePathPrefix string
 	action                        *githubactions.Action
 	config                        *config.Config
+	auditLog                      *audit.Log
+	metrics                       *metrics.Collector
 }
 
 func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
@@ -59,23 +74,32 @@ func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
 
 	// shape the request
 	repoOwner, _ := actionContext.Repo()
-	
+
 	// Pre-process fields to load choices from files if needed
 	processedFields := h.preprocessFields(h.config.Fields)
-	
+
+	// Prefill fields with the answers of a previous run and the query string
+	h.prefillFields(processedFields, r.URL.Query())
+
 	response = &CreateInteractiveInputsPortalRequest{
 		RepoOwner: repoOwner,
 		Title:     h.config.Title,
 		Fields:    processedFields,
 		Timeout:   toolbox.SecondsToMinutes(h.config.Timeout),
+		CspNonce:  portal.CspNonce(r),
 	}
 
+	h.auditLog.Record(portal.NewAuditEvent(r.Context(), audit.EventPortalOpened, nil))
+	h.metrics.ObservePageView()
+
 	// list of template files to parse, must be in order of inheritence
 	templateFilesToParse := []string{
 		fmt.Sprintf("%sweb/ui/html/index.tmpl.html", h.embeddedContentFilePathPrefix),
 		fmt.Sprintf("%sweb/ui/html/partials/shared/head-meta.tmpl.html", h.embeddedContentFilePathPrefix),
 		fmt.Sprintf("%sweb/ui/html/pages/@landing.tmpl.html", h.embeddedContentFilePathPrefix),
 		fmt.Sprintf("%sweb/ui/html/partials/shared/tailwind-dash-script.tmpl.html", h.embeddedContentFilePathPrefix),
+		fmt.Sprintf("%sweb/ui/html/partials/fields/group.tmpl.html", h.embeddedContentFilePathPrefix),
+		fmt.Sprintf("%sweb/ui/html/partials/fields/structured.tmpl.html", h.embeddedContentFilePathPrefix),
 	}
 
 	// Parse template
@@ -108,12 +132,12 @@ func (h *Handler) preprocessFields(fieldsData *fields.Fields) *fields.Fields {
 
 	for i, field := range fieldsData.Fields {
 		processedFields.Fields[i] = field
-		
+
 		// If choicesFilePath is provided, load choices from file
 		if field.Properties.ChoicesFilePath != "" {
 			choices, err := field.Properties.GetChoices()
 			if err != nil {
-				h.action.Warningf("Failed to load choices from file '%s' for field '%s': %v", 
+				h.action.Warningf("Failed to load choices from file '%s' for field '%s': %v",
 					field.Properties.ChoicesFilePath, field.Label, err)
 				// Keep the original choices if file loading fails
 			} else {
@@ -121,7 +145,66 @@ func (h *Handler) preprocessFields(fieldsData *fields.Fields) *fields.Fields {
 				processedFields.Fields[i].Properties.Choices = choices
 			}
 		}
+
+		// Group fields hold their own sub-fields, which may also load choices from files
+		if field.IsGroup() {
+			processedFields.Fields[i].Properties.SubFields = h.preprocessFields(&fields.Fields{
+				Fields: field.Properties.SubFields,
+			}).Fields
+		}
 	}
 
 	return processedFields
 }
+
+// prefillFields merges the query string values, then the saved answers, over the default
+// values of the fields that aren't read-only or file fields
+func (h *Handler) prefillFields(fieldsData *fields.Fields, query url.Values) {
+	var savedAnswers map[string]json.RawMessage
+	var err error
+
+	if fieldsData == nil {
+		return
+	}
+
+	if h.config.PrefillAnswersFile != "" {
+		savedAnswers, err = fields.LoadAnswersFromFile(h.config.PrefillAnswersFile)
+		if err != nil && os.IsNotExist(err) {
+			h.action.Debugf("No saved answers found at '%s', skipping prefill from previous run", h.config.PrefillAnswersFile)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			h.action.Warningf("Failed to load saved answers from '%s': %v", h.config.PrefillAnswersFile, err)
+		}
+	}
+
+	for i := range fieldsData.Fields {
+		field := &fieldsData.Fields[i]
+
+		if field.Properties.ReadOnly || field.IsFile() {
+			continue
+		}
+
+		if savedAnswer, ok := savedAnswers[field.Label]; ok {
+			values, err := field.DecodeAnswer(savedAnswer)
+			if err != nil {
+				h.action.Warningf("Failed to prefill field '%s' from saved answers: %v", field.Label, err)
+			} else {
+				prefillField(field, values)
+			}
+		}
+
+		if values, ok := query[field.Label]; ok {
+			prefillField(field, values)
+		}
+	}
+}
+
+// prefillField sets the value(s) the field is prefilled with
+func prefillField(field *fields.Field, values []string) {
+	if field.Properties.Type == "multiselect" {
+		field.Properties.PrefilledChoices = append([]string{}, values...)
+		return
+	}
+
+	field.Properties.DefaultValue = strings.Join(values, ",")
+}
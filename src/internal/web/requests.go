@@ -17,4 +17,8 @@ type CreateInteractiveInputsPortalRequest struct {
 	// Timeout is how long the portal will be available for users to use before it is
 	// automatically deactivated
 	Timeout string
+
+	// CspNonce is the nonce the page's inline scripts must carry to satisfy the portal's
+	// Content-Security-Policy
+	CspNonce string
 }
@@ -3,3 +3,14 @@ window.getCookie = function (name) {
   var match = document.cookie.match(new RegExp("(^| )" + name + "=([^;]+)"));
   if (match) return match[2];
 };
+
+// csrfHeaders returns the given headers along with the portal's CSRF token, which
+// must be sent with every request that changes the portal's state
+window.csrfHeaders = function (headers) {
+  return Object.assign({ "X-CSRF-Token": window.getCookie("iaip_csrf") || "" }, headers);
+};
+
+// send the portal's CSRF token with every htmx request
+document.addEventListener("htmx:configRequest", function (evt) {
+  Object.assign(evt.detail.headers, window.csrfHeaders());
+});
@@ -4,6 +4,7 @@ import (
 	"context"
 	"embed"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/boasihq/interactive-inputs/internal/config"
@@ -47,6 +48,11 @@ func run() error {
 			Action:  action,
 			Timeout: config.DefaultTimeout,
 			Fields:  fields,
+			Mode:    strings.ToLower(strings.TrimSpace(action.GetInput("mode"))),
+			Answers: os.Getenv("IAIP_ANSWERS"),
+		}
+		if cfg.Answers != "" {
+			cfg.Mode = config.ModeAnswers
 		}
 	}
 